@@ -0,0 +1,98 @@
+// Command querybench gates benchmark results in CI. It reads the output of
+// go test -bench run with -count, takes the median time per operation of
+// each benchmark over its runs, and fails when one benchmark is slower than
+// another by more than a threshold, so single noisy runs do not decide:
+//
+//	go test -run '^$' -bench ParamLookup -count 10 ./validator |
+//		querybench -faster ParamLookupPerfectHash -than ParamLookupMap
+//
+// The exit status is 1 when the gate fails and 2 on bad input, including
+// fewer runs of either benchmark than -min-runs.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	faster := flag.String("faster", "", "benchmark that must not be slower")
+	than := flag.String("than", "", "benchmark it is compared with")
+	threshold := flag.Float64("threshold", 0.05, "slowdown of the medians tolerated, as a fraction")
+	minRuns := flag.Int("min-runs", 5, "runs of each benchmark required")
+	flag.Parse()
+
+	if *faster == "" || *than == "" {
+		fmt.Fprintln(os.Stderr, "usage: go test -bench . -count N | querybench -faster A -than B [-threshold 0.05] [-min-runs 5]")
+		os.Exit(2)
+	}
+	samples, err := parseBenchmarks(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "querybench: %v\n", err)
+		os.Exit(2)
+	}
+	fast, slow, err := medians(samples, *faster, *than, *minRuns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "querybench: %v\n", err)
+		os.Exit(2)
+	}
+	fmt.Printf("%s\t%.1f ns/op\n%s\t%.1f ns/op\n", *faster, fast, *than, slow)
+	if fast > slow*(1+*threshold) {
+		fmt.Fprintf(os.Stderr, "querybench: %s is %.1f%% slower than %s\n", *faster, (fast/slow-1)*100, *than)
+		os.Exit(1)
+	}
+}
+
+// parseBenchmarks collects the ns/op of each run in go test -bench output,
+// by benchmark name without its Benchmark prefix and GOMAXPROCS suffix.
+func parseBenchmarks(r io.Reader) (map[string][]float64, error) {
+	samples := make(map[string][]float64)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || !strings.HasPrefix(fields[0], "Benchmark") {
+			continue
+		}
+		name := strings.TrimPrefix(fields[0], "Benchmark")
+		if i := strings.LastIndexByte(name, '-'); i > 0 {
+			if _, err := strconv.Atoi(name[i+1:]); err == nil {
+				name = name[:i]
+			}
+		}
+		for i := 2; i+1 < len(fields); i += 2 {
+			if fields[i+1] != "ns/op" {
+				continue
+			}
+			ns, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("benchmark %s: invalid ns/op %q", name, fields[i])
+			}
+			samples[name] = append(samples[name], ns)
+		}
+	}
+	return samples, scanner.Err()
+}
+
+// medians returns the median ns/op of benchmarks a and b, each of which must
+// have run at least minRuns times.
+func medians(samples map[string][]float64, a, b string, minRuns int) (float64, float64, error) {
+	var out [2]float64
+	for i, name := range []string{a, b} {
+		runs := slices.Sorted(slices.Values(samples[name]))
+		if len(runs) < max(minRuns, 1) {
+			return 0, 0, fmt.Errorf("benchmark %s ran %d times, want at least %d", name, len(runs), minRuns)
+		}
+		mid := len(runs) / 2
+		out[i] = runs[mid]
+		if len(runs)%2 == 0 {
+			out[i] = (runs[mid-1] + runs[mid]) / 2
+		}
+	}
+	return out[0], out[1], nil
+}
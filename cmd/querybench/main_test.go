@@ -0,0 +1,71 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+const benchOutput = `goos: linux
+goarch: amd64
+pkg: github.com/devdahcoder/golang-query-param-validator.git/validator
+BenchmarkParamLookupMap-8           	 7301451	       156.8 ns/op	       0 B/op	       0 allocs/op
+BenchmarkParamLookupMap-8           	 7638253	       158.3 ns/op	       0 B/op	       0 allocs/op
+BenchmarkParamLookupMap-8           	 7525269	       156.4 ns/op	       0 B/op	       0 allocs/op
+BenchmarkParamLookupPerfectHash-8   	 7672232	       146.6 ns/op	       0 B/op	       0 allocs/op
+BenchmarkParamLookupPerfectHash-8   	12676574	       113.3 ns/op	       0 B/op	       0 allocs/op
+BenchmarkParamLookupPerfectHash-8   	13782578	       114.1 ns/op	       0 B/op	       0 allocs/op
+BenchmarkCompiledRuleSet            	     100	     16053 ns/op
+PASS
+ok  	github.com/devdahcoder/golang-query-param-validator.git/validator	7.020s
+`
+
+func TestParseBenchmarks(t *testing.T) {
+	samples, err := parseBenchmarks(strings.NewReader(benchOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]float64{
+		"ParamLookupMap":         {156.8, 158.3, 156.4},
+		"ParamLookupPerfectHash": {146.6, 113.3, 114.1},
+		"CompiledRuleSet":        {16053},
+	}
+	if len(samples) != len(want) {
+		t.Errorf("got benchmarks %v, want %v", samples, want)
+	}
+	for name, runs := range want {
+		if !slices.Equal(samples[name], runs) {
+			t.Errorf("%s: runs %v, want %v", name, samples[name], runs)
+		}
+	}
+
+	if _, err := parseBenchmarks(strings.NewReader("BenchmarkX-8 10 fast ns/op\n")); err == nil {
+		t.Error("invalid ns/op accepted")
+	}
+}
+
+func TestMedians(t *testing.T) {
+	samples := map[string][]float64{
+		"A": {3, 1, 2},
+		"B": {4, 1, 3, 2},
+		"C": {5},
+	}
+	tests := []struct {
+		a, b    string
+		minRuns int
+		wantA   float64
+		wantB   float64
+		wantErr bool
+	}{
+		{"A", "B", 3, 2, 2.5, false},
+		{"B", "A", 1, 2.5, 2, false},
+		{"A", "C", 3, 0, 0, true},
+		{"A", "missing", 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		a, b, err := medians(samples, tt.a, tt.b, tt.minRuns)
+		if (err != nil) != tt.wantErr || a != tt.wantA || b != tt.wantB {
+			t.Errorf("medians(%s, %s, %d) = %v, %v, %v", tt.a, tt.b, tt.minRuns, a, b, err)
+		}
+	}
+}
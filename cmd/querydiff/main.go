@@ -0,0 +1,69 @@
+// Command querydiff compares two schema documents (the JSON served at
+// validator.WellKnownSchemaPath) and prints a changelog of added and removed
+// routes and parameters and of tightened, loosened and changed rules, so
+// rule changes can be reviewed and gated in CI.
+//
+//	querydiff old.json new.json
+//
+// Breaking changes, which may reject requests that were valid before, are
+// marked. With -exit-code the exit status is 1 when the documents differ,
+// and with -fail-on-breaking when any change is breaking.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/validator"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the changelog as JSON")
+	exitCode := flag.Bool("exit-code", false, "exit with status 1 when the schemas differ")
+	failOnBreaking := flag.Bool("fail-on-breaking", false, "exit with status 1 when a change is breaking")
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		fmt.Fprintln(os.Stderr, "usage: querydiff [-json] [-exit-code] [-fail-on-breaking] old.json new.json")
+		os.Exit(2)
+	}
+	old, err := readSchema(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "querydiff: %v\n", err)
+		os.Exit(2)
+	}
+	updated, err := readSchema(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "querydiff: %v\n", err)
+		os.Exit(2)
+	}
+
+	diff := old.Diff(updated)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(diff)
+	} else if len(diff.Changes) > 0 {
+		fmt.Println(diff)
+	}
+	if (*exitCode && len(diff.Changes) > 0) || (*failOnBreaking && diff.Breaking()) {
+		os.Exit(1)
+	}
+}
+
+func readSchema(path string) (validator.SchemaDocument, error) {
+	var doc validator.SchemaDocument
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return doc, err
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("invalid schema document %s: %v", path, err)
+	}
+	if doc.Version != validator.SchemaDocumentVersion {
+		return doc, fmt.Errorf("unsupported schema document version %q", doc.Version)
+	}
+	return doc, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadSchema(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{`{"version": "1", "routes": [{"route": "/users", "params": [{"name": "q", "rule": "string"}]}]}`, ""},
+		{`{"version": "2", "routes": []}`, `unsupported schema document version "2"`},
+		{`{"routes": [`, "invalid schema document"},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, "schema.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := readSchema(path)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("case %d: %v", i, err)
+		case tt.wantErr == "" && (len(doc.Routes) != 1 || doc.Routes[0].Params[0].Rule != "string"):
+			t.Errorf("case %d: document %+v", i, doc)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("case %d: error %v, want one containing %q", i, err, tt.wantErr)
+		}
+	}
+	if _, err := readSchema(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing file read")
+	}
+}
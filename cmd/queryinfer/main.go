@@ -0,0 +1,141 @@
+// Command queryinfer proposes validation rules from recorded traffic. It
+// reads web server access logs (common/combined format) or HAR files,
+// groups query strings by route and prints the inferred parameters as JSON.
+//
+//	queryinfer access.log
+//	queryinfer -rules -format har session.har
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/validator"
+)
+
+var requestLine = regexp.MustCompile(`"[A-Z]+ (\S+) HTTP/[0-9.]+"`)
+
+// idSegment matches path segments that are almost certainly identifiers.
+var idSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{24,})$`)
+
+type harFile struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL string `json:"url"`
+			} `json:"request"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+func main() {
+	format := flag.String("format", "auto", "input format: auto, log or har")
+	rulesOnly := flag.Bool("rules", false, "print only the proposed rules per route")
+	flag.Parse()
+
+	learner := validator.NewQueryValidator().NewLearner(0)
+
+	inputs := flag.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	for _, name := range inputs {
+		if err := learnFrom(learner, name, *format); err != nil {
+			fmt.Fprintf(os.Stderr, "queryinfer: %s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+
+	var out any = learner.Draft()
+	if *rulesOnly {
+		out = learner.DraftRules()
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		fmt.Fprintf(os.Stderr, "queryinfer: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func learnFrom(learner *validator.Learner, name, format string) error {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if format == "auto" {
+		format = "log"
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			format = "har"
+		}
+	}
+
+	switch format {
+	case "har":
+		var har harFile
+		if err := json.Unmarshal(data, &har); err != nil {
+			return fmt.Errorf("invalid har: %v", err)
+		}
+		for _, entry := range har.Log.Entries {
+			observe(learner, entry.Request.URL)
+		}
+	case "log":
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+		for scanner.Scan() {
+			if m := requestLine.FindStringSubmatch(scanner.Text()); m != nil {
+				observe(learner, m[1])
+			}
+		}
+		return scanner.Err()
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	return nil
+}
+
+func observe(learner *validator.Learner, rawURL string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return
+	}
+	values := make(map[string]string, len(query))
+	for name, vs := range query {
+		values[name] = vs[0]
+	}
+	learner.Observe(routeTemplate(u.Path), values)
+}
+
+// routeTemplate replaces identifier segments so /users/42 and /users/7 are
+// learned as one route, /users/:id.
+func routeTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
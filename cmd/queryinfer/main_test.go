@@ -0,0 +1,94 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/validator"
+)
+
+func TestRouteTemplate(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/users", "/users"},
+		{"/users/42", "/users/:id"},
+		{"/users/42/orders/3f2504e0-4f89-41d3-9a0c-0305e82c3301", "/users/:id/orders/:id"},
+		{"/objects/507f1f77bcf86cd799439011", "/objects/:id"},
+		{"/users/me", "/users/me"},
+		{"/v2/items", "/v2/items"},
+	}
+	for _, tt := range tests {
+		if got := routeTemplate(tt.path); got != tt.want {
+			t.Errorf("routeTemplate(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLearnFrom(t *testing.T) {
+	const accessLog = `127.0.0.1 - - [10/Oct/2024:13:55:36 +0000] "GET /users/42?limit=10&active=true HTTP/1.1" 200 512 "-" "curl/8.0"
+127.0.0.1 - - [10/Oct/2024:13:55:37 +0000] "GET /users/7?limit=25&active=false HTTP/1.1" 200 512 "-" "curl/8.0"
+127.0.0.1 - - [10/Oct/2024:13:55:38 +0000] "GET /health HTTP/1.1" 200 2 "-" "curl/8.0"
+not a request line
+`
+	const har = `{"log": {"entries": [
+		{"request": {"url": "https://api.example.com/search?q=shoes&since=2024-01-02"}},
+		{"request": {"url": "https://api.example.com/search?q=boots"}}
+	]}}`
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "access.log")
+	harFile := filepath.Join(dir, "session.har")
+	os.WriteFile(logFile, []byte(accessLog), 0o644)
+	os.WriteFile(harFile, []byte(har), 0o644)
+
+	tests := []struct {
+		name   string
+		file   string
+		format string
+		want   map[string]map[string]string
+	}{
+		{"log", logFile, "log", map[string]map[string]string{
+			"/users/:id": {"limit": "number", "active": "boolean"},
+		}},
+		{"auto-detected log", logFile, "auto", map[string]map[string]string{
+			"/users/:id": {"limit": "number", "active": "boolean"},
+		}},
+		{"auto-detected har", harFile, "auto", map[string]map[string]string{
+			"/search": {"q": "string", "since": "date"},
+		}},
+	}
+	for _, tt := range tests {
+		learner := validator.NewQueryValidator().NewLearner(0)
+		if err := learnFrom(learner, tt.file, tt.format); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		got := learner.DraftRules()
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: routes %v, want %v", tt.name, got, tt.want)
+		}
+		for route, rules := range tt.want {
+			if !maps.Equal(got[route], rules) {
+				t.Errorf("%s: rules for %s = %v, want %v", tt.name, route, got[route], rules)
+			}
+		}
+	}
+}
+
+func TestLearnFromErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.har")
+	os.WriteFile(bad, []byte(`{"log": [`), 0o644)
+	learner := validator.NewQueryValidator().NewLearner(0)
+	for _, tt := range []struct{ file, format string }{
+		{bad, "auto"},
+		{bad, "csv"},
+		{filepath.Join(dir, "missing.log"), "log"},
+	} {
+		if err := learnFrom(learner, tt.file, tt.format); err == nil {
+			t.Errorf("learnFrom(%s, %s) returned no error", tt.file, tt.format)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Command queryreplay replays captured query strings against a schema
+// document (the JSON served at validator.WellKnownSchemaPath) and reports
+// which requests would now be rejected and why, so schema changes can be
+// vetted against real traffic before they ship.
+//
+//	queryreplay -schema schema.json captured.txt
+//
+// Each input line is a request target such as /users/42?age=30. Lines
+// without a path are checked against the route given by -route. The exit
+// status is 1 when any request would fail.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/validator"
+)
+
+type failedRequest struct {
+	Line   int                              `json:"line"`
+	Target string                           `json:"target"`
+	Route  string                           `json:"route,omitempty"`
+	Errors []validator.QueryValidationError `json:"errors"`
+}
+
+type report struct {
+	Total  int             `json:"total"`
+	Failed []failedRequest `json:"failed"`
+}
+
+func main() {
+	schemaPath := flag.String("schema", "", "schema document to validate against (required)")
+	defaultRoute := flag.String("route", "", "route for lines that carry only a query string")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *schemaPath == "" {
+		fmt.Fprintln(os.Stderr, "queryreplay: -schema is required")
+		os.Exit(2)
+	}
+	doc, err := readSchema(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "queryreplay: %v\n", err)
+		os.Exit(2)
+	}
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 && flag.Arg(0) != "-" {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "queryreplay: %v\n", err)
+			os.Exit(2)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	rep, err := replay(validator.NewQueryValidator(), doc, *defaultRoute, in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "queryreplay: %v\n", err)
+		os.Exit(2)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rep)
+	} else {
+		printReport(os.Stdout, rep)
+	}
+	if len(rep.Failed) > 0 {
+		os.Exit(1)
+	}
+}
+
+func readSchema(path string) (validator.SchemaDocument, error) {
+	var doc validator.SchemaDocument
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return doc, err
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return doc, fmt.Errorf("invalid schema document %s: %v", path, err)
+	}
+	if doc.Version != validator.SchemaDocumentVersion {
+		return doc, fmt.Errorf("unsupported schema document version %q", doc.Version)
+	}
+	return doc, nil
+}
+
+func replay(qv *validator.QueryValidator, doc validator.SchemaDocument, defaultRoute string, in io.Reader) (report, error) {
+	var rep report
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		target := strings.TrimSpace(scanner.Text())
+		if target == "" || strings.HasPrefix(target, "#") {
+			continue
+		}
+		rep.Total++
+
+		path, rawQuery, _ := strings.Cut(target, "?")
+		if !strings.HasPrefix(path, "/") {
+			path, rawQuery = defaultRoute, strings.TrimPrefix(target, "?")
+		}
+		failed := failedRequest{Line: line, Target: target}
+
+		schema, ok := matchRoute(doc, path)
+		if !ok {
+			failed.Errors = []validator.QueryValidationError{{Message: "no schema for route " + path}}
+			rep.Failed = append(rep.Failed, failed)
+			continue
+		}
+		failed.Route = schema.Route
+
+		query, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			failed.Errors = []validator.QueryValidationError{{Message: "malformed query: " + err.Error()}}
+			rep.Failed = append(rep.Failed, failed)
+			continue
+		}
+		if errs := qv.ValidateValues(query, schema.Rules()); len(errs) > 0 {
+			sort.SliceStable(errs, func(i, j int) bool { return errs[i].Parameter < errs[j].Parameter })
+			failed.Errors = errs
+			rep.Failed = append(rep.Failed, failed)
+		}
+	}
+	return rep, scanner.Err()
+}
+
+// matchRoute finds the schema whose route pattern matches path. Segments
+// starting with ':' match any single segment and '*' matches the rest.
+func matchRoute(doc validator.SchemaDocument, path string) (validator.RouteSchema, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, schema := range doc.Routes {
+		if routeMatches(strings.Split(strings.Trim(schema.Route, "/"), "/"), segments) {
+			return schema, true
+		}
+	}
+	return validator.RouteSchema{}, false
+}
+
+func routeMatches(pattern, segments []string) bool {
+	for i, p := range pattern {
+		if p == "*" {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if !strings.HasPrefix(p, ":") && p != segments[i] {
+			return false
+		}
+	}
+	return len(pattern) == len(segments)
+}
+
+func printReport(w io.Writer, rep report) {
+	for _, f := range rep.Failed {
+		fmt.Fprintf(w, "line %d: %s\n", f.Line, f.Target)
+		for _, e := range f.Errors {
+			if e.Parameter == "" {
+				fmt.Fprintf(w, "  %s\n", e.Message)
+				continue
+			}
+			fmt.Fprintf(w, "  %s=%q: %s\n", e.Parameter, e.Value, e.Message)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d requests would fail\n", len(rep.Failed), rep.Total)
+}
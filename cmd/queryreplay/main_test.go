@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/validator"
+)
+
+var testDoc = validator.SchemaDocument{Version: validator.SchemaDocumentVersion, Routes: []validator.RouteSchema{
+	{Route: "/users/:id", Params: []validator.ParamSchema{{Name: "age", Rule: "number"}}},
+	{Route: "/files/*", Params: []validator.ParamSchema{{Name: "download", Rule: "boolean"}}},
+	{Route: "/search", Params: []validator.ParamSchema{{Name: "q", Rule: "required"}}},
+}}
+
+func TestMatchRoute(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/users/42", "/users/:id"},
+		{"/users/42/", "/users/:id"},
+		{"/users", ""},
+		{"/users/42/orders", ""},
+		{"/files/a/b/c", "/files/*"},
+		{"/search", "/search"},
+		{"/other", ""},
+	}
+	for _, tt := range tests {
+		schema, ok := matchRoute(testDoc, tt.path)
+		if ok != (tt.want != "") || schema.Route != tt.want {
+			t.Errorf("matchRoute(%q) = %q, %v, want %q", tt.path, schema.Route, ok, tt.want)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	input := `# captured on Monday
+/users/42?age=30
+/users/42?age=old
+
+/search
+?q=shoes
+/nowhere?x=1
+/search?q=%zz
+`
+	rep, err := replay(validator.NewQueryValidator(), testDoc, "/search", strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Total != 6 {
+		t.Errorf("total %d, want 6", rep.Total)
+	}
+	type failure struct {
+		line    int
+		route   string
+		message string
+	}
+	want := []failure{
+		{3, "/users/:id", "invalid value for type number"},
+		{5, "/search", "missing required parameter"},
+		{7, "", "no schema for route /nowhere"},
+		{8, "/search", "malformed query"},
+	}
+	if len(rep.Failed) != len(want) {
+		t.Fatalf("failed %+v, want %d requests", rep.Failed, len(want))
+	}
+	for i, w := range want {
+		f := rep.Failed[i]
+		if f.Line != w.line || f.Route != w.route || len(f.Errors) == 0 || !strings.HasPrefix(f.Errors[0].Message, w.message) {
+			t.Errorf("failure %d = %+v, want line %d, route %q, message %q", i, f, w.line, w.route, w.message)
+		}
+	}
+
+	var out strings.Builder
+	printReport(&out, rep)
+	if !strings.Contains(out.String(), `age="old": invalid value for type number`) ||
+		!strings.HasSuffix(out.String(), "4 of 6 requests would fail\n") {
+		t.Errorf("report:\n%s", out.String())
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		body    string
+		wantErr bool
+	}{
+		{`{"version": "1", "routes": [{"route": "/a", "params": [{"name": "q", "rule": "string"}]}]}`, false},
+		{`{"version": "2", "routes": []}`, true},
+		{`{"routes": `, true},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, "schema.json")
+		os.WriteFile(path, []byte(tt.body), 0o644)
+		doc, err := readSchema(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("case %d: error %v, want error %v", i, err, tt.wantErr)
+		}
+		if !tt.wantErr && (len(doc.Routes) != 1 || doc.Routes[0].Rules()["q"] != "string") {
+			t.Errorf("case %d: document %+v", i, doc)
+		}
+	}
+	if _, err := readSchema(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("missing schema read")
+	}
+}
@@ -0,0 +1,207 @@
+// Package perfect builds minimal perfect hash tables over fixed sets of
+// string keys, for lookups on hot paths whose keys are known up front.
+//
+// Like gperf, a table hashes only the length of a key and the bytes at a few
+// positions, chosen when the table is built so they tell the keys apart, so
+// long keys cost no more to look up than short ones. The hashes are spread
+// over one slot per key by hash and displace: each bucket of colliding hashes
+// gets a seed that moves its keys to free slots. A lookup is one short hash,
+// one seed and one string comparison.
+package perfect
+
+import "slices"
+
+// maxPositions caps the sampled positions; keys needing more are hashed
+// whole.
+const maxPositions = 8
+
+// maxSeeds bounds the seeds tried to place a bucket.
+const maxSeeds = 1 << 16
+
+// Table maps each of a fixed set of keys to a value.
+type Table[V any] struct {
+	// positions are the byte offsets hashed, unless whole keys are.
+	positions []int
+	whole     bool
+	seeds     []uint32
+	keys      []string
+	values    []V
+}
+
+// New builds a table mapping keys[i] to values[i]. ok is false when keys
+// has duplicates or, very rarely, when no placement was found.
+func New[V any](keys []string, values []V) (t *Table[V], ok bool) {
+	if len(keys) != len(values) {
+		return nil, false
+	}
+	t = &Table[V]{}
+	if len(keys) == 0 {
+		return t, true
+	}
+	t.positions, t.whole = choosePositions(keys)
+	hashes := make([]uint64, len(keys))
+	for i, key := range keys {
+		hashes[i] = t.hash(key)
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(hashes)))) != len(keys) {
+		return nil, false
+	}
+
+	n := uint64(len(keys))
+	buckets := make([][]int, n)
+	for i, h := range hashes {
+		buckets[h%n] = append(buckets[h%n], i)
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return len(buckets[b]) - len(buckets[a]) })
+
+	t.seeds = make([]uint32, n)
+	t.keys = make([]string, n)
+	t.values = make([]V, n)
+	taken := make([]bool, n)
+	slots := make([]uint64, 0, len(keys))
+	for _, b := range order {
+		if len(buckets[b]) == 0 {
+			break
+		}
+		placed := false
+		for seed := uint32(1); seed < maxSeeds && !placed; seed++ {
+			slots = slots[:0]
+			placed = true
+			for _, i := range buckets[b] {
+				slot := mix(hashes[i]^uint64(seed)) % n
+				if taken[slot] || slices.Contains(slots, slot) {
+					placed = false
+					break
+				}
+				slots = append(slots, slot)
+			}
+			if placed {
+				t.seeds[b] = seed
+			}
+		}
+		if !placed {
+			return nil, false
+		}
+		for j, i := range buckets[b] {
+			taken[slots[j]] = true
+			t.keys[slots[j]] = keys[i]
+			t.values[slots[j]] = values[i]
+		}
+	}
+	return t, true
+}
+
+// Get returns the value of key. ok is false for keys outside the table.
+func (t *Table[V]) Get(key string) (value V, ok bool) {
+	if t == nil || len(t.keys) == 0 {
+		return value, false
+	}
+	n := uint64(len(t.keys))
+	h := t.hash(key)
+	slot := mix(h^uint64(t.seeds[h%n])) % n
+	if t.keys[slot] != key {
+		return value, false
+	}
+	return t.values[slot], true
+}
+
+// Len returns the number of keys.
+func (t *Table[V]) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.keys)
+}
+
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// hash is FNV-1a over the length of key and its sampled bytes, with 0 for
+// positions beyond its end.
+func (t *Table[V]) hash(key string) uint64 {
+	h := uint64(offset64)
+	h = (h ^ uint64(len(key))) * prime64
+	if t.whole {
+		for i := 0; i < len(key); i++ {
+			h = (h ^ uint64(key[i])) * prime64
+		}
+		return h
+	}
+	for _, p := range t.positions {
+		var c byte
+		if p < len(key) {
+			c = key[p]
+		}
+		h = (h ^ uint64(c)) * prime64
+	}
+	return h
+}
+
+// mix is the splitmix64 finalizer, which makes the low bits of a hash depend
+// on all of its bits.
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// choosePositions picks byte positions telling keys apart together with
+// their lengths, greedily adding the position that splits the most keys
+// sharing a signature. whole is true when more than maxPositions are needed.
+func choosePositions(keys []string) (positions []int, whole bool) {
+	longest := 0
+	for _, key := range keys {
+		longest = max(longest, len(key))
+	}
+	for {
+		groups := signatureGroups(keys, positions)
+		if groups == len(keys) {
+			return positions, false
+		}
+		if len(positions) == maxPositions {
+			return nil, true
+		}
+		best, bestGroups := -1, groups
+		for p := 0; p < longest; p++ {
+			if slices.Contains(positions, p) {
+				continue
+			}
+			if g := signatureGroups(keys, append(positions, p)); g > bestGroups {
+				best, bestGroups = p, g
+			}
+		}
+		if best < 0 {
+			return nil, true
+		}
+		positions = append(positions, best)
+	}
+}
+
+// signatureGroups counts the distinct signatures of keys: their length and
+// their bytes at positions.
+func signatureGroups(keys []string, positions []int) int {
+	seen := make(map[string]struct{}, len(keys))
+	sig := make([]byte, 0, 8+len(positions))
+	for _, key := range keys {
+		n := len(key)
+		sig = append(sig[:0], byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+		for _, p := range positions {
+			var c byte
+			if p < len(key) {
+				c = key[p]
+			}
+			sig = append(sig, c)
+		}
+		seen[string(sig)] = struct{}{}
+	}
+	return len(seen)
+}
@@ -0,0 +1,84 @@
+package perfect
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{"empty", nil},
+		{"one", []string{"q"}},
+		{"params", []string{"q", "page", "size", "sort", "email", "status", "from", "to", "cursor", "limit"}},
+		{"shared prefixes", []string{"filter[status]", "filter[state]", "filter[stat]", "filter[s]", "filter"}},
+		{"prefix sequence", sequence("param_", 200)},
+		// Keys differing in more than maxPositions bytes are hashed whole.
+		{"whole keys", oneByteOff(12)},
+	}
+	for _, tt := range tests {
+		values := make([]int, len(tt.keys))
+		for i := range values {
+			values[i] = i
+		}
+		table, ok := New(tt.keys, values)
+		if !ok {
+			t.Fatalf("%s: New failed", tt.name)
+		}
+		if whole := tt.name == "whole keys"; table.whole != whole {
+			t.Errorf("%s: whole = %v, want %v", tt.name, table.whole, whole)
+		}
+		if table.Len() != len(tt.keys) {
+			t.Errorf("%s: Len = %d, want %d", tt.name, table.Len(), len(tt.keys))
+		}
+		for i, key := range tt.keys {
+			if got, ok := table.Get(strings.Clone(key)); !ok || got != i {
+				t.Errorf("%s: Get(%q) = %d, %v, want %d", tt.name, key, got, ok, i)
+			}
+		}
+		for _, miss := range []string{"", "missing", "Q", "page ", "filter[x]", "param_200"} {
+			if _, ok := table.Get(miss); ok {
+				t.Errorf("%s: Get(%q) found a key outside the table", tt.name, miss)
+			}
+		}
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	if _, ok := New([]string{"a", "b", "a"}, []int{1, 2, 3}); ok {
+		t.Error("New accepted duplicate keys")
+	}
+	if _, ok := New([]string{"a", "b"}, []int{1}); ok {
+		t.Error("New accepted more keys than values")
+	}
+}
+
+func TestNilTable(t *testing.T) {
+	var table *Table[int]
+	if _, ok := table.Get("a"); ok || table.Len() != 0 {
+		t.Error("nil table is not empty")
+	}
+}
+
+// oneByteOff returns n keys of n bytes each differing from the others in
+// one byte, so no fewer than n-1 positions tell them apart.
+func oneByteOff(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		key := []byte(strings.Repeat("a", n))
+		key[i] = 'b'
+		keys[i] = string(key)
+	}
+	return keys
+}
+
+func sequence(prefix string, n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return keys
+}
@@ -0,0 +1,125 @@
+// Package presets holds ready-made rule sets for parameters most APIs share.
+package presets
+
+import (
+	"math"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/validator"
+)
+
+// PaginationOptions configures Pagination.
+type PaginationOptions struct {
+	// LimitParam names the page size parameter, limit when empty; per_page
+	// is the other common spelling.
+	LimitParam string
+	// DefaultLimit is the page size when none is sent, 20 when zero, and at
+	// most MaxLimit.
+	DefaultLimit int
+	// MaxLimit and MaxPage cap the page size and number; zero leaves them
+	// uncapped.
+	MaxLimit int
+	MaxPage  int
+	// ClampLimit moves page sizes beyond MaxLimit onto it, with a CLAMPED
+	// warning, rather than rejecting them.
+	ClampLimit bool
+	// Offset accepts an offset parameter, counting items to skip, in place
+	// of page. At most one of the two may be sent.
+	Offset bool
+}
+
+// DefaultPaginationOptions suit typical list endpoints: pages of 20 items,
+// at most 100.
+var DefaultPaginationOptions = PaginationOptions{
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
+// Paginator holds the rules Pagination built.
+type Paginator struct {
+	opts   PaginationOptions
+	page   validator.TypedRule[int]
+	limit  validator.TypedRule[int]
+	offset validator.TypedRule[int]
+}
+
+// PageParams is the page a request asked for. Page counts from 1 and Offset
+// from 0; each is derived from the other when only one is sent.
+type PageParams struct {
+	Page   int
+	Limit  int
+	Offset int
+}
+
+// Pagination builds rules for the page, page size and, with opts.Offset,
+// offset parameters:
+//
+//	pages := presets.Pagination(presets.DefaultPaginationOptions)
+//	app.Get("/orders", func(c fiber.Ctx) error {
+//		p := pages.Params(validator.QueryResult(c))
+//		return c.JSON(listOrders(p.Offset, p.Limit))
+//	}, qv.Middleware(pages.Rules()))
+func Pagination(opts PaginationOptions) Paginator {
+	if opts.LimitParam == "" {
+		opts.LimitParam = "limit"
+	}
+	if opts.DefaultLimit <= 0 {
+		opts.DefaultLimit = 20
+	}
+	if opts.MaxLimit > 0 && opts.DefaultLimit > opts.MaxLimit {
+		opts.DefaultLimit = opts.MaxLimit
+	}
+
+	page := []validator.RuleOption{validator.Min(1)}
+	if opts.MaxPage > 0 {
+		page = append(page, validator.Max(float64(opts.MaxPage)))
+	}
+	limit := []validator.RuleOption{validator.Min(1)}
+	if opts.MaxLimit > 0 {
+		limit = append(limit, validator.Max(float64(opts.MaxLimit)))
+		if opts.ClampLimit {
+			limit = append(limit, validator.Clamp())
+		}
+	}
+	return Paginator{
+		opts:   opts,
+		page:   validator.Int[int](page...),
+		limit:  validator.Int[int](limit...),
+		offset: validator.Int[int](validator.Min(0)),
+	}
+}
+
+// Rules returns the rules of the pagination parameters, to merge into an
+// endpoint's own.
+func (p Paginator) Rules() validator.Rules {
+	rules := validator.Rules{
+		"page":            p.page.String(),
+		p.opts.LimitParam: p.limit.String(),
+	}
+	if p.opts.Offset {
+		rules["offset"] = p.offset.String() + " AND mutually_exclusive:page,offset"
+	}
+	return rules
+}
+
+// Params extracts the page from a validation result, using page 1 and the
+// default page size for parameters that were absent or rejected.
+func (p Paginator) Params(result validator.ValidationResult) PageParams {
+	params := PageParams{Page: 1, Limit: p.opts.DefaultLimit}
+	if n, ok := result.Int(p.opts.LimitParam); ok {
+		params.Limit = int(n)
+	}
+	if n, ok := result.Int("offset"); ok && p.opts.Offset {
+		params.Offset = int(n)
+		params.Page = params.Offset/params.Limit + 1
+		return params
+	}
+	if n, ok := result.Int("page"); ok {
+		params.Page = int(n)
+	}
+	if params.Page-1 > math.MaxInt/params.Limit {
+		params.Offset = math.MaxInt
+	} else {
+		params.Offset = (params.Page - 1) * params.Limit
+	}
+	return params
+}
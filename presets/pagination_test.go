@@ -0,0 +1,64 @@
+package presets
+
+import (
+	"maps"
+	"math"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/validator"
+)
+
+func TestPaginationRules(t *testing.T) {
+	got := Pagination(PaginationOptions{LimitParam: "per_page", MaxLimit: 50, MaxPage: 10, Offset: true}).Rules()
+	want := validator.Rules{
+		"page":     "int:64 AND min:1 AND max:10",
+		"per_page": "int:64 AND min:1 AND max:50",
+		"offset":   "int:64 AND min:0 AND mutually_exclusive:page,offset",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("Rules() = %v, want %v", got, want)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   PaginationOptions
+		query  string
+		want   PageParams
+		errors []string
+	}{
+		{"defaults", DefaultPaginationOptions, "", PageParams{Page: 1, Limit: 20, Offset: 0}, nil},
+		{"page and limit", DefaultPaginationOptions, "page=3&limit=10", PageParams{Page: 3, Limit: 10, Offset: 20}, nil},
+		{"limit too large", DefaultPaginationOptions, "limit=500", PageParams{Page: 1, Limit: 20, Offset: 0}, []string{"limit:TOO_LARGE"}},
+		{"page zero", DefaultPaginationOptions, "page=0", PageParams{Page: 1, Limit: 20, Offset: 0}, []string{"page:TOO_SMALL"}},
+		{"clamped limit", PaginationOptions{MaxLimit: 100, ClampLimit: true}, "limit=500", PageParams{Page: 1, Limit: 100, Offset: 0}, []string{"limit:CLAMPED"}},
+		{"per_page", PaginationOptions{LimitParam: "per_page"}, "per_page=5&page=2", PageParams{Page: 2, Limit: 5, Offset: 5}, nil},
+		{"default above max", PaginationOptions{DefaultLimit: 50, MaxLimit: 10}, "", PageParams{Page: 1, Limit: 10, Offset: 0}, nil},
+		{"offset", PaginationOptions{Offset: true}, "offset=45&limit=10", PageParams{Page: 5, Limit: 10, Offset: 45}, nil},
+		{"offset with page", PaginationOptions{Offset: true}, "offset=45&page=2", PageParams{Page: 3, Limit: 20, Offset: 45}, []string{"page:MUTUALLY_EXCLUSIVE"}},
+		{"offset disabled", DefaultPaginationOptions, "offset=45", PageParams{Page: 1, Limit: 20, Offset: 0}, []string{"offset:UNEXPECTED_PARAM"}},
+		{"huge page", PaginationOptions{}, "page=9223372036854775807&limit=1000", PageParams{Page: math.MaxInt, Limit: 1000, Offset: math.MaxInt}, nil},
+	}
+	for _, tt := range tests {
+		pages := Pagination(tt.opts)
+		values, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := validator.NewQueryValidator().ValidateValuesResult(values, pages.Rules())
+		var codes []string
+		for _, err := range result.Errors {
+			codes = append(codes, err.Parameter+":"+err.Code)
+		}
+		slices.Sort(codes)
+		if !slices.Equal(codes, tt.errors) {
+			t.Errorf("%s: errors %v, want %v", tt.name, codes, tt.errors)
+		}
+		if got := pages.Params(result); got != tt.want {
+			t.Errorf("%s: Params = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package validator
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyChecker confirms that an API key exists, typically against a database
+// or an auth service.
+type KeyChecker interface {
+	KeyExists(ctx context.Context, key string) (bool, error)
+}
+
+// APIKeyOptions configures an API key validator.
+type APIKeyOptions struct {
+	// Prefix is required at the start of every key, e.g. "sk_live_".
+	Prefix string
+	// MinLength and MaxLength bound the whole key, prefix included.
+	MinLength int
+	MaxLength int
+	// Charset lists the bytes allowed after the prefix.
+	Charset string
+	// Checker, when set, is consulted for keys that pass the format checks.
+	// Lookup errors and timeouts reject the key.
+	Checker KeyChecker
+	// Timeout bounds a single Checker call. Zero means one second.
+	Timeout time.Duration
+	// CacheTTL keeps Checker answers for this long. Zero disables caching.
+	CacheTTL time.Duration
+	// MaxCacheEntries bounds the cache. Zero means 1024.
+	MaxCacheEntries int
+}
+
+// DefaultAPIKeyOptions is used for the built-in "apikey" type.
+var DefaultAPIKeyOptions = APIKeyOptions{
+	MinLength: 20,
+	MaxLength: 128,
+	Charset:   urlSafeCharset,
+}
+
+type apiKeyCacheEntry struct {
+	exists  bool
+	expires time.Time
+}
+
+type apiKeyCache struct {
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]apiKeyCacheEntry
+	max     int
+}
+
+// NewAPIKeyValidator returns a type validator checking the key format and,
+// when opts.Checker is set, that the key exists.
+func NewAPIKeyValidator(opts APIKeyOptions) func(string) bool {
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Second
+	}
+	if opts.MaxCacheEntries <= 0 {
+		opts.MaxCacheEntries = 1024
+	}
+	cache := &apiKeyCache{
+		entries: make(map[[sha256.Size]byte]apiKeyCacheEntry),
+		max:     opts.MaxCacheEntries,
+	}
+
+	return func(v string) bool {
+		if !validAPIKeyFormat(v, opts) {
+			return false
+		}
+		if opts.Checker == nil {
+			return true
+		}
+
+		// Keys are only held as digests so the cache never stores secrets.
+		id := sha256.Sum256([]byte(v))
+		if opts.CacheTTL > 0 {
+			if exists, ok := cache.get(id); ok {
+				return exists
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+		defer cancel()
+		exists, err := opts.Checker.KeyExists(ctx, v)
+		if err != nil {
+			return false
+		}
+		if opts.CacheTTL > 0 {
+			cache.put(id, exists, opts.CacheTTL)
+		}
+		return exists
+	}
+}
+
+func validAPIKeyFormat(v string, opts APIKeyOptions) bool {
+	if len(v) < opts.MinLength || (opts.MaxLength > 0 && len(v) > opts.MaxLength) {
+		return false
+	}
+	rest, ok := strings.CutPrefix(v, opts.Prefix)
+	if !ok || rest == "" {
+		return false
+	}
+	for i := 0; i < len(rest); i++ {
+		if strings.IndexByte(opts.Charset, rest[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *apiKeyCache) get(id [sha256.Size]byte) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[id]
+	if !ok || time.Now().After(entry.expires) {
+		return false, false
+	}
+	return entry.exists, true
+}
+
+func (c *apiKeyCache) put(id [sha256.Size]byte, exists bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= c.max {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.max {
+			clear(c.entries)
+		}
+	}
+	c.entries[id] = apiKeyCacheEntry{exists: exists, expires: now.Add(ttl)}
+}
@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeKeyChecker knows the keys in known, counting lookups.
+type fakeKeyChecker struct {
+	known map[string]bool
+	err   error
+	delay time.Duration
+	calls atomic.Int32
+}
+
+func (c *fakeKeyChecker) KeyExists(ctx context.Context, key string) (bool, error) {
+	c.calls.Add(1)
+	if c.delay > 0 {
+		select {
+		case <-time.After(c.delay):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	return c.known[key], c.err
+}
+
+func TestAPIKeyFormat(t *testing.T) {
+	valid := NewAPIKeyValidator(APIKeyOptions{Prefix: "sk_live_", MinLength: 16, MaxLength: 32, Charset: urlSafeCharset})
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"sk_live_abcdefgh", true},
+		{"sk_live_abcdefg", false},
+		{"sk_live_" + strings.Repeat("a", 24), true},
+		{"sk_live_" + strings.Repeat("a", 25), false},
+		{"sk_test_abcdefgh", false},
+		{"sk_live_abcd efgh", false},
+		{"sk_live_abcd=efgh", false},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.key); got != tt.want {
+			t.Errorf("api key %q = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+	if NewAPIKeyValidator(APIKeyOptions{Prefix: "sk_", Charset: urlSafeCharset})("sk_") {
+		t.Error("key with nothing after the prefix accepted")
+	}
+}
+
+func TestAPIKeyChecker(t *testing.T) {
+	const key = "sk_abcdefghijklmnop"
+	checker := &fakeKeyChecker{known: map[string]bool{key: true}}
+	opts := APIKeyOptions{Prefix: "sk_", Charset: urlSafeCharset, Checker: checker}
+
+	valid := NewAPIKeyValidator(opts)
+	if !valid(key) || valid("sk_unknownunknown") {
+		t.Error("checker answers not followed")
+	}
+	if valid("pk_abcdefghijklmnop"); checker.calls.Load() != 2 {
+		t.Errorf("checker called %d times, want 2: malformed keys must not be looked up", checker.calls.Load())
+	}
+
+	checker.calls.Store(0)
+	opts.CacheTTL = time.Minute
+	cached := NewAPIKeyValidator(opts)
+	for range 3 {
+		if !cached(key) {
+			t.Fatal("known key rejected")
+		}
+	}
+	if n := checker.calls.Load(); n != 1 {
+		t.Errorf("checker called %d times with caching, want 1", n)
+	}
+}
+
+func TestAPIKeyCheckerFailures(t *testing.T) {
+	const key = "sk_abcdefghijklmnop"
+	tests := []struct {
+		name    string
+		checker *fakeKeyChecker
+	}{
+		{"lookup error", &fakeKeyChecker{known: map[string]bool{key: true}, err: errors.New("db down")}},
+		{"timeout", &fakeKeyChecker{known: map[string]bool{key: true}, delay: time.Second}},
+	}
+	for _, tt := range tests {
+		valid := NewAPIKeyValidator(APIKeyOptions{
+			Prefix: "sk_", Charset: urlSafeCharset, Checker: tt.checker, Timeout: 10 * time.Millisecond,
+		})
+		if valid(key) {
+			t.Errorf("%s: key accepted", tt.name)
+		}
+	}
+}
+
+func TestAPIKeyType(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"key": "apikey"}
+	checkCodes(t, qv, map[string]string{"key": "abcdefghij0123456789"}, rules)
+	checkCodes(t, qv, map[string]string{"key": "too-short"}, rules, "key:INVALID_TYPE")
+}
@@ -0,0 +1,140 @@
+package validator
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Array rules validate lists sent as repeated keys, comma-separated values
+// or both, as in "ids": "array:number|maxitems:50". The array term names
+// the element type; item-count terms bound the list and every other term
+// applies to each element.
+const (
+	arrayTerm    = "array"
+	minItemsTerm = "minitems"
+	maxItemsTerm = "maxitems"
+)
+
+type arrayRule struct {
+	elem               string
+	minItems, maxItems int
+}
+
+// parseArrayRule splits rule into its element rule and item bounds. ok is
+// false when rule is not an array rule. Bounds are -1 when absent.
+func parseArrayRule(rule string) (arrayRule, bool) {
+	if !strings.Contains(rule, arrayTerm+":") {
+		return arrayRule{}, false
+	}
+	spec := arrayRule{minItems: -1, maxItems: -1}
+	isArray := false
+	var elem []string
+	for _, term := range ruleTerms(rule) {
+		name, arg, _ := strings.Cut(term, ":")
+		switch {
+		case strings.ContainsAny(term, "()") || strings.Contains(term, " OR "):
+			// Rules joined by OR come back whole; array terms there are
+			// plain constraints.
+			return arrayRule{}, false
+		case name == arrayTerm:
+			isArray = true
+			elem = append([]string{arg}, elem...)
+		case name == minItemsTerm:
+			spec.minItems, _ = strconv.Atoi(arg)
+		case name == maxItemsTerm:
+			spec.maxItems, _ = strconv.Atoi(arg)
+		case name == requiredType, name+":" == skipPrefix:
+		default:
+			elem = append(elem, term)
+		}
+	}
+	spec.elem = strings.Join(elem, " AND ")
+	return spec, isArray
+}
+
+func isArrayRule(rule string) bool {
+	_, ok := parseArrayRule(rule)
+	return ok
+}
+
+// splitItems splits a comma-separated list; the empty list has no items.
+func splitItems(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// validateArray checks the item count of value and each of its items,
+// reporting failed items with their index.
+func (qv *QueryValidator) validateArray(ctx context.Context, param, value string, spec arrayRule) []QueryValidationError {
+	items := splitItems(value)
+	switch {
+	case spec.minItems >= 0 && len(items) < spec.minItems:
+		return []QueryValidationError{newValidationError(param, value, failTerm(minItemsTerm, MsgTooFewItems, spec.minItems))}
+	case spec.maxItems >= 0 && len(items) > spec.maxItems:
+		return []QueryValidationError{newValidationError(param, value, failTerm(maxItemsTerm, MsgTooManyItems, spec.maxItems))}
+	}
+
+	var errors []QueryValidationError
+	for i, item := range items {
+		var reasons []failure
+		if qv.reportAll {
+			reasons = qv.checkParamValueAll(ctx, item, spec.elem)
+		} else if reason, ok := qv.checkParamValue(ctx, item, spec.elem); !ok {
+			reasons = []failure{reason}
+		}
+		for _, reason := range reasons {
+			err := newValidationError(param, item, reason)
+			err.Index = &i
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
+
+// arrayConstraints are the array terms as plain constraints, for rules that
+// nest them where validateArray does not apply.
+func (qv *QueryValidator) arrayConstraints() {
+	qv.constraints[arrayTerm] = func(elem string) (func(string) bool, error) {
+		if _, err := qv.parseTypeExpr(elem); err != nil {
+			return nil, err
+		}
+		return func(v string) bool {
+			for _, item := range splitItems(v) {
+				if _, ok := qv.checkParamValue(context.Background(), item, elem); !ok {
+					return false
+				}
+			}
+			return true
+		}, nil
+	}
+	qv.constraints[minItemsTerm] = func(arg string) (func(string) bool, error) {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(v string) bool { return len(splitItems(v)) >= n }, nil
+	}
+	qv.constraints[maxItemsTerm] = func(arg string) (func(string) bool, error) {
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(v string) bool { return len(splitItems(v)) <= n }, nil
+	}
+}
+
+// joinArrayValues joins the repeated values of array parameters into one
+// comma-separated value, so ?id=1&id=2 and ?id=1,2 validate alike.
+func joinArrayValues(flat map[string]string, multi func(param string) []string, rules map[string]string) {
+	for param, rule := range rules {
+		if !isArrayRule(rule) {
+			continue
+		}
+		if values := multi(param); len(values) > 1 {
+			flat[param] = strings.Join(values, ",")
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestArrayRules(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"ids":  "array:number|maxitems:3",
+		"tags": "required AND array:string AND minitems:1 AND maxlen:5",
+		"n":    "array:integer|min:1",
+	}
+	tests := []struct {
+		values url.Values
+		want   []string
+	}{
+		{url.Values{"tags": {"go"}, "ids": {"1", "2"}}, nil},
+		{url.Values{"tags": {"go"}, "ids": {"1,2,3"}}, nil},
+		{url.Values{"tags": {"go"}, "ids": {"1,2", "3"}}, nil},
+		{url.Values{"tags": {"go"}, "ids": {"1,2", "3", "4"}}, []string{"ids:TOO_MANY_ITEMS"}},
+		{url.Values{"tags": {"go"}, "ids": {"1", "x", "y"}}, []string{"ids:INVALID_TYPE", "ids:INVALID_TYPE"}},
+		{url.Values{"tags": {""}}, []string{"tags:TOO_FEW_ITEMS"}},
+		{url.Values{"tags": {"go,golang"}}, []string{"tags:TOO_LONG"}},
+		{url.Values{"ids": {"1"}}, []string{"tags:MISSING_REQUIRED"}},
+		{url.Values{"tags": {"go"}, "n": {"0,1"}}, []string{"n:TOO_SMALL"}},
+	}
+	for _, tt := range tests {
+		if got := errorCodes(qv.ValidateValues(tt.values, rules)); !slices.Equal(got, tt.want) {
+			t.Errorf("ValidateValues(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestArrayErrorIndex(t *testing.T) {
+	qv := NewQueryValidator()
+	errs := qv.ValidateValues(url.Values{"ids": {"1", "x", "3,y"}}, map[string]string{"ids": "array:integer"})
+	if len(errs) != 2 {
+		t.Fatalf("got %d errors, want 2: %+v", len(errs), errs)
+	}
+	for i, want := range []struct {
+		index int
+		value string
+	}{{1, "x"}, {3, "y"}} {
+		if errs[i].Index == nil || *errs[i].Index != want.index || errs[i].Value != want.value {
+			t.Errorf("error %d: index %v, value %q, want %d, %q", i, errs[i].Index, errs[i].Value, want.index, want.value)
+		}
+	}
+
+	errs = qv.ValidateValues(url.Values{"ids": {"1,2,3"}}, map[string]string{"ids": "array:integer AND maxitems:2"})
+	if len(errs) != 1 || errs[0].Index != nil || errs[0].Message != "list must have at most 2 items" {
+		t.Errorf("item count error = %+v", errs)
+	}
+}
+
+func TestParseArrayRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want arrayRule
+		ok   bool
+	}{
+		{"array:number|maxitems:50", arrayRule{elem: "number", minItems: -1, maxItems: 50}, true},
+		{"required AND minitems:1 AND array:integer AND min:0", arrayRule{elem: "integer AND min:0", minItems: 1, maxItems: -1}, true},
+		{"number", arrayRule{}, false},
+		{"array:uuid OR email", arrayRule{}, false},
+	}
+	for _, tt := range tests {
+		if got, ok := parseArrayRule(tt.rule); ok != tt.ok || got != tt.want {
+			t.Errorf("parseArrayRule(%q) = %+v, %v, want %+v, %v", tt.rule, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"net/url"
+	"runtime"
+	"sync"
+)
+
+// BatchResult is the outcome for one item of a batch.
+type BatchResult struct {
+	Index  int                    `json:"index"`
+	Errors []QueryValidationError `json:"errors,omitempty"`
+}
+
+// BatchReport aggregates a batch run. Results follow the input order.
+type BatchReport struct {
+	Results []BatchResult `json:"results"`
+	// Failed counts the items with at least one error.
+	Failed int `json:"failed"`
+	// ErrorsByParam counts errors per parameter across the batch.
+	ErrorsByParam map[string]int `json:"errorsByParam"`
+}
+
+// ValidateBatch validates many stored queries against rules, spreading the
+// work over GOMAXPROCS workers. It is meant for offline pipelines, and
+// behaves like ValidateValues for each item.
+func (qv *QueryValidator) ValidateBatch(batch []url.Values, rules map[string]string) BatchReport {
+	report := BatchReport{
+		Results:       make([]BatchResult, len(batch)),
+		ErrorsByParam: make(map[string]int),
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	workers := min(runtime.GOMAXPROCS(0), len(batch))
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// Each worker writes only its own slot, so no locking is needed.
+				report.Results[i] = BatchResult{
+					Index:  i,
+					Errors: qv.ValidateValues(batch[i], rules),
+				}
+			}
+		}()
+	}
+	for i := range batch {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range report.Results {
+		if len(result.Errors) > 0 {
+			report.Failed++
+		}
+		for _, err := range result.Errors {
+			report.ErrorsByParam[err.Parameter]++
+		}
+	}
+	return report
+}
+
+// flattenValues keeps the last value of repeated keys, as Fiber's Queries
+// does, and joins those of the array parameters of rules.
+func flattenValues(values url.Values, rules map[string]string) map[string]string {
+	flat := make(map[string]string, len(values))
+	for name, vs := range values {
+		if len(vs) > 0 {
+			flat[name] = vs[len(vs)-1]
+		}
+	}
+	joinArrayValues(flat, func(param string) []string { return values[param] }, rules)
+	return flat
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestValidateBatch(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"age": "number", "q": "required", "ids": "array:integer"}
+	batch := []url.Values{
+		{"age": {"30"}, "q": {"a"}},
+		{"age": {"old"}, "q": {"a"}},
+		{"age": {"1"}},
+		{"q": {"a"}, "ids": {"1", "x"}},
+		{"q": {"a"}, "nope": {"1"}, "age": {"x", "2"}},
+	}
+	report := qv.ValidateBatch(batch, rules)
+
+	want := [][]string{
+		nil,
+		{"age:INVALID_TYPE"},
+		{"q:MISSING_REQUIRED"},
+		{"ids:INVALID_TYPE"},
+		{"nope:UNEXPECTED_PARAM"},
+	}
+	if len(report.Results) != len(batch) {
+		t.Fatalf("%d results for %d items", len(report.Results), len(batch))
+	}
+	for i, result := range report.Results {
+		if result.Index != i {
+			t.Errorf("result %d has index %d", i, result.Index)
+		}
+		if got := errorCodes(result.Errors); !slices.Equal(got, want[i]) {
+			t.Errorf("item %d: errors %v, want %v", i, got, want[i])
+		}
+	}
+	if report.Failed != 4 {
+		t.Errorf("failed %d, want 4", report.Failed)
+	}
+	if byParam := map[string]int{"age": 1, "q": 1, "ids": 1, "nope": 1}; !maps.Equal(report.ErrorsByParam, byParam) {
+		t.Errorf("errors by param %v, want %v", report.ErrorsByParam, byParam)
+	}
+}
+
+func TestValidateBatchMatchesValidateValues(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"n": "integer AND min:0 AND max:500"}
+	batch := make([]url.Values, 1000)
+	for i := range batch {
+		batch[i] = url.Values{"n": {fmt.Sprint(i)}}
+	}
+	report := qv.ValidateBatch(batch, rules)
+	for i, result := range report.Results {
+		if got, want := errorCodes(result.Errors), errorCodes(qv.ValidateValues(batch[i], rules)); !slices.Equal(got, want) {
+			t.Errorf("item %d: batch errors %v, ValidateValues errors %v", i, got, want)
+		}
+	}
+	if report.Failed != 499 {
+		t.Errorf("failed %d, want 499", report.Failed)
+	}
+	if empty := qv.ValidateBatch(nil, rules); len(empty.Results) != 0 || empty.Failed != 0 {
+		t.Errorf("empty batch report %+v", empty)
+	}
+}
@@ -0,0 +1,275 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/binder"
+)
+
+// BinderName is the name the binder returned by Binder registers under, for
+// c.Bind().Custom(BinderName, &dst).
+const BinderName = "validated-query"
+
+// ValidationErrors is returned by the binding integrations when a query
+// fails validation. Callers can errors.As it to render the individual errors.
+type ValidationErrors []QueryValidationError
+
+func (e ValidationErrors) Error() string {
+	return ErrorSummary(e)
+}
+
+// Binder returns a Fiber custom binder that validates the whole query, with
+// defaults, scopes and unknown-parameter checks, before binding it into the
+// destination. Rules come from RouteRules for the matched route, falling
+// back to the destination's rule tags:
+//
+//	app.RegisterCustomBinder(qv.Binder())
+//	err := c.Bind().Custom(validator.BinderName, &q)
+func (qv *QueryValidator) Binder() fiber.CustomBinder {
+	return queryBinder{qv: qv}
+}
+
+type queryBinder struct{ qv *QueryValidator }
+
+func (b queryBinder) Name() string { return BinderName }
+
+func (b queryBinder) MIMETypes() []string { return nil }
+
+func (b queryBinder) Parse(c fiber.Ctx, out any) error {
+	rules, ok := b.qv.RouteRules(c.Route().Path)
+	if !ok {
+		rules = tagRules(out)
+	}
+	return b.qv.bindQuery(c, out, rules)
+}
+
+// BindAndValidate validates the query against the rules in out's tags and
+// then binds it into out, converting values to the field types:
+//
+//	type ListUsersQuery struct {
+//		Age    int    `query:"age" validate:"min=18"`
+//		Status string `query:"status" validate:"required,oneof=active inactive"`
+//	}
+//	var q ListUsersQuery
+//	if err := qv.BindAndValidate(c, &q); err != nil { ... }
+//
+// Every field with a query tag declares its parameter. Fields may take a
+// rule tag with this package's rule syntax or a validate tag with
+// comma-separated required, min, max, len, oneof and ne terms; min, max and
+// len bound the value of numeric fields and the length of others. Without
+// a rule tag the value must also fit the field's type; slice fields become
+// array parameters whose terms apply to each element. A failed check
+// returns ValidationErrors.
+func (qv *QueryValidator) BindAndValidate(c fiber.Ctx, out any) error {
+	if structType(out) == nil {
+		return fmt.Errorf("bind target must be a struct pointer, got %T", out)
+	}
+	return qv.bindQuery(c, out, tagRules(out))
+}
+
+func (qv *QueryValidator) bindQuery(c fiber.Ctx, out any, rules map[string]string) error {
+	if errors := qv.ValidateQuery(c, rules); len(errors) > 0 {
+		return ValidationErrors(errors)
+	}
+	return binder.QueryBinder.Bind(c.Context(), out)
+}
+
+// StructValidator returns a validator for fiber.Config.StructValidator, so
+// c.Bind().Query(&dst) also runs the rules in dst's rule tags:
+//
+//	type ListUsersQuery struct {
+//		Status string `query:"status" rule:"not_in:deleted"`
+//	}
+//
+// It sees only what Fiber bound, so zero-valued fields count as absent and
+// unknown parameters go unnoticed; use Binder for the full checks.
+func (qv *QueryValidator) StructValidator() fiber.StructValidator {
+	return structValidator{qv: qv}
+}
+
+type structValidator struct{ qv *QueryValidator }
+
+func (v structValidator) Validate(out any) error {
+	rules := tagRules(out)
+	if len(rules) == 0 {
+		return nil
+	}
+	v.qv.mu.RLock()
+	defer v.qv.mu.RUnlock()
+	var errors []QueryValidationError
+	for param, values := range fieldValues(out) {
+		for _, value := range values {
+			if reason, ok := v.qv.checkParamValue(context.Background(), value, rules[param]); !ok {
+				errors = append(errors, newValidationError(param, value, reason))
+			}
+		}
+	}
+	if len(errors) > 0 {
+		v.qv.finishErrors(errors)
+		return ValidationErrors(errors)
+	}
+	return nil
+}
+
+// tagRules reads the rules of out's fields that carry a query tag. Fields
+// without a rule or validate tag get the type term of their Go type.
+func tagRules(out any) map[string]string {
+	rules := make(map[string]string)
+	t := structType(out)
+	if t == nil {
+		return rules
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := field.Tag.Get("query"); queryField(field, name) {
+			rules[name] = fieldRule(field)
+		}
+	}
+	return rules
+}
+
+// queryField reports whether field binds the query parameter name.
+func queryField(field reflect.StructField, name string) bool {
+	return name != "" && name != "-" && field.IsExported()
+}
+
+// fieldRule is the rule of field's rule tag, or else its validate tag
+// translated into a rule, which checks that values fit the field's type.
+func fieldRule(field reflect.StructField) string {
+	if rule := field.Tag.Get("rule"); rule != "" {
+		return rule
+	}
+	tag := field.Tag.Get("validate")
+
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	slice := t.Kind() == reflect.Slice
+	if slice {
+		t = t.Elem()
+	}
+	numeric := false
+	var terms []string
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		terms, numeric = append(terms, "int:"+strconv.Itoa(t.Bits())), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		terms, numeric = append(terms, "uint:"+strconv.Itoa(t.Bits())), true
+	case reflect.Float32, reflect.Float64:
+		terms, numeric = append(terms, "float:"+strconv.Itoa(t.Bits())), true
+	case reflect.Bool:
+		terms = append(terms, "boolean")
+	}
+
+	for _, term := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(term), "=")
+		switch name {
+		case "":
+		case "required":
+			terms = append(terms, "required")
+		case "min", "max":
+			if !numeric {
+				name += "len"
+			}
+			terms = append(terms, name+":"+arg)
+		case "len":
+			terms = append(terms, "len:"+arg)
+		case "oneof":
+			terms = append(terms, "in:"+strings.Join(strings.Fields(arg), ","))
+		case "ne":
+			terms = append(terms, "not_in:"+arg)
+		default:
+			// Unknown terms become rules that fail as INVALID_RULE rather
+			// than being dropped silently.
+			terms = append(terms, name+":"+arg)
+		}
+	}
+	if slice {
+		// Slices validate per element, so the type term moves into the
+		// array term.
+		elem := "string"
+		if numeric || t.Kind() == reflect.Bool {
+			elem, terms = terms[0], terms[1:]
+		}
+		terms = append([]string{"array:" + elem}, terms...)
+	}
+	if len(terms) == 0 {
+		return "string"
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// fieldValues formats the non-zero query-tagged fields of out back into query
+// values. Slices yield one value per element.
+func fieldValues(out any) map[string][]string {
+	values := make(map[string][]string)
+	t := structType(out)
+	if t == nil {
+		return values
+	}
+	v := reflect.ValueOf(out)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("query")
+		if !queryField(field, name) {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.IsZero() {
+			continue
+		}
+		if fv.Kind() == reflect.Slice {
+			for j := 0; j < fv.Len(); j++ {
+				if s, ok := formatField(fv.Index(j)); ok {
+					values[name] = append(values[name], s)
+				}
+			}
+			continue
+		}
+		if s, ok := formatField(fv); ok {
+			values[name] = append(values[name], s)
+		}
+	}
+	return values
+}
+
+func formatField(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), true
+	}
+	return "", false
+}
+
+func structType(out any) reflect.Type {
+	t := reflect.TypeOf(out)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
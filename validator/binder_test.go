@@ -0,0 +1,175 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"errors"
+	"maps"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type listQuery struct {
+	Page     int      `query:"page"`
+	Size     uint8    `query:"size" validate:"max=50"`
+	Ratio    float64  `query:"ratio"`
+	Archived bool     `query:"archived"`
+	Status   string   `query:"status" validate:"required,oneof=active inactive"`
+	Name     string   `query:"name" validate:"min=2"`
+	Search   string   `query:"q" rule:"string AND maxlen:20"`
+	IDs      []int64  `query:"ids" validate:"max=9"`
+	Tags     []string `query:"tags"`
+	Skipped  string   `query:"-"`
+	internal string   `query:"internal"`
+	Untagged string
+}
+
+func TestTagRules(t *testing.T) {
+	want := map[string]string{
+		"page":     "int:64",
+		"size":     "uint:8 AND max:50",
+		"ratio":    "float:64",
+		"archived": "boolean",
+		"status":   "required AND in:active,inactive",
+		"name":     "minlen:2",
+		"q":        "string AND maxlen:20",
+		"ids":      "array:int:64 AND max:9",
+		"tags":     "array:string",
+	}
+	if got := tagRules(&listQuery{}); !maps.Equal(got, want) {
+		t.Errorf("tagRules = %v, want %v", got, want)
+	}
+}
+
+func TestBindAndValidate(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+		page  int
+	}{
+		{query: "status=active&page=2", page: 2},
+		{query: "status=active&page=x", want: []string{"page:CONSTRAINT_FAILED"}},
+		{query: "status=active&size=51", want: []string{"size:TOO_LARGE"}},
+		{query: "page=1", want: []string{"status:MISSING_REQUIRED"}},
+		{query: "status=active&other=1", want: []string{"other:UNEXPECTED_PARAM"}},
+		{query: "status=active&ids=1&ids=10", want: []string{"ids:TOO_LARGE"}},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		var q listQuery
+		var bindErr error
+		serveQuery(t, "/", "/?"+tt.query, func(c fiber.Ctx) []QueryValidationError {
+			bindErr = qv.BindAndValidate(c, &q)
+			return nil
+		})
+		var verrs ValidationErrors
+		errors.As(bindErr, &verrs)
+		if got := errorCodes(verrs); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: errors %v (%v), want %v", tt.query, got, bindErr, tt.want)
+			continue
+		}
+		if bindErr == nil && q.Page != tt.page {
+			t.Errorf("%s: Page = %d, want %d", tt.query, q.Page, tt.page)
+		}
+	}
+}
+
+func TestStructValidator(t *testing.T) {
+	qv := NewQueryValidator()
+	err := qv.StructValidator().Validate(&listQuery{Status: "gone", Name: "x"})
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("Validate = %v, want ValidationErrors", err)
+	}
+	want := []string{"name:TOO_SHORT", "status:NOT_ALLOWED"}
+	if got := errorCodes(verrs); !slices.Equal(got, want) {
+		t.Errorf("errors = %v, want %v", got, want)
+	}
+	if err := qv.StructValidator().Validate(&listQuery{Status: "active"}); err != nil {
+		t.Errorf("Validate of a valid struct = %v", err)
+	}
+}
+
+// bindOnce serves target from app, whose /items handler binds with bind,
+// and returns the binding error.
+func bindOnce(t *testing.T, app *fiber.App, target string, bind func(c fiber.Ctx) error) error {
+	t.Helper()
+	var bindErr error
+	app.Get("/items", func(c fiber.Ctx) error {
+		bindErr = bind(c)
+		return nil
+	})
+	resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return bindErr
+}
+
+func TestCustomBinder(t *testing.T) {
+	type itemsQuery struct {
+		Status string `query:"status" rule:"in:active,inactive"`
+		Limit  int    `query:"limit"`
+	}
+	tests := []struct {
+		name       string
+		registered map[string]string
+		query      string
+		want       []string
+		limit      int
+	}{
+		{"tag rules", nil, "status=active&limit=5", nil, 5},
+		{"tag rules reject", nil, "status=gone", []string{"status:NOT_ALLOWED"}, 0},
+		{"unknown parameter", nil, "status=active&x=1", []string{"x:UNEXPECTED_PARAM"}, 0},
+		{"registered rules win", map[string]string{"status": "string", "limit": "integer AND max:10"},
+			"status=gone&limit=50", []string{"limit:TOO_LARGE"}, 0},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		if tt.registered != nil {
+			qv.RegisterRoute("/items", tt.registered)
+		}
+		app := fiber.New()
+		app.RegisterCustomBinder(qv.Binder())
+		var q itemsQuery
+		err := bindOnce(t, app, "/items?"+tt.query, func(c fiber.Ctx) error {
+			return c.Bind().Custom(BinderName, &q)
+		})
+		var verrs ValidationErrors
+		errors.As(err, &verrs)
+		if got := errorCodes(verrs); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: errors %v (%v), want %v", tt.name, got, err, tt.want)
+		}
+		if err == nil && q.Limit != tt.limit {
+			t.Errorf("%s: Limit = %d, want %d", tt.name, q.Limit, tt.limit)
+		}
+	}
+}
+
+func TestStructValidatorConfig(t *testing.T) {
+	type itemsQuery struct {
+		Status string `query:"status" rule:"not_in:deleted"`
+	}
+	qv := NewQueryValidator()
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"status=active", nil},
+		{"status=deleted", []string{"status:FORBIDDEN_VALUE"}},
+		{"other=1", nil},
+	} {
+		app := fiber.New(fiber.Config{StructValidator: qv.StructValidator()})
+		var q itemsQuery
+		err := bindOnce(t, app, "/items?"+tt.query, func(c fiber.Ctx) error { return c.Bind().Query(&q) })
+		var verrs ValidationErrors
+		errors.As(err, &verrs)
+		if got := errorCodes(verrs); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: errors %v (%v), want %v", tt.query, got, err, tt.want)
+		}
+	}
+}
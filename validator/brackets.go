@@ -0,0 +1,149 @@
+package validator
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// bracketWildcard, as a segment of a bracketed rule key, matches any
+// segment, as in "filter[*]" for filter[status] and filter[owner].
+const bracketWildcard = "*"
+
+// splitBrackets splits a bracketed name such as filter[age][gte] into its
+// base and segments. ok is false for names without brackets or with
+// malformed ones.
+func splitBrackets(name string) (base string, segments []string, ok bool) {
+	base, rest, found := strings.Cut(name, "[")
+	if !found || base == "" {
+		return "", nil, false
+	}
+	rest = "[" + rest
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return "", nil, false
+		}
+		segment := rest[1:end]
+		if strings.ContainsAny(segment, "[") {
+			return "", nil, false
+		}
+		segments = append(segments, segment)
+		rest = rest[end+1:]
+	}
+	return base, segments, true
+}
+
+// bracketsMatch reports whether param matches the bracketed rule key
+// pattern, whose wildcard segments match any segment.
+func bracketsMatch(pattern, param string) bool {
+	patternBase, patternSegments, ok := splitBrackets(pattern)
+	if !ok {
+		return false
+	}
+	base, segments, ok := splitBrackets(param)
+	if !ok || base != patternBase || len(segments) != len(patternSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if segment != bracketWildcard && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchBracketedName checks a bracketed name against a name pattern: its
+// base and each segment must match, except for empty segments, as in
+// ids[], and numeric ones, as in sort[0]. Other names must match whole.
+func matchBracketedName(pattern Regexp, name string) bool {
+	base, segments, ok := splitBrackets(name)
+	if !ok {
+		return pattern.MatchString(name)
+	}
+	if !pattern.MatchString(base) {
+		return false
+	}
+	for _, segment := range segments {
+		if segment != "" && !allDigits(segment) && !pattern.MatchString(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// NestedValues expands bracketed parameter names into nested maps, as PHP
+// and Rails do: filter[age][gte]=18 becomes
+// {"filter": {"age": {"gte": "18"}}}. Levels whose segments are all numeric
+// or empty, as in sort[0] or ids[], become slices ordered by index; empty
+// segments of several values come in key order. When a name is sent both
+// bare and with brackets, as in filter=x&filter[a]=y, the nested form wins.
+func NestedValues(values map[string]string) map[string]any {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	root := make(map[string]any, len(values))
+	for _, name := range names {
+		base, segments, ok := splitBrackets(name)
+		if !ok {
+			if _, nested := root[name].(map[string]any); !nested {
+				root[name] = values[name]
+			}
+			continue
+		}
+		level := root
+		path := append([]string{base}, segments...)
+		for i, segment := range path {
+			if segment == "" {
+				segment = strconv.Itoa(len(level))
+			}
+			if i == len(path)-1 {
+				if _, nested := level[segment].(map[string]any); !nested {
+					level[segment] = values[name]
+				}
+				break
+			}
+			next, ok := level[segment].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				level[segment] = next
+			}
+			level = next
+		}
+	}
+	for name, value := range root {
+		root[name] = listify(value)
+	}
+	return root
+}
+
+// listify turns the maps of nested whose keys are all indexes into slices.
+func listify(nested any) any {
+	level, ok := nested.(map[string]any)
+	if !ok {
+		return nested
+	}
+	type entry struct {
+		index int
+		value any
+	}
+	entries := make([]entry, 0, len(level))
+	for key, value := range level {
+		level[key] = listify(value)
+		if n, err := strconv.Atoi(key); err == nil && n >= 0 {
+			entries = append(entries, entry{n, level[key]})
+		}
+	}
+	if len(entries) == 0 || len(entries) != len(level) {
+		return level
+	}
+	slices.SortFunc(entries, func(a, b entry) int { return a.index - b.index })
+	list := make([]any, len(entries))
+	for i, e := range entries {
+		list[i] = e.value
+	}
+	return list
+}
@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSplitBrackets(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		segments []string
+		ok       bool
+	}{
+		{"filter[status]", "filter", []string{"status"}, true},
+		{"filter[age][gte]", "filter", []string{"age", "gte"}, true},
+		{"ids[]", "ids", []string{""}, true},
+		{"plain", "", nil, false},
+		{"[status]", "", nil, false},
+		{"filter[status", "", nil, false},
+		{"filter[a[b]]", "", nil, false},
+		{"filter[a]x", "", nil, false},
+	}
+	for _, tt := range tests {
+		base, segments, ok := splitBrackets(tt.name)
+		if base != tt.base || !slices.Equal(segments, tt.segments) || ok != tt.ok {
+			t.Errorf("splitBrackets(%q) = %q, %q, %v, want %q, %q, %v", tt.name, base, segments, ok, tt.base, tt.segments, tt.ok)
+		}
+	}
+}
+
+func TestBracketRules(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"filter[*]":        "string AND maxlen:5",
+		"filter[age][gte]": "integer",
+		"filter[age][*]":   "integer AND min:0",
+		"filter[owner]":    "in:me,team",
+		"sort[*]":          "in:name,age",
+	}
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []string
+	}{
+		{"valid", map[string]string{"filter[status]": "open", "filter[age][gte]": "18", "sort[0]": "age"}, nil},
+		{"wildcard rule", map[string]string{"filter[status]": "archived"}, []string{"filter[status]:TOO_LONG"}},
+		{"exact key wins", map[string]string{"filter[owner]": "all"}, []string{"filter[owner]:NOT_ALLOWED"}},
+		{"exact nested key", map[string]string{"filter[age][gte]": "x"}, []string{"filter[age][gte]:INVALID_TYPE"}},
+		{"nested wildcard", map[string]string{"filter[age][lte]": "-1"}, []string{"filter[age][lte]:TOO_SMALL"}},
+		{"depth must match", map[string]string{"filter[a][b][c]": "1"}, []string{"filter[a][b][c]:UNEXPECTED_PARAM"}},
+		{"other base", map[string]string{"find[status]": "open"}, []string{"find[status]:UNEXPECTED_PARAM"}},
+		{"bad segment name", map[string]string{"find[st-atus]": "open"}, []string{"find[st-atus]:INVALID_NAME"}},
+		// Names a wildcard key matched are checked only by its name_pattern.
+		{"segment named by wildcard", map[string]string{"filter[st-atus]": "open"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkCodes(t, qv, tt.values, rules, tt.want...)
+		})
+	}
+}
+
+func TestNestedValues(t *testing.T) {
+	values := map[string]string{
+		"filter[age][gte]": "18",
+		"filter[age][lte]": "65",
+		"filter[status]":   "open",
+		"sort[1]":          "age",
+		"sort[0]":          "name",
+		"ids[]":            "7",
+		"mixed[0]":         "a",
+		"mixed[x]":         "b",
+		"q":                "x",
+		"both":             "bare",
+		"both[a]":          "nested",
+	}
+	want := map[string]any{
+		"filter": map[string]any{
+			"age":    map[string]any{"gte": "18", "lte": "65"},
+			"status": "open",
+		},
+		"sort":  []any{"name", "age"},
+		"ids":   []any{"7"},
+		"mixed": map[string]any{"0": "a", "x": "b"},
+		"q":     "x",
+		"both":  map[string]any{"a": "nested"},
+	}
+	if got := NestedValues(values); !reflect.DeepEqual(got, want) {
+		t.Errorf("NestedValues = %v, want %v", got, want)
+	}
+}
+
+func TestValidationResultNested(t *testing.T) {
+	qv := NewQueryValidator()
+	result := qv.ValidateValuesResult(url.Values{"filter[status]": {"open"}, "filter[age]": {"x"}},
+		map[string]string{"filter[status]": "string", "filter[age]": "integer"})
+	want := map[string]any{"filter": map[string]any{"status": "open"}}
+	if got := result.Nested(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Nested = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"context"
+	"time"
+)
+
+// ContextValidator is a type validator that may block, e.g. on a remote
+// lookup, and should give up when ctx is done.
+type ContextValidator func(ctx context.Context, value string) (bool, error)
+
+type budgetedValidator struct {
+	fn     ContextValidator
+	budget time.Duration
+}
+
+// AddContextValidator registers a type whose validator runs under the
+// request's context, given a deadline of budget. A validator that overruns
+// its budget fails the value with a VALIDATOR_TIMEOUT error, even if it
+// ignores its context; an error return fails it as an invalid value.
+func (qv *QueryValidator) AddContextValidator(name string, budget time.Duration, validator ContextValidator) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.contextValidators[name] = budgetedValidator{fn: validator, budget: budget}
+}
+
+// typeOutcome is the result of running a type validator.
+type typeOutcome int
+
+const (
+	typeValid typeOutcome = iota
+	typeInvalid
+	typeTimedOut
+	typePanicked
+)
+
+// runType runs the validator of the named type on value, consulting its
+// memo when the type is memoized. Parsing rejects rules naming unknown
+// types, so only required gets here unregistered, and accepts every value.
+func (qv *QueryValidator) runType(ctx context.Context, name, value string) typeOutcome {
+	memo := qv.memos[name]
+	if memo != nil {
+		if outcome, ok := memo.get(value); ok {
+			return outcome
+		}
+	}
+	outcome := qv.evalType(ctx, name, value)
+	if memo != nil && (outcome == typeValid || outcome == typeInvalid) {
+		memo.put(value, outcome)
+	}
+	return outcome
+}
+
+func (qv *QueryValidator) evalType(ctx context.Context, name, value string) typeOutcome {
+	bv, ok := qv.contextValidators[name]
+	if !ok {
+		validator, exists := qv.typeValidators[name]
+		if !exists {
+			if name == requiredType {
+				return typeValid
+			}
+			return typeInvalid
+		}
+		valid, panicked := qv.safeCheck(name, validator, value)
+		switch {
+		case panicked:
+			return typePanicked
+		case valid:
+			return typeValid
+		}
+		return typeInvalid
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, bv.budget)
+	defer cancel()
+
+	type result struct {
+		valid    bool
+		err      error
+		panicked bool
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				qv.notifyPanic(name, r)
+				done <- result{panicked: true}
+			}
+		}()
+		valid, err := bv.fn(ctx, value)
+		done <- result{valid: valid, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		switch {
+		case r.panicked:
+			return typePanicked
+		case r.err != nil && ctx.Err() != nil:
+			return typeTimedOut
+		case r.err != nil || !r.valid:
+			return typeInvalid
+		}
+		return typeValid
+	case <-ctx.Done():
+		return typeTimedOut
+	}
+}
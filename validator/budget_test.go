@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestContextValidatorBudget(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddContextValidator("remote", 20*time.Millisecond, func(ctx context.Context, v string) (bool, error) {
+		switch v {
+		case "slow":
+			<-ctx.Done()
+			return false, ctx.Err()
+		case "stuck":
+			// Ignores its context.
+			time.Sleep(200 * time.Millisecond)
+			return true, nil
+		case "broken":
+			return false, errors.New("lookup failed")
+		}
+		return v == "ok", nil
+	})
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"ok", nil},
+		{"bad", []string{"p:INVALID_TYPE"}},
+		{"broken", []string{"p:INVALID_TYPE"}},
+		{"slow", []string{"p:VALIDATOR_TIMEOUT"}},
+		{"stuck", []string{"p:VALIDATOR_TIMEOUT"}},
+	}
+	for _, tt := range tests {
+		start := time.Now()
+		checkCodes(t, qv, map[string]string{"p": tt.value}, map[string]string{"p": "remote"}, tt.want...)
+		if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+			t.Errorf("%s: validation took %v, past the budget", tt.value, elapsed)
+		}
+	}
+	if got := errorMessage(t, qv, "p", "slow", "remote"); got != "validation of remote timed out" {
+		t.Errorf("message %q", got)
+	}
+}
+
+func TestContextValidatorDeadline(t *testing.T) {
+	qv := NewQueryValidator()
+	var deadline time.Duration
+	qv.AddContextValidator("remote", time.Second, func(ctx context.Context, v string) (bool, error) {
+		d, ok := ctx.Deadline()
+		if !ok {
+			return false, errors.New("no deadline")
+		}
+		deadline = time.Until(d)
+		return true, nil
+	})
+	checkCodes(t, qv, map[string]string{"p": "x"}, map[string]string{"p": "remote"})
+	if deadline <= 0 || deadline > time.Second {
+		t.Errorf("validator got %v to run, want at most its 1s budget", deadline)
+	}
+}
@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Normalizer rewrites a valid value of a type into its canonical spelling.
+type Normalizer func(value string) string
+
+// AddNormalizer registers how values of typeName are canonicalized by
+// CanonicalQuery. Types without a normalizer keep their values as sent.
+func (qv *QueryValidator) AddNormalizer(typeName string, normalizer Normalizer) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.normalizers[typeName] = normalizer
+}
+
+func (qv *QueryValidator) addBuiltinNormalizers() {
+	qv.normalizers["number"] = canonicalNumber
+	qv.normalizers["boolean"] = func(v string) string {
+		if v == "1" || strings.EqualFold(v, "true") {
+			return "true"
+		}
+		return "false"
+	}
+	qv.normalizers[flagType] = func(v string) string {
+		on, _ := parseFlag(v)
+		return strconv.FormatBool(on)
+	}
+	qv.normalizers["hostname"] = func(v string) string {
+		if host, err := CanonicalHostname(v, IDNAcceptBoth); err == nil {
+			return strings.ToLower(host)
+		}
+		return v
+	}
+}
+
+// CanonicalMap is CanonicalQuery for values validated outside of an HTTP
+// request, as with ValidateMap.
+func (qv *QueryValidator) CanonicalMap(values map[string]string, rules map[string]string) (string, []QueryValidationError) {
+	if errors := qv.ValidateMap(values, rules); len(errors) > 0 {
+		return "", errors
+	}
+	return qv.canonicalize(values, rules), nil
+}
+
+// CacheKeyMap is CacheKey for values validated outside of an HTTP request.
+func (qv *QueryValidator) CacheKeyMap(values map[string]string, rules map[string]string) (string, []QueryValidationError) {
+	if errors := qv.ValidateMap(values, rules); len(errors) > 0 {
+		return "", errors
+	}
+	return cacheKey(qv.canonicalize(declaredValues(values, rules), rules)), nil
+}
+
+func cacheKey(canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(sum[:])
+}
+
+// declaredValues drops values rules does not declare, such as defaults for
+// parameters of other routes.
+func declaredValues(values map[string]string, rules map[string]string) map[string]string {
+	declared := make(map[string]string, len(rules))
+	for param, value := range values {
+		if _, ok := rules[param]; ok {
+			declared[param] = value
+		}
+	}
+	return declared
+}
+
+func (qv *QueryValidator) canonicalize(values map[string]string, rules map[string]string) string {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	canonical := make(url.Values, len(values))
+	for param, value := range values {
+		// Only a plain type says how to spell a value; combined rules keep it.
+		if expr, err := qv.parseTypeExpr(rules[param]); err == nil {
+			if t, ok := expr.(typeRef); ok {
+				if normalize, ok := qv.normalizers[string(t)]; ok {
+					value = normalize(value)
+				}
+			}
+		}
+		canonical.Set(param, value)
+	}
+	// Encode sorts by key.
+	return canonical.Encode()
+}
+
+// canonicalNumber drops leading zeros, trailing fraction zeros and the sign
+// of zero without going through float64, so large values keep every digit.
+func canonicalNumber(v string) string {
+	negative := strings.HasPrefix(v, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(v, "-"), ".")
+	whole = strings.TrimLeft(whole, "0")
+	if whole == "" {
+		whole = "0"
+	}
+	fraction = strings.TrimRight(fraction, "0")
+
+	n := whole
+	if fraction != "" {
+		n += "." + fraction
+	}
+	if negative && n != "0" {
+		n = "-" + n
+	}
+	return n
+}
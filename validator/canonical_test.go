@@ -0,0 +1,106 @@
+package validator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalNumber(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"42", "42"},
+		{"007", "7"},
+		{"1.500", "1.5"},
+		{"1.0", "1"},
+		{"-0", "0"},
+		{"-0.0", "0"},
+		{"-01.20", "-1.2"},
+		{".5", "0.5"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+	}
+	for _, tt := range tests {
+		if got := canonicalNumber(tt.in); got != tt.want {
+			t.Errorf("canonicalNumber(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalMap(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDefault("sort", StaticDefault("name"))
+	qv.AddNormalizer("upper", strings.ToUpper)
+	qv.AddTypeValidator("upper", func(string) bool { return true })
+	rules := map[string]string{
+		"limit":   "number",
+		"active":  "boolean",
+		"verbose": "flag",
+		"host":    "hostname",
+		"code":    "upper",
+		"sort":    "string",
+		"q":       "string AND maxlen:20",
+		"page":    "number AND min:1",
+	}
+	tests := []struct {
+		values map[string]string
+		want   string
+	}{
+		{map[string]string{"limit": "010", "active": "1"}, "active=true&limit=10&sort=name"},
+		{map[string]string{"active": "TRUE", "verbose": ""}, "active=true&sort=name&verbose=true"},
+		{map[string]string{"host": "Example.COM", "code": "ab"}, "code=AB&host=example.com&sort=name"},
+		{map[string]string{"q": "a b", "sort": "date"}, "q=a+b&sort=date"},
+		// Combined rules keep values as sent.
+		{map[string]string{"page": "02"}, "page=02&sort=name"},
+	}
+	for _, tt := range tests {
+		got, errs := qv.CanonicalMap(tt.values, rules)
+		if len(errs) > 0 || got != tt.want {
+			t.Errorf("CanonicalMap(%v) = %q, %v, want %q", tt.values, got, errorCodes(errs), tt.want)
+		}
+	}
+
+	a, _ := qv.CanonicalMap(map[string]string{"limit": "1.50", "active": "0"}, rules)
+	b, _ := qv.CanonicalMap(map[string]string{"active": "false", "limit": "1.5"}, rules)
+	if a != b {
+		t.Errorf("equivalent queries canonicalize differently: %q and %q", a, b)
+	}
+
+	got, errs := qv.CanonicalMap(map[string]string{"limit": "x"}, rules)
+	if got != "" || !slices.Equal(errorCodes(errs), []string{"limit:INVALID_TYPE"}) {
+		t.Errorf("invalid query: %q, %v", got, errorCodes(errs))
+	}
+}
+
+func TestCacheKeyMap(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDefault("region", StaticDefault("eu"))
+	rules := map[string]string{"limit": "number", "active": "boolean", "region": "in:eu,us"}
+	key := func(values map[string]string) string {
+		t.Helper()
+		k, errs := qv.CacheKeyMap(values, rules)
+		if len(errs) > 0 {
+			t.Fatalf("CacheKeyMap(%v): %v", values, errorCodes(errs))
+		}
+		if len(k) != 64 {
+			t.Fatalf("key %q is not a hex SHA-256", k)
+		}
+		return k
+	}
+
+	base := key(map[string]string{"limit": "10", "active": "true"})
+	if got := key(map[string]string{"active": "1", "limit": "010"}); got != base {
+		t.Error("equivalent spellings give different keys")
+	}
+	if got := key(map[string]string{"limit": "20", "active": "true"}); got == base {
+		t.Error("different values give the same key")
+	}
+	// A default and the same value sent explicitly are the same query.
+	if got := key(map[string]string{"limit": "10", "active": "true", "region": "eu"}); got != base {
+		t.Error("sending the default gives a different key")
+	}
+	if got := key(map[string]string{"limit": "10", "active": "true", "region": "us"}); got == base {
+		t.Error("a non-default region gives the default's key")
+	}
+	if _, errs := qv.CacheKeyMap(map[string]string{"limit": "ten"}, rules); len(errs) == 0 {
+		t.Error("invalid query got a cache key")
+	}
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"hash/crc32"
+	"strings"
+)
+
+// ChecksumAlgorithm selects the digest a checksum rule verifies.
+type ChecksumAlgorithm int
+
+const (
+	// ChecksumCRC32 is the IEEE CRC-32 as 8 hex digits.
+	ChecksumCRC32 ChecksumAlgorithm = iota
+	// ChecksumSHA256 is a SHA-256 digest, or a hex prefix of one.
+	ChecksumSHA256
+)
+
+// ChecksumRule returns a cross rule requiring param, when present, to be the
+// hex checksum of source's value. minPrefix is the shortest accepted prefix
+// in hex digits; zero requires the full digest.
+func ChecksumRule(param, source string, algo ChecksumAlgorithm, minPrefix int) CrossRule {
+	return func(values map[string]string) []QueryValidationError {
+		sum, ok := values[param]
+		if !ok {
+			return nil
+		}
+
+		expected := checksumHex(algo, values[source])
+		shortest := minPrefix
+		if shortest <= 0 || shortest > len(expected) {
+			shortest = len(expected)
+		}
+		got := strings.ToLower(sum)
+		if len(got) < shortest || len(got) > len(expected) ||
+			subtle.ConstantTimeCompare([]byte(got), []byte(expected[:len(got)])) != 1 {
+			return []QueryValidationError{
+				newValidationError(param, sum, fail(MsgChecksumMismatch, source)),
+			}
+		}
+		return nil
+	}
+}
+
+func checksumHex(algo ChecksumAlgorithm, value string) string {
+	switch algo {
+	case ChecksumSHA256:
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:])
+	default:
+		return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(value)))
+	}
+}
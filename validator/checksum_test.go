@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChecksumRule(t *testing.T) {
+	const sha = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	tests := []struct {
+		algo      ChecksumAlgorithm
+		minPrefix int
+		sum       string
+		ok        bool
+	}{
+		{ChecksumCRC32, 0, "3610a686", true},
+		{ChecksumCRC32, 0, "3610A686", true},
+		{ChecksumCRC32, 0, "3610a687", false},
+		{ChecksumCRC32, 0, "3610a6", false},
+		{ChecksumSHA256, 0, sha, true},
+		{ChecksumSHA256, 0, sha[:12], false},
+		{ChecksumSHA256, 12, sha[:12], true},
+		{ChecksumSHA256, 12, sha[:11], false},
+		{ChecksumSHA256, 12, "ff" + sha[2:12], false},
+		{ChecksumSHA256, 12, sha + "00", false},
+		{ChecksumSHA256, 100, sha, true},
+	}
+	for _, tt := range tests {
+		rule := ChecksumRule("sum", "body", tt.algo, tt.minPrefix)
+		errs := rule(map[string]string{"body": "hello", "sum": tt.sum})
+		if got := len(errs) == 0; got != tt.ok {
+			t.Errorf("algo %d, prefix %d, sum %q: ok = %v, want %v", tt.algo, tt.minPrefix, tt.sum, got, tt.ok)
+		}
+	}
+}
+
+func TestChecksumRuleQuery(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddCrossRule(ChecksumRule("sum", "body", ChecksumCRC32, 0))
+	rules := map[string]string{"body": "string", "sum": "string"}
+
+	checkCodes(t, qv, map[string]string{"body": "hello", "sum": "3610a686"}, rules)
+	checkCodes(t, qv, map[string]string{"body": "hello"}, rules)
+	errs := qv.ValidateMap(map[string]string{"body": "hello", "sum": "00000000"}, rules)
+	if got := errorCodes(errs); !slices.Equal(got, []string{"sum:CHECKSUM_MISMATCH"}) {
+		t.Fatalf("codes = %v", got)
+	}
+	if want := "checksum does not match body"; errs[0].Message != want {
+		t.Errorf("message = %q, want %q", errs[0].Message, want)
+	}
+}
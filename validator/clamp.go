@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// clampTerm is the rule term that clamps numbers to the rule's min and max
+// instead of rejecting them, as in "int:64 AND min:1 AND max:1000 AND clamp".
+const clampTerm = "clamp"
+
+// clampValues moves out-of-range numbers of clamping rules onto the nearest
+// bound. It returns a warning for each clamped value and the new values.
+func (qv *QueryValidator) clampValues(values, rules map[string]string) ([]QueryValidationError, map[string]string) {
+	var warnings []QueryValidationError
+	var clamped map[string]string
+	for param, value := range values {
+		rule, ok := rules[param]
+		if !ok || !strings.Contains(rule, clampTerm) {
+			continue
+		}
+		bound, ok := qv.clampBound(rule, value)
+		if !ok {
+			continue
+		}
+		// Adapters write changed values back into buffers value may
+		// alias, so warnings keep their own copy of what was sent.
+		warning := newValidationError(param, strings.Clone(value), fail(MsgClamped, bound))
+		warning.Severity = SeverityWarning
+		warnings = append(warnings, warning)
+		if clamped == nil {
+			clamped = make(map[string]string)
+		}
+		values[param], clamped[param] = bound, bound
+	}
+	return warnings, clamped
+}
+
+// clampBound returns the bound value lies beyond, when rule clamps. Values
+// failing the rest of the rule, such as 1e9 for an int:64 rule, are left to
+// fail validation rather than be clamped into range.
+func (qv *QueryValidator) clampBound(rule, value string) (string, bool) {
+	clamps := false
+	var lower, upper string
+	var others []string
+	for _, term := range ruleTerms(rule) {
+		name, arg, _ := strings.Cut(term, ":")
+		switch name {
+		case clampTerm:
+			clamps = true
+		case "min":
+			lower = arg
+		case "max":
+			upper = arg
+		default:
+			others = append(others, term)
+		}
+	}
+	if !clamps {
+		return "", false
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return "", false
+	}
+	if len(others) > 0 {
+		if _, ok := qv.checkParamValue(context.Background(), value, strings.Join(others, " AND ")); !ok {
+			return "", false
+		}
+	}
+	if lo, err := strconv.ParseFloat(lower, 64); err == nil && n < lo {
+		return lower, true
+	}
+	if hi, err := strconv.ParseFloat(upper, 64); err == nil && n > hi {
+		return upper, true
+	}
+	return "", false
+}
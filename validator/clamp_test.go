@@ -0,0 +1,48 @@
+package validator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		rule  string
+		value string
+		want  []string
+		// clamped is the value after validation.
+		clamped string
+	}{
+		{"int:64 AND min:1 AND max:1000 AND clamp", "5000", []string{"l:CLAMPED"}, "1000"},
+		{"int:64 AND min:1 AND max:1000 AND clamp", "0", []string{"l:CLAMPED"}, "1"},
+		{"int:64 AND min:1 AND max:1000 AND clamp", "500", nil, "500"},
+		{"int:64|min:1|max:1000|clamp", "5000", []string{"l:CLAMPED"}, "1000"},
+		{"int:64|min:1|max:1000|clamp", "1e9", []string{"l:CONSTRAINT_FAILED"}, "1e9"},
+		{"int:64|min:1|max:1000|clamp", "Inf", []string{"l:CONSTRAINT_FAILED"}, "Inf"},
+		{"int:64 AND min:1 AND max:1000 AND clamp", "abc", []string{"l:CONSTRAINT_FAILED"}, "abc"},
+		{"number AND max:10 AND clamp", "10.5", []string{"l:CLAMPED"}, "10"},
+		{"int:64 AND min:1 AND max:1000", "5000", []string{"l:TOO_LARGE"}, "5000"},
+	}
+	qv := NewQueryValidator()
+	for _, tt := range tests {
+		values := map[string]string{"l": tt.value}
+		got := errorCodes(qv.ValidateMap(values, map[string]string{"l": tt.rule}))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q with %s: errors %v, want %v", tt.rule, tt.value, got, tt.want)
+		}
+		if values["l"] != tt.clamped {
+			t.Errorf("%q with %s: value %s, want %s", tt.rule, tt.value, values["l"], tt.clamped)
+		}
+	}
+}
+
+func TestClampWarningKeepsSentValue(t *testing.T) {
+	qv := NewQueryValidator()
+	errors := qv.ValidateMap(map[string]string{"l": "5000"}, map[string]string{"l": "int:64 AND max:1000 AND clamp"})
+	if len(errors) != 1 || errors[0].Value != "5000" || errors[0].Severity != SeverityWarning {
+		t.Fatalf("errors = %+v, want one warning for 5000", errors)
+	}
+	if want := "value was clamped to 1000"; errors[0].Message != want {
+		t.Errorf("message %q, want %q", errors[0].Message, want)
+	}
+}
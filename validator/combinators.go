@@ -0,0 +1,283 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Rule values may combine types and name:arg constraints with AND, OR and
+// NOT, e.g. "uuid OR positiveInt" or "string AND not_in:admin,root". NOT
+// binds tightest, then AND, then OR; parentheses group. Constraint arguments
+// run to the next space, so patterns must spell spaces as \s.
+//
+// Terms may also be chained with '|', a shorthand for AND, as in
+// "number|min:18|max:120". A '|' inside a constraint argument, such as a
+// regex alternation, is kept unless what follows it names a registered type
+// or constraint; spell such a '|' as \x7c.
+type typeExpr interface {
+	// check reports whether value satisfies the expression and, if not, why.
+	check(ctx context.Context, qv *QueryValidator, value string) (failure, bool)
+	String() string
+}
+
+type typeRef string
+
+type notExpr struct{ operand typeExpr }
+
+type andExpr []typeExpr
+
+type orExpr []typeExpr
+
+func (t typeRef) check(ctx context.Context, qv *QueryValidator, value string) (failure, bool) {
+	switch qv.runType(ctx, string(t), value) {
+	case typeValid:
+		return failure{}, true
+	case typeTimedOut:
+		return fail(MsgValidatorTimeout, string(t)), false
+	case typePanicked:
+		return fail(MsgInternalError, string(t)), false
+	}
+	return failTerm(string(t), MsgInvalidType, string(t)), false
+}
+
+func (t typeRef) String() string { return string(t) }
+
+func (n notExpr) check(ctx context.Context, qv *QueryValidator, value string) (failure, bool) {
+	if _, ok := n.operand.check(ctx, qv, value); ok {
+		return fail(MsgMustNotMatch, n.operand.String()), false
+	}
+	return failure{}, true
+}
+
+func (n notExpr) String() string { return "NOT " + groupExpr(n.operand) }
+
+func (a andExpr) check(ctx context.Context, qv *QueryValidator, value string) (failure, bool) {
+	for _, operand := range a {
+		if reason, ok := operand.check(ctx, qv, value); !ok {
+			return reason, false
+		}
+	}
+	return failure{}, true
+}
+
+func (a andExpr) String() string { return joinExprs(a, " AND ") }
+
+func (o orExpr) check(ctx context.Context, qv *QueryValidator, value string) (failure, bool) {
+	for _, operand := range o {
+		if _, ok := operand.check(ctx, qv, value); ok {
+			return failure{}, true
+		}
+	}
+	alternatives := make([]string, len(o))
+	for i, operand := range o {
+		alternatives[i] = operand.String()
+	}
+	return fail(MsgNoAlternative, strings.Join(alternatives, ", ")), false
+}
+
+func (o orExpr) String() string { return joinExprs(o, " OR ") }
+
+func joinExprs(exprs []typeExpr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = groupExpr(e)
+	}
+	return strings.Join(parts, sep)
+}
+
+func groupExpr(e typeExpr) string {
+	switch e.(type) {
+	case andExpr, orExpr:
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// parseTypeExpr parses a rule value into a type expression. A plain type
+// name parses to a single typeRef.
+func (qv *QueryValidator) parseTypeExpr(rule string) (typeExpr, error) {
+	if expr, ok := qv.compiled[rule]; ok {
+		return expr, nil
+	}
+	p := &exprParser{qv: qv, tokens: qv.splitPipes(tokenizeTypeExpr(rule))}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty rule")
+	}
+	var skips []skipCondition
+	for strings.HasPrefix(p.peek(), skipPrefix) {
+		skips = append(skips, parseSkipCondition(p.peek()))
+		p.pos++
+		if p.peek() != "AND" {
+			return nil, fmt.Errorf("skip_if must be followed by AND and a rule")
+		}
+		p.pos++
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if len(skips) > 0 {
+		return skipExpr{conditions: skips, expr: expr}, nil
+	}
+	return expr, nil
+}
+
+func tokenizeTypeExpr(rule string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(rule) {
+		for strings.HasPrefix(field, "(") {
+			tokens = append(tokens, "(")
+			field = field[1:]
+		}
+		// A constraint argument may hold its own parentheses, so only
+		// unbalanced trailing ones close a group.
+		closing := 0
+		for strings.HasSuffix(field, ")") &&
+			(!strings.Contains(field, ":") || strings.Count(field, ")") > strings.Count(field, "(")) {
+			closing++
+			field = field[:len(field)-1]
+		}
+		if field != "" {
+			tokens = append(tokens, field)
+		}
+		for ; closing > 0; closing-- {
+			tokens = append(tokens, ")")
+		}
+	}
+	return tokens
+}
+
+// splitPipes turns the '|' chains within tokens into AND operators.
+func (qv *QueryValidator) splitPipes(tokens []string) []string {
+	var out []string
+	for _, tok := range tokens {
+		if !strings.Contains(tok, "|") {
+			out = append(out, tok)
+			continue
+		}
+		var terms []string
+		for _, piece := range strings.Split(tok, "|") {
+			if len(terms) > 0 && !qv.isTermName(piece) {
+				terms[len(terms)-1] += "|" + piece
+				continue
+			}
+			terms = append(terms, piece)
+		}
+		for i, term := range terms {
+			if i > 0 {
+				out = append(out, "AND")
+			}
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+// isTermName reports whether term starts with a registered type or
+// constraint name.
+func (qv *QueryValidator) isTermName(term string) bool {
+	if name, _, ok := strings.Cut(term, ":"); ok {
+		_, exists := qv.constraints[name]
+		return exists
+	}
+	_, isType := qv.typeValidators[term]
+	_, isContext := qv.contextValidators[term]
+	return isType || isContext
+}
+
+type exprParser struct {
+	qv     *QueryValidator
+	tokens []string
+	pos    int
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) parseOr() (typeExpr, error) {
+	return p.parseList("OR", p.parseAnd, func(es []typeExpr) typeExpr { return orExpr(es) })
+}
+
+func (p *exprParser) parseAnd() (typeExpr, error) {
+	return p.parseList("AND", p.parseUnary, func(es []typeExpr) typeExpr { return andExpr(es) })
+}
+
+func (p *exprParser) parseList(op string, next func() (typeExpr, error), build func([]typeExpr) typeExpr) (typeExpr, error) {
+	first, err := next()
+	if err != nil {
+		return nil, err
+	}
+	operands := []typeExpr{first}
+	for p.peek() == op {
+		p.pos++
+		operand, err := next()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	p.qv.sortByPriority(operands)
+	return build(operands), nil
+}
+
+func (p *exprParser) parseUnary() (typeExpr, error) {
+	switch tok := p.peek(); tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of rule")
+	case "NOT":
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{operand: operand}, nil
+	case "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	case ")", "AND", "OR":
+		return nil, fmt.Errorf("unexpected %q", tok)
+	default:
+		p.pos++
+		if strings.Contains(tok, ":") {
+			return p.qv.resolveConstraint(tok)
+		}
+		if !p.qv.knownType(tok) {
+			return nil, fmt.Errorf("unknown type %q", tok)
+		}
+		return typeRef(tok), nil
+	}
+}
+
+// knownType reports whether name is a type a rule may use: a registered
+// type or context validator, or required. Unknown names are rejected
+// rather than accepting anything, so a typo in one branch of an OR cannot
+// widen the rule.
+func (qv *QueryValidator) knownType(name string) bool {
+	if name == requiredType {
+		return true
+	}
+	if _, ok := qv.typeValidators[name]; ok {
+		return true
+	}
+	_, ok := qv.contextValidators[name]
+	return ok
+}
@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseTypeExpr(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"uuid", "uuid"},
+		{"uuid OR integer", "uuid OR integer"},
+		{"NOT in:reserved,admin", "NOT in:reserved,admin"},
+		{"uuid OR integer AND min:1", "uuid OR (integer AND min:1)"},
+		{"(uuid OR integer) AND NOT in:0", "(uuid OR integer) AND NOT in:0"},
+		{"NOT (email OR url)", "NOT (email OR url)"},
+		{"number|min:18|max:120", "number AND min:18 AND max:120"},
+		{"regex:^(a|b)$", "regex:^(a|b)$"},
+		{"string|regex:^(a|b)$|maxlen:5", "string AND regex:^(a|b)$ AND maxlen:5"},
+	}
+	for _, tt := range tests {
+		expr, err := qv.parseTypeExpr(tt.rule)
+		if err != nil {
+			t.Errorf("parseTypeExpr(%q): %v", tt.rule, err)
+			continue
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("parseTypeExpr(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestParseTypeExprErrors(t *testing.T) {
+	qv := NewQueryValidator()
+	for _, rule := range []string{"", "uuid OR", "AND uuid", "(uuid OR integer", "uuid)", "NOT", "uuid integer", "uuid OR positiveInt", "NOT strnig"} {
+		if _, err := qv.parseTypeExpr(rule); err == nil {
+			t.Errorf("parseTypeExpr(%q) returned no error", rule)
+		}
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule  string
+		value string
+		want  []string
+	}{
+		{"uuid OR integer", "42", nil},
+		{"uuid OR integer", "3f2504e0-4f89-41d3-9a0c-0305e82c3301", nil},
+		{"uuid OR integer", "abc", []string{"id:NO_ALTERNATIVE_MATCHED"}},
+		{"string AND NOT in:reserved,admin", "alice", nil},
+		{"string AND NOT in:reserved,admin", "admin", []string{"id:MUST_NOT_MATCH"}},
+		{"integer AND min:10", "5", []string{"id:TOO_SMALL"}},
+		{"integer AND min:10", "x", []string{"id:INVALID_TYPE"}},
+		{"(uuid OR integer) AND NOT in:0", "0", []string{"id:MUST_NOT_MATCH"}},
+		{"uuid OR", "1", []string{"id:INVALID_RULE"}},
+		// A misspelt type must not widen the OR to anything.
+		{"uuid OR positiveInt", "zzz", []string{"id:INVALID_RULE"}},
+		{"positiveInt", "42", []string{"id:INVALID_RULE"}},
+		{"required AND integer", "42", nil},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{"id": tt.value}, map[string]string{"id": tt.rule}, tt.want...)
+	}
+}
+
+func TestUnknownTypesAfterRegistration(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"id": "uuid OR positiveInt"}
+	checkCodes(t, qv, map[string]string{"id": "7"}, rules, "id:INVALID_RULE")
+	qv.AddTypeValidator("positiveInt", func(v string) bool { return allDigits(v) && v != "0" })
+	checkCodes(t, qv, map[string]string{"id": "7"}, rules)
+	checkCodes(t, qv, map[string]string{"id": "zzz"}, rules, "id:NO_ALTERNATIVE_MATCHED")
+}
+
+func TestCombinatorMessages(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule  string
+		value string
+		want  string
+	}{
+		{"uuid OR integer", "abc", "value must match one of: uuid, integer"},
+		{"NOT in:admin,root", "root", "value must not match in:admin,root"},
+		{"integer AND uuid", "1", "invalid value for type uuid"},
+	}
+	for _, tt := range tests {
+		if got := errorMessage(t, qv, "id", tt.value, tt.rule); got != tt.want {
+			t.Errorf("%q with %q: message %q, want %q", tt.rule, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAndStopsAtFirstFailure(t *testing.T) {
+	qv := NewQueryValidator()
+	calls := 0
+	qv.AddTypeValidator("counted", func(string) bool { calls++; return true })
+	expr, err := qv.parseTypeExpr("integer AND counted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := expr.check(context.Background(), qv, "abc"); ok || calls != 0 {
+		t.Errorf("ok = %v after %d calls, want a failure before counted runs", ok, calls)
+	}
+}
+
+func TestPipeChains(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddTypeValidator("even", func(v string) bool { return strings.HasSuffix(v, "0") || strings.HasSuffix(v, "2") })
+	tests := []struct {
+		rule  string
+		value string
+		want  []string
+	}{
+		{"number|min:18|max:120", "30", nil},
+		{"number|min:18|max:120", "121", []string{"p:TOO_LARGE"}},
+		{"required|number", "x", []string{"p:INVALID_TYPE"}},
+		{"number|even", "31", []string{"p:INVALID_TYPE"}},
+		{"regex:^(1|3)$|even", "3", []string{"p:INVALID_TYPE"}},
+		{"regex:^1|3$", "3", nil},
+		{"regex:^1\\x7cmin:3$", "1|min:3", nil},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{"p": tt.value}, map[string]string{"p": tt.rule}, tt.want...)
+	}
+}
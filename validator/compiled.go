@@ -0,0 +1,167 @@
+package validator
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/internal/perfect"
+)
+
+// CompiledRuleSet is a rule set parsed once, with its regexes and other
+// constraint arguments compiled, for reuse across requests instead of
+// reparsing every rule on each one. It is immutable and safe for concurrent
+// use. It snapshots the registrations and settings of the validator it was
+// compiled with, so later ones on that validator do not affect it.
+type CompiledRuleSet struct {
+	qv    *QueryValidator
+	rules Rules
+}
+
+// CompileRules compiles rules against the built-in types and constraints.
+// Use QueryValidator.CompileRules to compile against custom ones.
+func CompileRules(rules map[string]string) (*CompiledRuleSet, error) {
+	return NewQueryValidator().CompileRules(rules)
+}
+
+// CompileRules parses rules and the rules of the role and media type
+// overlays, failing on the first invalid one.
+func (qv *QueryValidator) CompileRules(rules map[string]string) (*CompiledRuleSet, error) {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	exprs := make(map[string]typeExpr)
+	compile := func(param, rule string) error {
+		if _, done := exprs[rule]; done {
+			return nil
+		}
+		expr, err := qv.parseTypeExpr(rule)
+		if err != nil {
+			return fmt.Errorf("invalid rule for %s: %v", param, err)
+		}
+		exprs[rule] = expr
+		if spec, ok := parseArrayRule(rule); ok {
+			if exprs[spec.elem], err = qv.parseTypeExpr(spec.elem); err != nil {
+				return fmt.Errorf("invalid element rule for %s: %v", param, err)
+			}
+		}
+		return nil
+	}
+
+	for _, param := range Rules(rules).params() {
+		if err := compile(param, rules[param]); err != nil {
+			return nil, err
+		}
+	}
+	for _, overlays := range []map[string]map[string]string{qv.roleRules, qv.mediaTypeRules} {
+		for _, overlay := range overlays {
+			for param, rule := range overlay {
+				if rule == "" {
+					continue
+				}
+				if err := compile(param, rule); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	set := &CompiledRuleSet{rules: maps.Clone(rules)}
+	set.qv = qv.snapshot()
+	set.qv.compiled = exprs
+	set.qv.compiledRules = set.rules
+	// The parameters of a set are fixed, so they are looked up in a perfect
+	// hash table, which hashes only a few bytes of each name. Should no
+	// table be found for them, the map serves.
+	params := set.rules.params()
+	paramRules := make([]string, len(params))
+	for i, param := range params {
+		paramRules[i] = set.rules[param]
+	}
+	set.qv.compiledParams, _ = perfect.New(params, paramRules)
+	return set, nil
+}
+
+// declaredRule returns the rule of param, looking it up in the compiled
+// set's table when rules are the set's.
+func (qv *QueryValidator) declaredRule(rules map[string]string, req validationRequest, param string) (string, bool) {
+	if req.compiled {
+		return qv.compiledParams.Get(param)
+	}
+	rule, ok := rules[param]
+	return rule, ok
+}
+
+// lookupRule is declaredRule without the presence flag.
+func (qv *QueryValidator) lookupRule(rules map[string]string, req validationRequest, param string) string {
+	rule, _ := qv.declaredRule(rules, req, param)
+	return rule
+}
+
+// sameMap reports whether a and b are the same map, not merely equal ones.
+func sameMap(a, b map[string]string) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
+
+// snapshot copies qv with registrations of its own, so later ones on qv do
+// not reach the copy. Registered values are shared: key rings, codecs, enum
+// caches, the repository and the value statistics.
+func (qv *QueryValidator) snapshot() *QueryValidator {
+	s := *qv
+	s.paramPatterns = maps.Clone(qv.paramPatterns)
+	s.typeValidators = maps.Clone(qv.typeValidators)
+	s.constraints = maps.Clone(qv.constraints)
+	s.crossRules = slices.Clone(qv.crossRules)
+	s.defaults = maps.Clone(qv.defaults)
+	s.roleRules = cloneOverlays(qv.roleRules)
+	s.mediaTypeRules = cloneOverlays(qv.mediaTypeRules)
+	s.paramScopes = maps.Clone(qv.paramScopes)
+	s.descriptions = maps.Clone(qv.descriptions)
+	s.routes = cloneOverlays(qv.routes)
+	s.contextValidators = maps.Clone(qv.contextValidators)
+	s.normalizers = maps.Clone(qv.normalizers)
+	s.memos = maps.Clone(qv.memos)
+	s.priorities = maps.Clone(qv.priorities)
+	s.constraintMessages = maps.Clone(qv.constraintMessages)
+	s.paramMessages = cloneOverlays(qv.paramMessages)
+	s.keyRings = maps.Clone(qv.keyRings)
+	s.cursorCodecs = maps.Clone(qv.cursorCodecs)
+	s.enums = maps.Clone(qv.enums)
+	s.routePatterns = maps.Clone(qv.routePatterns)
+	s.reserved = slices.Clone(qv.reserved)
+	s.uniqueCheckers = maps.Clone(qv.uniqueCheckers)
+	return &s
+}
+
+func cloneOverlays(overlays map[string]map[string]string) map[string]map[string]string {
+	if overlays == nil {
+		return nil
+	}
+	clone := make(map[string]map[string]string, len(overlays))
+	for name, overlay := range overlays {
+		clone[name] = maps.Clone(overlay)
+	}
+	return clone
+}
+
+// Rules returns a copy of the compiled rules.
+func (s *CompiledRuleSet) Rules() Rules {
+	return maps.Clone(s.rules)
+}
+
+// ValidateValues is QueryValidator.ValidateValues with the compiled rules.
+func (s *CompiledRuleSet) ValidateValues(values url.Values) []QueryValidationError {
+	return s.qv.ValidateValues(values, s.rules)
+}
+
+// ValidateValuesResult is QueryValidator.ValidateValuesResult with the
+// compiled rules.
+func (s *CompiledRuleSet) ValidateValuesResult(values url.Values) ValidationResult {
+	return s.qv.ValidateValuesResult(values, s.rules)
+}
+
+// ValidateMap is QueryValidator.ValidateMap with the compiled rules.
+func (s *CompiledRuleSet) ValidateMap(values map[string]string) []QueryValidationError {
+	return s.qv.ValidateMap(values, s.rules)
+}
@@ -0,0 +1,208 @@
+package validator
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+var benchRules = map[string]string{
+	"q":      "string AND maxlen:200",
+	"page":   "int:32 AND min:1 AND max:1000",
+	"size":   "int:32 AND in:10,20,50,100",
+	"sort":   "regex:^-?(name|created_at|updated_at)$",
+	"email":  "email",
+	"status": "in:active,pending,closed OR not_in:deleted",
+	"from":   "date",
+}
+
+var benchQuery = map[string]string{
+	"q":      "golang",
+	"page":   "3",
+	"size":   "20",
+	"sort":   "-created_at",
+	"email":  "gopher@example.com",
+	"status": "active",
+	"from":   "2024-01-02",
+}
+
+// benchBadQuery fails every rule, as attack traffic tends to.
+var benchBadQuery = map[string]string{
+	"q":      strings.Repeat("x", 201),
+	"page":   "0",
+	"size":   "15",
+	"sort":   "password",
+	"email":  "not-an-email",
+	"status": "deleted",
+	"from":   "yesterday",
+}
+
+func TestCompileRules(t *testing.T) {
+	set, err := CompileRules(benchRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := errorCodes(set.ValidateMap(maps.Clone(benchQuery))); len(got) != 0 {
+		t.Errorf("valid query: errors %v", got)
+	}
+	got := errorCodes(set.ValidateMap(maps.Clone(benchBadQuery)))
+	want := errorCodes(NewQueryValidator().ValidateMap(maps.Clone(benchBadQuery), benchRules))
+	if strings.Join(got, " ") != strings.Join(want, " ") || len(got) != len(benchRules) {
+		t.Errorf("rejected query: compiled errors %v, uncompiled %v", got, want)
+	}
+
+	rules := set.Rules()
+	rules["q"] = "int"
+	if set.Rules()["q"] != benchRules["q"] {
+		t.Error("Rules returned the set's own map")
+	}
+}
+
+func TestCompileRulesErrors(t *testing.T) {
+	tests := []map[string]string{
+		{"q": "string AND maxlen:x"},
+		{"q": "(string"},
+		{"q": "regex:("},
+		{"ids": "array:(int"},
+	}
+	for _, rules := range tests {
+		if _, err := CompileRules(rules); err == nil {
+			t.Errorf("CompileRules(%v) returned no error", rules)
+		}
+	}
+}
+
+func TestCompiledRuleSetIgnoresLaterRegistrations(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddTypeValidator("sku", func(v string) bool { return strings.HasPrefix(v, "SKU-") })
+	set, err := qv.CompileRules(map[string]string{"item": "sku", "q": "string"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qv.AddTypeValidator("sku", func(string) bool { return false })
+	qv.AddTypeValidator("string", func(string) bool { return false })
+	qv.AddRoleRules("admin", map[string]string{"q": "int"})
+
+	checkSet := func(values map[string]string, want ...string) {
+		t.Helper()
+		got := errorCodes(set.ValidateMap(values))
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Errorf("ValidateMap(%v) = %v, want %v", values, got, want)
+		}
+	}
+	checkSet(map[string]string{"item": "SKU-1", "q": "shoes"})
+	checkSet(map[string]string{"item": "1"}, "item:INVALID_TYPE")
+	checkCodes(t, qv, map[string]string{"item": "SKU-1"}, map[string]string{"item": "sku"}, "item:INVALID_TYPE")
+	if _, ok := set.qv.roleRules["admin"]; ok {
+		t.Error("role rules added after compiling reached the set")
+	}
+}
+
+func BenchmarkPerRequestValidator(b *testing.B) {
+	b.ReportAllocs()
+	for range b.N {
+		NewQueryValidator().ValidateMap(maps.Clone(benchQuery), benchRules)
+	}
+}
+
+func BenchmarkSharedValidator(b *testing.B) {
+	qv := NewQueryValidator()
+	b.ReportAllocs()
+	for range b.N {
+		qv.ValidateMap(maps.Clone(benchQuery), benchRules)
+	}
+}
+
+func BenchmarkCompiledRuleSet(b *testing.B) {
+	set, err := CompileRules(benchRules)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for range b.N {
+		set.ValidateMap(maps.Clone(benchQuery))
+	}
+}
+
+func BenchmarkCompiledRuleSetRejected(b *testing.B) {
+	set, err := CompileRules(benchRules)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for range b.N {
+		set.ValidateMap(maps.Clone(benchBadQuery))
+	}
+}
+
+func TestCompiledRuleSetParamTable(t *testing.T) {
+	set, err := CompileRules(benchRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.qv.compiledParams.Len() != len(benchRules) {
+		t.Fatalf("table holds %d parameters, want %d", set.qv.compiledParams.Len(), len(benchRules))
+	}
+	if !sameMap(set.rules, set.qv.compiledRules) {
+		t.Error("the snapshot's rules are not the set's")
+	}
+	req := validationRequest{compiled: true}
+	for param, want := range benchRules {
+		if rule, ok := set.qv.declaredRule(nil, req, strings.Clone(param)); !ok || rule != want {
+			t.Errorf("declaredRule(%q) = %q, %v, want %q", param, rule, ok, want)
+		}
+	}
+	if _, ok := set.qv.declaredRule(nil, req, "unknown"); ok {
+		t.Error("declaredRule found an undeclared parameter")
+	}
+
+	// Without a table, as when none could be built, the map serves.
+	fallback, err := CompileRules(benchRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback.qv.compiledParams = nil
+	for _, s := range []*CompiledRuleSet{set, fallback} {
+		if got := errorCodes(s.ValidateMap(maps.Clone(benchQuery))); len(got) != 0 {
+			t.Errorf("valid query: errors %v", got)
+		}
+		if got := errorCodes(s.ValidateMap(map[string]string{"page": "0", "extra": "1"})); strings.Join(got, " ") != "extra:UNEXPECTED_PARAM page:TOO_SMALL" {
+			t.Errorf("rejected query: errors %v", got)
+		}
+	}
+}
+
+// lookupNames are copies of the parameter names of benchRules, so lookups
+// compare their bytes as they would for names parsed from a query.
+func lookupNames() []string {
+	var names []string
+	for param := range benchRules {
+		names = append(names, strings.Clone(param))
+	}
+	return names
+}
+
+func BenchmarkParamLookupMap(b *testing.B) {
+	names := lookupNames()
+	b.ReportAllocs()
+	for range b.N {
+		for _, name := range names {
+			_ = benchRules[name]
+		}
+	}
+}
+
+func BenchmarkParamLookupPerfectHash(b *testing.B) {
+	set, err := CompileRules(benchRules)
+	if err != nil {
+		b.Fatal(err)
+	}
+	names := lookupNames()
+	b.ReportAllocs()
+	for range b.N {
+		for _, name := range names {
+			set.qv.compiledParams.Get(name)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentRegistration registers while validating from other
+// goroutines. It finds data races under go test -race.
+func TestConcurrentRegistration(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"n": "required AND integer AND min:1", "sort": "in:asc,desc"}
+	compiled, err := qv.CompileRules(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				name := fmt.Sprintf("t%d_%d", i, j)
+				qv.AddTypeValidator(name, ValidRelativeURL)
+				if err := qv.AddParamPattern(name, "^[a-z_0-9]+$"); err != nil {
+					t.Error(err)
+				}
+				qv.SetMessage(name, "", "bad "+name)
+				qv.AddCrossRule(func(map[string]string) []QueryValidationError { return nil })
+				qv.RegisterRoute("/"+name, map[string]string{name: name})
+			}
+		}()
+	}
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				if errs := qv.ValidateMap(map[string]string{"n": "2", "sort": "asc"}, rules); len(errs) != 0 {
+					t.Errorf("ValidateMap: %v", errs)
+				}
+				if errs := qv.ValidateValues(url.Values{"n": {"0"}}, rules); len(errs) != 1 || errs[0].Code != MsgTooSmall {
+					t.Errorf("ValidateValues: %v", errs)
+				}
+				if errs := compiled.ValidateMap(map[string]string{"sort": "up"}); len(errs) != 2 {
+					t.Errorf("compiled ValidateMap: %v", errs)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Registrations made concurrently all took effect.
+	checkCodes(t, qv, map[string]string{"t3_49": "//evil.example"}, map[string]string{"t3_49": "t3_49"}, "t3_49:INVALID_TYPE")
+	if got := errorMessage(t, qv, "t0_0", "x", "t0_0"); got != "bad t0_0" {
+		t.Errorf("message %q, want %q", got, "bad t0_0")
+	}
+}
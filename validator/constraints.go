@@ -0,0 +1,248 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ConstraintFactory builds a value check from the argument of a
+// parameterised rule term, e.g. "admin,root" in "not_in:admin,root".
+type ConstraintFactory func(arg string) (func(string) bool, error)
+
+// AddConstraint registers a parameterised rule term usable as name:arg in
+// rule expressions.
+func (qv *QueryValidator) AddConstraint(name string, factory ConstraintFactory) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.constraints[name] = factory
+}
+
+// SetConstraintMessage sets the message of values failing the named
+// constraint. The template may use {param}, {value} and {constraint}, which
+// is the constraint's argument, e.g. "{param} must be at least {constraint}".
+// Per-request overrides from Locals still take precedence.
+func (qv *QueryValidator) SetConstraintMessage(name, template string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.constraintMessages[name] = template
+	registerTemplate(template)
+}
+
+// constraintKeys are the message keys of the built-in constraints; other
+// constraints fail with MsgConstraintFailed.
+var constraintKeys = map[string]string{
+	"min":           MsgTooSmall,
+	"max":           MsgTooLarge,
+	"in":            MsgNotAllowed,
+	"enum":          MsgNotAllowed,
+	"exists":        MsgNotFound,
+	"unique":        MsgAlreadyTaken,
+	"minitems":      MsgTooFewItems,
+	"maxitems":      MsgTooManyItems,
+	"not_in":        MsgForbiddenValue,
+	"not_eq":        MsgForbiddenValue,
+	"regex":         MsgPatternMismatch,
+	"not_regex":     MsgMustNotMatch,
+	"len":           MsgWrongLength,
+	"minlen":        MsgTooShort,
+	"maxlen":        MsgTooLong,
+	"allowed_hosts": MsgForeignRedirect,
+	cursorTerm:      MsgInvalidCursor,
+}
+
+func (qv *QueryValidator) addBuiltinConstraints() {
+	qv.constraints["not_in"] = func(arg string) (func(string) bool, error) {
+		excluded := strings.Split(arg, ",")
+		return func(v string) bool { return !slices.Contains(excluded, v) }, nil
+	}
+	qv.constraints["not_eq"] = func(arg string) (func(string) bool, error) {
+		return func(v string) bool { return v != arg }, nil
+	}
+	qv.constraints["not_regex"] = func(arg string) (func(string) bool, error) {
+		re, err := qv.regexpEngine.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return func(v string) bool { return !re.MatchString(v) }, nil
+	}
+	qv.constraints["allowed_hosts"] = func(arg string) (func(string) bool, error) {
+		return NewRedirectValidator(strings.Split(arg, ",")...), nil
+	}
+	qv.constraints[decryptTerm] = qv.decryptConstraint
+	qv.constraints[cursorTerm] = qv.cursorConstraint
+	qv.constraints["enum"] = qv.enumConstraint
+	qv.constraints["exists"] = qv.existsConstraint
+	qv.constraints["unique"] = qv.uniqueConstraint
+	qv.arrayConstraints()
+	qv.constraints[namePatternTerm] = qv.namePatternConstraint
+	qv.crossFieldConstraints()
+	qv.constraints["regex"] = func(arg string) (func(string) bool, error) {
+		re, err := qv.regexpEngine.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	}
+	// Lengths count runes unless a unit follows the bounds, as in
+	// maxlen:10:graphemes. len takes an exact length or a min,max range.
+	qv.constraints["len"] = func(arg string) (func(string) bool, error) {
+		bounds, unit, err := splitLengthArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		lo, hi, isRange := strings.Cut(bounds, ",")
+		if !isRange {
+			hi = lo
+		}
+		minLen, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, err
+		}
+		maxLen, err := strconv.Atoi(hi)
+		if err != nil {
+			return nil, err
+		}
+		return maxLength(LengthOptions{Min: minLen, Max: maxLen, Unit: unit}), nil
+	}
+	qv.constraints["minlen"] = func(arg string) (func(string) bool, error) {
+		bounds, unit, err := splitLengthArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(bounds)
+		if err != nil {
+			return nil, err
+		}
+		return NewLengthValidator(LengthOptions{Min: n, Unit: unit}), nil
+	}
+	qv.constraints["maxlen"] = func(arg string) (func(string) bool, error) {
+		bounds, unit, err := splitLengthArg(arg)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(bounds)
+		if err != nil {
+			return nil, err
+		}
+		return maxLength(LengthOptions{Max: n, Unit: unit}), nil
+	}
+	qv.constraints["in"] = func(arg string) (func(string) bool, error) {
+		allowed := strings.Split(arg, ",")
+		return func(v string) bool { return slices.Contains(allowed, v) }, nil
+	}
+	qv.constraints["min"] = func(arg string) (func(string) bool, error) {
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, err
+		}
+		return func(v string) bool {
+			n, err := strconv.ParseFloat(v, 64)
+			return err == nil && n >= bound
+		}, nil
+	}
+	qv.constraints["max"] = func(arg string) (func(string) bool, error) {
+		bound, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, err
+		}
+		return func(v string) bool {
+			n, err := strconv.ParseFloat(v, 64)
+			return err == nil && n <= bound
+		}, nil
+	}
+	// scale:N accepts numbers with at most N fractional digits, e.g. cents.
+	qv.constraints["scale"] = func(arg string) (func(string) bool, error) {
+		digits, err := strconv.Atoi(arg)
+		if err != nil || digits < 0 {
+			return nil, fmt.Errorf("invalid scale %q", arg)
+		}
+		return func(v string) bool {
+			_, fraction, _ := strings.Cut(v, ".")
+			return len(fraction) <= digits
+		}, nil
+	}
+	// int:N, uint:N and float:N accept values that parse into a number of N
+	// bits, signed as the integer and float types are.
+	qv.constraints["int"] = bitSizeConstraint(func(v string, bits int) error {
+		if !allDigits(strings.TrimPrefix(v, "-")) {
+			return strconv.ErrSyntax
+		}
+		_, err := strconv.ParseInt(v, 10, bits)
+		return err
+	})
+	qv.constraints["uint"] = bitSizeConstraint(func(v string, bits int) error {
+		_, err := strconv.ParseUint(v, 10, bits)
+		return err
+	})
+	qv.constraints["float"] = bitSizeConstraint(func(v string, bits int) error {
+		if !decimalShape(v) {
+			return strconv.ErrSyntax
+		}
+		_, err := strconv.ParseFloat(v, bits)
+		return err
+	})
+}
+
+func bitSizeConstraint(parse func(v string, bits int) error) ConstraintFactory {
+	return func(arg string) (func(string) bool, error) {
+		bits, err := strconv.Atoi(arg)
+		if err != nil || bits <= 0 || bits > 64 {
+			return nil, fmt.Errorf("invalid bit size %q", arg)
+		}
+		return func(v string) bool { return parse(v, bits) == nil }, nil
+	}
+}
+
+// constraintRef is a name:arg term resolved against the constraint registry.
+type constraintRef struct {
+	term   string
+	name   string
+	arg    string
+	accept func(string) bool
+}
+
+func (c constraintRef) check(_ context.Context, qv *QueryValidator, value string) (failure, bool) {
+	valid, panicked := qv.safeCheck(c.term, c.accept, value)
+	switch {
+	case panicked:
+		return fail(MsgInternalError, c.term), false
+	case valid:
+		return failure{}, true
+	}
+	reason := failTerm(c.name, MsgConstraintFailed, c.term)
+	if key, ok := constraintKeys[c.name]; ok {
+		var arg any = c.arg
+		switch c.name {
+		case "len", "minlen", "maxlen":
+			bounds, _, _ := splitLengthArg(c.arg)
+			arg = strings.Replace(bounds, ",", " to ", 1)
+		case minItemsTerm, maxItemsTerm:
+			// Item counts select a plural form.
+			arg, _ = strconv.Atoi(c.arg)
+		}
+		reason = failTerm(c.name, key, arg)
+	}
+	if tmpl, ok := qv.constraintMessages[c.name]; ok {
+		reason.template = tmpl
+		reason.args = []any{c.arg}
+	}
+	return reason, false
+}
+
+func (c constraintRef) String() string { return c.term }
+
+func (qv *QueryValidator) resolveConstraint(term string) (typeExpr, error) {
+	name, arg, _ := strings.Cut(term, ":")
+	factory, exists := qv.constraints[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown constraint %s", name)
+	}
+	accept, err := qv.safeFactory(name, factory, arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid argument for %s: %v", name, err)
+	}
+	return constraintRef{term: term, name: name, arg: arg, accept: accept}, nil
+}
@@ -0,0 +1,143 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+func TestNegationConstraints(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule  string
+		value string
+		want  []string
+	}{
+		{"not_in:admin,root", "alice", nil},
+		{"not_in:admin,root", "root", []string{"p:FORBIDDEN_VALUE"}},
+		{"not_in:admin,root", "Admin", nil},
+		{"not_eq:0", "1", nil},
+		{"not_eq:0", "0", []string{"p:FORBIDDEN_VALUE"}},
+		{"not_regex:^/internal", "/public/a", nil},
+		{"not_regex:^/internal", "/internal/a", []string{"p:MUST_NOT_MATCH"}},
+		{"string AND not_regex:\\s", "a b", []string{"p:MUST_NOT_MATCH"}},
+		{"not_regex:(", "a", []string{"p:INVALID_RULE"}},
+		{"nope:1", "a", []string{"p:INVALID_RULE"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{"p": tt.value}, map[string]string{"p": tt.rule}, tt.want...)
+	}
+}
+
+func TestNegationMessages(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule  string
+		value string
+		want  string
+	}{
+		{"not_in:admin,root", "admin", "value must not be any of admin,root"},
+		{"not_eq:0", "0", "value must not be any of 0"},
+		{"not_regex:^/internal", "/internal", "value must not match ^/internal"},
+	}
+	for _, tt := range tests {
+		if got := errorMessage(t, qv, "p", tt.value, tt.rule); got != tt.want {
+			t.Errorf("%q: message %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestAddConstraint(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddConstraint("multiple_of", func(arg string) (func(string) bool, error) {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n == 0 {
+			return nil, fmt.Errorf("invalid divisor %q", arg)
+		}
+		return func(v string) bool {
+			m, err := strconv.Atoi(v)
+			return err == nil && m%n == 0
+		}, nil
+	})
+	rules := map[string]string{"size": "integer AND multiple_of:5"}
+	checkCodes(t, qv, map[string]string{"size": "15"}, rules)
+	checkCodes(t, qv, map[string]string{"size": "12"}, rules, "size:CONSTRAINT_FAILED")
+	checkCodes(t, qv, map[string]string{"size": "12"}, map[string]string{"size": "multiple_of:0"}, "size:INVALID_RULE")
+
+	if got, want := errorMessage(t, qv, "size", "12", "multiple_of:5"), "value fails constraint multiple_of:5"; got != want {
+		t.Errorf("message %q, want %q", got, want)
+	}
+	qv.SetConstraintMessage("multiple_of", "{param} must be a multiple of {constraint}, not {value}")
+	if got, want := errorMessage(t, qv, "size", "12", "multiple_of:5"), "size must be a multiple of 5, not 12"; got != want {
+		t.Errorf("message %q, want %q", got, want)
+	}
+}
+
+func TestBitSizeConstraints(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule  string
+		value string
+		ok    bool
+	}{
+		{"int:8", "127", true},
+		{"int:8", "128", false},
+		{"int:8", "-128", true},
+		{"int:8", "+5", false},
+		{"uint:8", "255", true},
+		{"uint:8", "-1", false},
+		{"uint:8", "+5", false},
+		{"float:32", "3.5", true},
+		{"float:32", "+5", false},
+		{"float:32", "-5e-1", true},
+		{"float:32", "Inf", false},
+		{"float:32", "1e39", false},
+		{"scale:2", "1.25", true},
+		{"scale:2", "1.255", false},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(map[string]string{"n": tt.value}, map[string]string{"n": tt.rule})
+		if got := len(errs) == 0; got != tt.ok {
+			t.Errorf("%s with %s: ok = %v, want %v (%v)", tt.rule, tt.value, got, tt.ok, errorCodes(errs))
+		}
+	}
+	checkCodes(t, qv, map[string]string{"n": "1"}, map[string]string{"n": "int:65"}, "n:INVALID_RULE")
+}
+
+func TestConstraintMessages(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule  string
+		value string
+		want  string
+	}{
+		{"min:18", "17", "value must be at least 18"},
+		{"max:120", "121", "value must be at most 120"},
+		{"in:asc,desc", "up", "value must be one of asc,desc"},
+		{"regex:^[a-z]+$", "A", "value must match ^[a-z]+$"},
+		{"minlen:3", "ab", "value must be at least 3 characters long"},
+		{"maxlen:2", "abc", "value must be at most 2 characters long"},
+		{"len:2,3", "a", "value must be 2 to 3 characters long"},
+		{"len:2", "a", "value must be 2 characters long"},
+	}
+	for _, tt := range tests {
+		if got := errorMessage(t, qv, "p", tt.value, tt.rule); got != tt.want {
+			t.Errorf("%q: message %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestSetConstraintMessage(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetConstraintMessage("min", "{param} must be at least {constraint}, got {value}")
+	if got, want := errorMessage(t, qv, "age", "17", "number|min:18"), "age must be at least 18, got 17"; got != want {
+		t.Errorf("min message %q, want %q", got, want)
+	}
+	if got, want := errorMessage(t, qv, "age", "121", "number|max:120"), "value must be at most 120"; got != want {
+		t.Errorf("max message %q, want %q", got, want)
+	}
+	errors := qv.ValidateMap(map[string]string{"age": "17"}, map[string]string{"age": "min:18"})
+	if errors[0].Code != MsgTooSmall {
+		t.Errorf("code %q, want %s", errors[0].Code, MsgTooSmall)
+	}
+}
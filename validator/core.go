@@ -0,0 +1,7 @@
+//go:build tinygo || wasm
+
+package validator
+
+// frameworkHooks is empty in core builds, which leave out the Fiber and
+// net/http adapters so the package compiles under TinyGo and for WASM.
+type frameworkHooks struct{}
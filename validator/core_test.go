@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"go/build"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// coreBuilds are the WASM and TinyGo targets the core must build for.
+var coreBuilds = []struct {
+	goos, goarch string
+	tags         []string
+}{
+	{"js", "wasm", nil},
+	{"wasip1", "wasm", nil},
+	{"linux", "arm", []string{"tinygo"}},
+}
+
+// adapterDeps are the packages only the adapters may pull in.
+var adapterDeps = []string{"net/http", "crypto/tls", "github.com/gofiber/", "github.com/getkin/"}
+
+// TestCoreBuildLeavesOutAdapters checks which files the package has in WASM
+// and TinyGo builds, without needing those toolchains.
+func TestCoreBuildLeavesOutAdapters(t *testing.T) {
+	for _, tt := range coreBuilds {
+		ctx := build.Default
+		ctx.GOOS, ctx.GOARCH, ctx.BuildTags = tt.goos, tt.goarch, tt.tags
+		pkg, err := ctx.ImportDir(".", 0)
+		if err != nil {
+			t.Fatalf("%s/%s %v: %v", tt.goos, tt.goarch, tt.tags, err)
+		}
+		if !slices.Contains(pkg.GoFiles, "core.go") || slices.Contains(pkg.GoFiles, "fiber.go") {
+			t.Errorf("%s/%s %v: files %v", tt.goos, tt.goarch, tt.tags, pkg.GoFiles)
+		}
+	}
+}
+
+// TestCoreBuildDeps checks the transitive dependencies of core builds, so
+// an adapter package reached through any import fails it.
+func TestCoreBuildDeps(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	for _, tt := range coreBuilds {
+		cmd := exec.Command(goTool, "list", "-deps", "-tags", strings.Join(tt.tags, ","), ".")
+		cmd.Env = append(os.Environ(), "GOOS="+tt.goos, "GOARCH="+tt.goarch, "CGO_ENABLED=0")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("%s/%s %v: go list: %v", tt.goos, tt.goarch, tt.tags, err)
+		}
+		for _, dep := range strings.Fields(string(out)) {
+			for _, adapter := range adapterDeps {
+				if dep == adapter || strings.HasSuffix(adapter, "/") && strings.HasPrefix(dep, adapter) {
+					t.Errorf("%s/%s %v: core build depends on %s", tt.goos, tt.goarch, tt.tags, dep)
+				}
+			}
+		}
+	}
+}
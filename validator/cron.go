@@ -0,0 +1,132 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CronDialect selects the cron syntax accepted by a cron validator.
+type CronDialect int
+
+const (
+	// CronStandard is the five-field crontab(5) format: minute hour dom month dow.
+	CronStandard CronDialect = iota
+	// CronWithSeconds prefixes the standard fields with a seconds field.
+	CronWithSeconds
+	// CronOptionalSeconds accepts either of the above.
+	CronOptionalSeconds
+)
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+	// question reports whether "?" may stand in for "*".
+	question bool
+}
+
+var (
+	cronMonthNames = map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}
+	cronWeekdayNames = map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}
+
+	cronSecondField = cronField{name: "second", min: 0, max: 59}
+	cronFields      = []cronField{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31, question: true},
+		{name: "month", min: 1, max: 12, names: cronMonthNames},
+		{name: "day of week", min: 0, max: 7, names: cronWeekdayNames, question: true},
+	}
+
+	cronMacros = map[string]bool{
+		"@yearly": true, "@annually": true, "@monthly": true, "@weekly": true,
+		"@daily": true, "@midnight": true, "@hourly": true,
+	}
+)
+
+// NewCronValidator returns a type validator accepting cron expressions in the
+// given dialect, e.g. "*/5 * * * *".
+func NewCronValidator(dialect CronDialect) func(string) bool {
+	return func(v string) bool {
+		return ParseCronExpression(v, dialect) == nil
+	}
+}
+
+// ParseCronExpression checks expr against dialect and describes the first
+// offending field when it is malformed.
+func ParseCronExpression(expr string, dialect CronDialect) error {
+	expr = strings.TrimSpace(expr)
+	if cronMacros[strings.ToLower(expr)] {
+		return nil
+	}
+
+	parts := strings.Fields(expr)
+	fields := cronFields
+	switch {
+	case len(parts) == 6 && dialect != CronStandard:
+		fields = append([]cronField{cronSecondField}, cronFields...)
+	case len(parts) == 5 && dialect != CronWithSeconds:
+	default:
+		return fmt.Errorf("cron expression has %d fields", len(parts))
+	}
+
+	for i, part := range parts {
+		if err := fields[i].check(part); err != nil {
+			return fmt.Errorf("invalid %s field %q: %v", fields[i].name, part, err)
+		}
+	}
+	return nil
+}
+
+func (f cronField) check(field string) error {
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			n, err := strconv.Atoi(step)
+			if err != nil || n < 1 || n > f.max {
+				return fmt.Errorf("bad step %q", step)
+			}
+		}
+
+		if rangePart == "*" || (rangePart == "?" && f.question && !hasStep) {
+			continue
+		}
+
+		lo, hi, isRange := strings.Cut(rangePart, "-")
+		start, err := f.value(lo)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		end, err := f.value(hi)
+		if err != nil {
+			return err
+		}
+		if end < start {
+			return fmt.Errorf("range %s is reversed", rangePart)
+		}
+	}
+	return nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if n, ok := f.names[strings.ToUpper(s)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("bad value %q", s)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", n, f.min, f.max)
+	}
+	return n, nil
+}
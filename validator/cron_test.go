@@ -0,0 +1,53 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCronExpression(t *testing.T) {
+	tests := []struct {
+		expr                      string
+		standard, seconds, either bool
+	}{
+		{"*/5 * * * *", true, false, true},
+		{"0 9-17 * * MON-FRI", true, false, true},
+		{"0 0 1,15 jan,jul ?", true, false, true},
+		{"30 0 0 * * *", false, true, true},
+		{"@daily", true, true, true},
+		{"@Hourly", true, true, true},
+		{"60 * * * *", false, false, false},
+		{"* 24 * * *", false, false, false},
+		{"* * 0 * *", false, false, false},
+		{"* * * 13 *", false, false, false},
+		{"* * * * 8", false, false, false},
+		{"0 17-9 * * *", false, false, false},
+		{"*/0 * * * *", false, false, false},
+		{"?/5 * * * *", false, false, false},
+		{"* * ?/2 * *", false, false, false},
+		{"* * * * * * *", false, false, false},
+		{"* * * *", false, false, false},
+		{"@reboot", false, false, false},
+	}
+	for _, tt := range tests {
+		for dialect, want := range map[CronDialect]bool{CronStandard: tt.standard, CronWithSeconds: tt.seconds, CronOptionalSeconds: tt.either} {
+			if err := ParseCronExpression(tt.expr, dialect); (err == nil) != want {
+				t.Errorf("ParseCronExpression(%q, %d) = %v, want valid %v", tt.expr, dialect, err, want)
+			}
+		}
+	}
+}
+
+func TestParseCronExpressionNamesField(t *testing.T) {
+	err := ParseCronExpression("0 25 * * *", CronStandard)
+	if err == nil || !strings.Contains(err.Error(), "hour") {
+		t.Errorf("error %v, want one naming the hour field", err)
+	}
+}
+
+func TestCronType(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"schedule": "cron"}
+	checkCodes(t, qv, map[string]string{"schedule": "15 3 * * SUN"}, rules)
+	checkCodes(t, qv, map[string]string{"schedule": "every day"}, rules, "schedule:INVALID_TYPE")
+}
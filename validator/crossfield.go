@@ -0,0 +1,183 @@
+package validator
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cross-field rule terms relate a parameter to its siblings:
+//
+//	"end_date":  "date AND gt_field:start_date AND required_with:start_date"
+//	"cursor":    "string AND mutually_exclusive:cursor,page"
+//	"after":     "string AND required_if:pagination=cursor"
+//	"page":      "int:10 AND forbidden_if:cursor"
+//
+// required_with:a,b requires the parameter when any of a or b is sent;
+// mutually_exclusive:a,b allows at most one of a and b; gt_field, gte_field,
+// lt_field and lte_field compare the value with another parameter's, as
+// numbers, dates or times when both parse as such and as strings otherwise.
+// Terms naming absent parameters hold.
+//
+// required_if, required_unless, forbidden_if and forbidden_unless take a
+// condition on another parameter: a bare name holds when it is sent, and
+// name=a,b when it is sent with one of the listed values.
+var fieldComparisons = map[string]struct {
+	key  string
+	hold func(cmp int) bool
+}{
+	"gt_field":  {MsgGreaterThanField, func(cmp int) bool { return cmp > 0 }},
+	"gte_field": {MsgAtLeastField, func(cmp int) bool { return cmp >= 0 }},
+	"lt_field":  {MsgLessThanField, func(cmp int) bool { return cmp < 0 }},
+	"lte_field": {MsgAtMostField, func(cmp int) bool { return cmp <= 0 }},
+}
+
+// conditionalTerms require or forbid the parameter depending on a condition,
+// which unless terms negate. The keys name the condition in the message.
+var conditionalTerms = map[string]struct {
+	required   bool
+	negate     bool
+	onValue    string
+	onPresence string
+}{
+	"required_if":      {true, false, MsgRequiredIf, MsgRequiredWith},
+	"required_unless":  {true, true, MsgRequiredUnless, MsgRequiredWithout},
+	"forbidden_if":     {false, false, MsgForbiddenIf, MsgForbiddenWith},
+	"forbidden_unless": {false, true, MsgForbiddenUnless, MsgForbiddenWithout},
+}
+
+const (
+	requiredWithTerm      = "required_with"
+	mutuallyExclusiveTerm = "mutually_exclusive"
+)
+
+func isCrossFieldTerm(name string) bool {
+	_, comparison := fieldComparisons[name]
+	_, conditional := conditionalTerms[name]
+	return comparison || conditional || name == requiredWithTerm || name == mutuallyExclusiveTerm
+}
+
+// hasCrossFieldTerms cheaply rules out rules without cross-field terms.
+func hasCrossFieldTerms(rule string) bool {
+	for _, marker := range []string{"_field:", "required_", "forbidden_", mutuallyExclusiveTerm} {
+		if strings.Contains(rule, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// crossFieldConstraints registers the cross-field terms, which constrain
+// nothing on their own, so rules using them parse.
+func (qv *QueryValidator) crossFieldConstraints() {
+	accept := func(string) (func(string) bool, error) {
+		return func(string) bool { return true }, nil
+	}
+	for name := range fieldComparisons {
+		qv.constraints[name] = accept
+	}
+	for name := range conditionalTerms {
+		qv.constraints[name] = accept
+	}
+	qv.constraints[requiredWithTerm] = accept
+	qv.constraints[mutuallyExclusiveTerm] = accept
+}
+
+// crossFieldErrors checks the cross-field terms of rules against values.
+func (qv *QueryValidator) crossFieldErrors(values, rules map[string]string) []QueryValidationError {
+	var errors []QueryValidationError
+	reported := make(map[string]bool)
+	for _, param := range Rules(rules).params() {
+		rule := rules[param]
+		if !hasCrossFieldTerms(rule) {
+			continue
+		}
+		value, present := values[param]
+		for _, term := range ruleTerms(rule) {
+			name, arg, _ := strings.Cut(term, ":")
+			if !isCrossFieldTerm(name) {
+				continue
+			}
+			if conditional, ok := conditionalTerms[name]; ok {
+				other, want, hasValue := strings.Cut(arg, "=")
+				otherValue, sent := values[other]
+				holds := sent && (!hasValue || slices.Contains(strings.Split(want, ","), otherValue))
+				if holds == conditional.negate {
+					continue
+				}
+				reason := failTerm(name, conditional.onPresence, other)
+				if hasValue {
+					reason = failTerm(name, conditional.onValue, other, want)
+				}
+				switch {
+				case conditional.required && !present && !qv.skipped(rule, values):
+					errors = append(errors, newValidationError(param, "", reason))
+				case !conditional.required && present:
+					errors = append(errors, newValidationError(param, value, reason))
+				}
+				continue
+			}
+
+			others := strings.Split(arg, ",")
+			switch name {
+			case requiredWithTerm:
+				if present || qv.skipped(rule, values) {
+					continue
+				}
+				for _, other := range others {
+					if _, sent := values[other]; sent {
+						errors = append(errors, newValidationError(param, "", failTerm(name, MsgRequiredWith, other)))
+						break
+					}
+				}
+			case mutuallyExclusiveTerm:
+				slices.Sort(others)
+				var sent []string
+				for _, other := range others {
+					if _, ok := values[other]; ok {
+						sent = append(sent, other)
+					}
+				}
+				if key := strings.Join(others, ","); len(sent) > 1 && !reported[key] {
+					reported[key] = true
+					errors = append(errors, newValidationError(sent[1], values[sent[1]], failTerm(name, MsgMutuallyExclusive, sent[0])))
+				}
+			default:
+				otherValue, sent := values[others[0]]
+				if !present || !sent {
+					continue
+				}
+				comparison := fieldComparisons[name]
+				if !comparison.hold(compareValues(value, otherValue)) {
+					errors = append(errors, newValidationError(param, value, failTerm(name, comparison.key, others[0])))
+				}
+			}
+		}
+	}
+	return errors
+}
+
+// compareValues orders a and b as numbers, then as dates or times, then as
+// strings.
+func compareValues(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	for _, layout := range []string{time.DateOnly, time.RFC3339Nano} {
+		if x, err := time.Parse(layout, a); err == nil {
+			if y, err := time.Parse(layout, b); err == nil {
+				return x.Compare(y)
+			}
+		}
+	}
+	return strings.Compare(a, b)
+}
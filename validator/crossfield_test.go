@@ -0,0 +1,148 @@
+package validator
+
+import "testing"
+
+func TestCrossFieldTerms(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"start_date": "date",
+		"end_date":   "date AND gt_field:start_date AND required_with:start_date",
+		"cursor":     "string AND mutually_exclusive:cursor,page",
+		"page":       "integer AND mutually_exclusive:cursor,page",
+		"min":        "number",
+		"max":        "number AND gte_field:min",
+		"from":       "string",
+		"to":         "string|lt_field:from",
+		"hi":         "number|lte_field:min",
+	}
+	tests := []struct {
+		values map[string]string
+		want   []string
+	}{
+		{map[string]string{"start_date": "2024-01-01", "end_date": "2024-02-01"}, nil},
+		{map[string]string{"start_date": "2024-02-01", "end_date": "2024-01-01"}, []string{"end_date:GT_FIELD"}},
+		{map[string]string{"start_date": "2024-01-01", "end_date": "2024-01-01"}, []string{"end_date:GT_FIELD"}},
+		{map[string]string{"start_date": "2024-01-01"}, []string{"end_date:REQUIRED_WITH"}},
+		{map[string]string{"end_date": "2024-01-01"}, nil},
+		{map[string]string{"cursor": "abc"}, nil},
+		{map[string]string{"page": "2"}, nil},
+		{map[string]string{"cursor": "abc", "page": "2"}, []string{"page:MUTUALLY_EXCLUSIVE"}},
+		{map[string]string{"min": "9", "max": "10"}, nil},
+		{map[string]string{"min": "10", "max": "10"}, nil},
+		{map[string]string{"min": "10", "max": "9.5"}, []string{"max:GTE_FIELD"}},
+		{map[string]string{"min": "9", "max": "10", "hi": "10"}, []string{"hi:LTE_FIELD"}},
+		{map[string]string{"from": "b", "to": "a"}, nil},
+		{map[string]string{"from": "b", "to": "c"}, []string{"to:LT_FIELD"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, tt.values, rules, tt.want...)
+	}
+}
+
+func TestCrossFieldMessages(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"start": "date",
+		"end":   "date AND gt_field:start AND required_with:start",
+		"a":     "string AND mutually_exclusive:a,b",
+		"b":     "string",
+	}
+	tests := []struct {
+		values map[string]string
+		want   string
+	}{
+		{map[string]string{"start": "2024-02-01", "end": "2024-01-01"}, "value must be greater than start"},
+		{map[string]string{"start": "2024-02-01"}, "parameter is required when start is sent"},
+		{map[string]string{"a": "x", "b": "y"}, "parameter cannot be combined with a"},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(tt.values, rules)
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%v: errors %+v, want %q", tt.values, errs, tt.want)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"9", "10", -1},
+		{"1e3", "999", 1},
+		{"2024-01-02", "2024-01-10", -1},
+		{"2024-01-02T10:00:00Z", "2024-01-02T09:00:00-02:00", -1},
+		{"b", "a", 1},
+		{"10", "abc", -1},
+		{"x", "x", 0},
+	}
+	for _, tt := range tests {
+		if got := compareValues(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareValues(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAddCrossRule(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddCrossRule(func(values map[string]string) []QueryValidationError {
+		if values["lat"] != "" && values["lng"] == "" {
+			return []QueryValidationError{{Parameter: "lng", Code: "NEEDS_LAT_LNG", Message: "lng must come with lat"}}
+		}
+		return nil
+	})
+	rules := map[string]string{"lat": "number", "lng": "number"}
+	checkCodes(t, qv, map[string]string{"lat": "1", "lng": "2"}, rules)
+	checkCodes(t, qv, map[string]string{"lat": "1"}, rules, "lng:NEEDS_LAT_LNG")
+}
+
+func TestConditionalTerms(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"pagination": "in:cursor,offset,none",
+		"after":      "string AND required_if:pagination=cursor",
+		"page":       "int:10 AND forbidden_if:after",
+		"offset":     "integer AND forbidden_unless:pagination=offset",
+		"limit":      "integer AND required_unless:pagination=none",
+		"token":      "string AND required_unless:after",
+		"debug":      "boolean AND forbidden_unless:token",
+	}
+	tests := []struct {
+		values map[string]string
+		want   []string
+	}{
+		{map[string]string{"pagination": "cursor", "after": "x", "limit": "10"}, nil},
+		{map[string]string{"pagination": "cursor", "limit": "10", "token": "t"}, []string{"after:REQUIRED_IF"}},
+		{map[string]string{"pagination": "cursor", "after": "x", "page": "2", "limit": "10"}, []string{"page:FORBIDDEN_WITH"}},
+		{map[string]string{"pagination": "offset", "offset": "20", "limit": "10", "token": "t"}, nil},
+		{map[string]string{"pagination": "none", "offset": "20", "token": "t"}, []string{"offset:FORBIDDEN_UNLESS"}},
+		{map[string]string{"pagination": "offset", "token": "t"}, []string{"limit:REQUIRED_UNLESS"}},
+		{map[string]string{"pagination": "none"}, []string{"token:REQUIRED_WITHOUT"}},
+		{map[string]string{"pagination": "none", "token": "t", "debug": "true"}, nil},
+		{map[string]string{"pagination": "none", "after": "x", "debug": "true"}, []string{"debug:FORBIDDEN_WITHOUT"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, tt.values, rules, tt.want...)
+	}
+}
+
+func TestConditionalTermMessages(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule   string
+		values map[string]string
+		want   string
+	}{
+		{"required_if:mode=a,b", map[string]string{"mode": "b"}, "parameter is required when mode is a,b"},
+		{"required_unless:mode=a", map[string]string{"mode": "b"}, "parameter is required unless mode is a"},
+		{"required_unless:mode", map[string]string{}, "parameter is required when mode is not sent"},
+		{"forbidden_if:mode=a", map[string]string{"mode": "a", "p": "1"}, "parameter is not allowed when mode is a"},
+		{"forbidden_unless:mode", map[string]string{"p": "1"}, "parameter is only allowed when mode is sent"},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(tt.values, map[string]string{"p": tt.rule, "mode": "string"})
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%q with %v: errors %+v, want %q", tt.rule, tt.values, errs, tt.want)
+		}
+	}
+}
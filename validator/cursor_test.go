@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPage struct {
+	AfterID int64
+}
+
+func testCursorCodec(t *testing.T, primary string, maxAge time.Duration) *CursorCodec {
+	t.Helper()
+	codec, err := NewCursorCodec(map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 16),
+		"k2": bytes.Repeat([]byte{2}, 32),
+	}, primary, maxAge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codec
+}
+
+func TestCursorCodecDecode(t *testing.T) {
+	old, codec := testCursorCodec(t, "k1", time.Hour), testCursorCodec(t, "k2", time.Hour)
+	encode := func(c *CursorCodec, name string, cursor any, issued time.Time) string {
+		v, err := c.encodeAt(name, cursor, issued)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	now := time.Now()
+	valid := encode(codec, "orders", testPage{AfterID: 42}, now)
+	id, rest, _ := strings.Cut(valid, ".")
+	issued, rest, _ := strings.Cut(rest, ".")
+	payload, mac, _ := strings.Cut(rest, ".")
+
+	tests := []struct {
+		name   string
+		value  string
+		wantID int64
+	}{
+		{"valid", valid, 42},
+		{"rotated key", encode(old, "orders", testPage{AfterID: 7}, now), 7},
+		{"other endpoint", encode(codec, "users", testPage{AfterID: 42}, now), 0},
+		{"expired", encode(codec, "orders", testPage{AfterID: 42}, now.Add(-2*time.Hour)), 0},
+		{"edited issue time", strings.Join([]string{id, "9" + issued, payload, mac}, "."), 0},
+		{"edited payload", strings.Join([]string{id, issued, "e30", mac}, "."), 0},
+		{"unknown key", strings.Join([]string{"k9", issued, payload, mac}, "."), 0},
+		{"missing issue time", strings.Join([]string{id, payload, mac}, "."), 0},
+		{"other fields", encode(codec, "orders", map[string]int{"Offset": 3}, now), 0},
+		{"garbage", "not-a-cursor", 0},
+	}
+	for _, tt := range tests {
+		var page testPage
+		err := codec.Decode("orders", tt.value, &page)
+		if (err == nil) != (tt.wantID != 0) || page.AfterID != tt.wantID {
+			t.Errorf("%s: Decode = %+v, %v, want AfterID %d", tt.name, page, err, tt.wantID)
+		}
+	}
+}
+
+func TestCursorCodecNoMaxAge(t *testing.T) {
+	codec := testCursorCodec(t, "k1", 0)
+	v, err := codec.encodeAt("orders", testPage{AfterID: 1}, time.Now().Add(-24*365*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page testPage
+	if err := codec.Decode("orders", v, &page); err != nil || page.AfterID != 1 {
+		t.Errorf("Decode = %+v, %v, want an old cursor to stay valid", page, err)
+	}
+}
+
+func TestNewCursorCodecErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    map[string][]byte
+		primary string
+		maxAge  time.Duration
+	}{
+		{"missing primary", map[string][]byte{"k1": make([]byte, 16)}, "k2", 0},
+		{"dotted id", map[string][]byte{"k.1": make([]byte, 16)}, "k.1", 0},
+		{"short key", map[string][]byte{"k1": make([]byte, 8)}, "k1", 0},
+		{"negative max age", map[string][]byte{"k1": make([]byte, 16)}, "k1", -time.Second},
+	}
+	for _, tt := range tests {
+		if _, err := NewCursorCodec(tt.keys, tt.primary, tt.maxAge); err == nil {
+			t.Errorf("%s: NewCursorCodec returned no error", tt.name)
+		}
+	}
+}
+
+func TestCursorRule(t *testing.T) {
+	codec := testCursorCodec(t, "k1", time.Hour)
+	after := Cursor[testPage]("orders", codec)
+	qv := NewQueryValidator()
+	qv.AddCursorCodec("orders", codec)
+	qv.AddCursorCodec("users", codec)
+	rules := map[string]string{"after": after.String()}
+
+	orders, err := codec.Encode("orders", testPage{AfterID: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := codec.Encode("users", testPage{AfterID: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCodes(t, qv, map[string]string{"after": orders}, rules)
+	checkCodes(t, qv, map[string]string{"after": users}, rules, "after:INVALID_CURSOR")
+	checkCodes(t, qv, map[string]string{"after": "forged"}, rules, "after:INVALID_CURSOR")
+
+	page, err := after.Parse(orders)
+	if err != nil || page.AfterID != 9 {
+		t.Errorf("Parse = %+v, %v, want AfterID 9", page, err)
+	}
+}
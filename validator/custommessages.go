@@ -0,0 +1,40 @@
+package validator
+
+// SetMessage sets the message of param failing the named rule term, which
+// is a type such as "number", a constraint such as "min", or "required".
+// An empty term covers every failure of param without a message of its
+// own. The template may use {param}, {value} and {constraint}, as with
+// SetConstraintMessage, which it takes precedence over:
+//
+//	qv.SetMessage("age", "min", "age must be at least {constraint}")
+//	qv.SetMessage("age", "", "age must be a whole number between 18 and 120")
+func (qv *QueryValidator) SetMessage(param, term, template string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	if qv.paramMessages[param] == nil {
+		qv.paramMessages[param] = make(map[string]string)
+	}
+	qv.paramMessages[param][term] = template
+	registerTemplate(template)
+}
+
+// applyMessages renders errors with the templates set with SetMessage.
+func (qv *QueryValidator) applyMessages(errors []QueryValidationError) {
+	if len(qv.paramMessages) == 0 {
+		return
+	}
+	for i := range errors {
+		templates, ok := qv.paramMessages[errors[i].Parameter]
+		if !ok || errors[i].reason.key == "" {
+			continue
+		}
+		tmpl, ok := templates[errors[i].reason.term]
+		if !ok {
+			if tmpl, ok = templates[""]; !ok {
+				continue
+			}
+		}
+		errors[i].reason.template = tmpl
+		errors[i].Message = renderTemplate(tmpl, errors[i].Parameter, errors[i].Value, errors[i].reason)
+	}
+}
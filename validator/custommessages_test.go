@@ -0,0 +1,40 @@
+package validator
+
+import "testing"
+
+func TestSetMessage(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetConstraintMessage("min", "at least {constraint} please")
+	qv.SetMessage("age", "min", "{param} must be at least {constraint}, not {value}")
+	qv.SetMessage("age", "number", "{param} must be a {constraint}")
+	qv.SetMessage("age", "required", "tell us your age")
+	qv.SetMessage("size", "", "size must be between 1 and 10")
+	rules := map[string]string{
+		"age":    "required AND number AND min:18 AND max:120",
+		"size":   "integer AND min:1 AND max:10",
+		"weight": "number AND min:1",
+	}
+	tests := []struct {
+		values map[string]string
+		want   string
+	}{
+		{map[string]string{"age": "17"}, "age must be at least 18, not 17"},
+		{map[string]string{"age": "old"}, "age must be a number"},
+		{map[string]string{}, "tell us your age"},
+		{map[string]string{"age": "121"}, "value must be at most 120"},
+		{map[string]string{"age": "30", "size": "0"}, "size must be between 1 and 10"},
+		{map[string]string{"age": "30", "size": "x"}, "size must be between 1 and 10"},
+		{map[string]string{"age": "30", "weight": "0"}, "at least 1 please"},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(tt.values, rules)
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%v: errors %+v, want message %q", tt.values, errs, tt.want)
+		}
+	}
+
+	errs := qv.ValidateMap(map[string]string{"age": "17"}, rules)
+	if len(errs) != 1 || errs[0].Code != MsgTooSmall {
+		t.Errorf("custom message changed the code: %+v", errs)
+	}
+}
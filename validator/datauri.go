@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/url"
+	"strings"
+)
+
+// DataURIOptions bounds the data: URIs accepted by a datauri validator.
+type DataURIOptions struct {
+	// AllowedMIMETypes lists accepted media types. Entries may use a
+	// wildcard subtype such as "image/*". Empty means any type.
+	AllowedMIMETypes []string
+	// MaxDecodedSize caps the decoded payload in bytes. Zero means no limit.
+	MaxDecodedSize int
+}
+
+// DefaultDataURIOptions is used for the built-in "datauri" type.
+var DefaultDataURIOptions = DataURIOptions{
+	AllowedMIMETypes: []string{"image/png", "image/jpeg", "image/gif", "image/webp"},
+	MaxDecodedSize:   16 << 10,
+}
+
+// NewDataURIValidator returns a type validator accepting RFC 2397 data URIs
+// whose media type and decoded size satisfy opts.
+func NewDataURIValidator(opts DataURIOptions) func(string) bool {
+	return func(v string) bool {
+		if len(v) < 5 || !strings.EqualFold(v[:5], "data:") {
+			return false
+		}
+		header, payload, ok := strings.Cut(v[5:], ",")
+		if !ok {
+			return false
+		}
+
+		isBase64 := false
+		if h, found := strings.CutSuffix(header, ";base64"); found {
+			header, isBase64 = h, true
+		}
+		mediaType := "text/plain"
+		if header != "" && !strings.HasPrefix(header, ";") {
+			mt, _, err := mime.ParseMediaType(header)
+			if err != nil {
+				return false
+			}
+			mediaType = mt
+		}
+		if !dataURIMIMEAllowed(mediaType, opts.AllowedMIMETypes) {
+			return false
+		}
+
+		return dataURIPayloadValid(payload, isBase64, opts.MaxDecodedSize)
+	}
+}
+
+func dataURIMIMEAllowed(mediaType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if a == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// dataURIPayloadValid reports whether payload decodes to at most max bytes,
+// judging the size from the encoded length before decoding anything, so
+// oversized payloads cost no allocation.
+func dataURIPayloadValid(payload string, isBase64 bool, max int) bool {
+	if !isBase64 {
+		// Each byte takes one to three characters, as in %41.
+		if max > 0 && len(payload)/3 > max {
+			return false
+		}
+		decoded, err := url.PathUnescape(payload)
+		return err == nil && (max <= 0 || len(decoded) <= max)
+	}
+
+	enc := base64.StdEncoding
+	if !strings.HasSuffix(payload, "=") && len(payload)%4 != 0 {
+		enc = base64.RawStdEncoding
+	}
+	// Padding adds no bytes, so the unpadded length gives the exact size.
+	if max > 0 && base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(payload, "="))) > max {
+		return false
+	}
+	_, err := enc.DecodeString(payload)
+	return err == nil
+}
@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestDataURIValidator(t *testing.T) {
+	images := NewDataURIValidator(DefaultDataURIOptions)
+	anyType := NewDataURIValidator(DataURIOptions{AllowedMIMETypes: []string{"text/*"}, MaxDecodedSize: 5})
+	big := base64.StdEncoding.EncodeToString(make([]byte, 16<<10+1))
+	limit := base64.StdEncoding.EncodeToString(make([]byte, 16<<10))
+	limitRaw := base64.RawStdEncoding.EncodeToString(make([]byte, 16<<10-1))
+	tests := []struct {
+		value       string
+		image, text bool
+	}{
+		{"data:image/png;base64,iVBORw0KGgo=", true, false},
+		{"DATA:image/jpeg;base64,/9j/4A", true, false},
+		{"data:image/png;base64," + big, false, false},
+		{"data:image/png;base64," + limit, true, false},
+		{"data:image/png;base64," + limitRaw, true, false},
+		{"data:text/plain;base64,aGVsbG8=", false, true},
+		{"data:text/plain;base64,aGVsbG8h", false, false},
+		{"data:text/plain," + strings.Repeat("%41", 6), false, false},
+		{"data:image/png;base64,%%%", false, false},
+		{"data:,hello", false, true},
+		{"data:;charset=utf-8,hi%20x", false, true},
+		{"data:text/html,<b>toolong</b>", false, false},
+		{"data:text/csv,a%2Cb", false, true},
+		{"data:application/pdf;base64,JVBERi0=", false, false},
+		{"data:image/png;base64", false, false},
+		{"http://example.com/a.png", false, false},
+		{"data:text/plain,%zz", false, false},
+	}
+	for _, tt := range tests {
+		name := tt.value
+		if len(name) > 40 {
+			name = name[:40] + "..."
+		}
+		if got := images(tt.value); got != tt.image {
+			t.Errorf("image data URI %s = %v, want %v", name, got, tt.image)
+		}
+		if got := anyType(tt.value); got != tt.text {
+			t.Errorf("text data URI %s = %v, want %v", name, got, tt.text)
+		}
+	}
+}
+
+func TestDataURIType(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"avatar": "datauri"}
+	checkCodes(t, qv, map[string]string{"avatar": "data:image/gif;base64,R0lGODlh"}, rules)
+	checkCodes(t, qv, map[string]string{"avatar": "data:text/plain," + strings.Repeat("x", 3)}, rules, "avatar:INVALID_TYPE")
+}
+
+func TestDataURISizeCheckedBeforeDecoding(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable under the race detector")
+	}
+	valid := NewDataURIValidator(DataURIOptions{MaxDecodedSize: 1 << 10})
+	huge := "data:;base64," + base64.StdEncoding.EncodeToString(make([]byte, 1<<20))
+	if n := testing.AllocsPerRun(10, func() {
+		if valid(huge) {
+			t.Fatal("oversized payload accepted")
+		}
+	}); n != 0 {
+		t.Errorf("oversized payload allocates %v times, want 0", n)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build decimal
+
+package validator
+
+import "github.com/shopspring/decimal"
+
+// Decimal builds a rule for exact decimal numbers such as money amounts,
+// producing decimal.Decimal values so no precision is lost to float64:
+//
+//	price := validator.Decimal(validator.Scale(2), validator.Min(0))
+//
+// It is only available when building with the decimal tag, keeping the
+// shopspring/decimal dependency optional.
+func Decimal(opts ...RuleOption) TypedRule[decimal.Decimal] {
+	return TypedRule[decimal.Decimal]{
+		rule:  buildRule("number", opts),
+		parse: decimal.NewFromString,
+	}
+}
@@ -0,0 +1,37 @@
+//go:build decimal
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDecimal(t *testing.T) {
+	price := Decimal(Scale(2), Min(0))
+	if got, want := price.String(), "number AND scale:2 AND min:0"; got != want {
+		t.Errorf("rule %q, want %q", got, want)
+	}
+	qv := NewQueryValidator()
+	rules := map[string]string{"price": price.String()}
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"19.99", nil},
+		{"0", nil},
+		{"19.999", []string{"price:CONSTRAINT_FAILED"}},
+		{"-1", []string{"price:TOO_SMALL"}},
+		{"ten", []string{"price:INVALID_TYPE"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{"price": tt.value}, rules, tt.want...)
+	}
+
+	big := "12345678901234567890.12"
+	v, ok := price.Value(map[string]string{"price": big}, "price")
+	if !ok || !v.Equal(decimal.RequireFromString(big)) || v.String() != big {
+		t.Errorf("Value = %v, %v, want %s exactly", v, ok, big)
+	}
+}
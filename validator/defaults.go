@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DefaultFunc computes a default for an absent parameter from the parameters
+// the client did send. An empty result leaves the parameter absent.
+type DefaultFunc func(values map[string]string) string
+
+// SetDefault registers the default used when param is missing from a query
+// whose rules declare it.
+func (qv *QueryValidator) SetDefault(param string, fn DefaultFunc) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.defaults[param] = fn
+}
+
+// StaticDefault always yields value.
+func StaticDefault(value string) DefaultFunc {
+	return func(map[string]string) string { return value }
+}
+
+// DefaultIfPresent yields then when other was sent and otherwise when it was not,
+// e.g. sort defaults to "relevance" only for searches.
+func DefaultIfPresent(other, then, otherwise string) DefaultFunc {
+	return func(values map[string]string) string {
+		if _, ok := values[other]; ok {
+			return then
+		}
+		return otherwise
+	}
+}
+
+// resolveDefaults fills absent parameters rules declares into values and
+// returns the ones it added. Every DefaultFunc sees only what the client
+// sent, so results do not depend on evaluation order.
+func (qv *QueryValidator) resolveDefaults(values, rules map[string]string) map[string]string {
+	if len(qv.defaults) == 0 {
+		return nil
+	}
+	sent := maps.Clone(values)
+	added := make(map[string]string)
+	for param, fn := range qv.defaults {
+		if _, ok := sent[param]; ok {
+			continue
+		}
+		// Defaults of other routes' parameters would be unexpected here.
+		if _, declared := rules[param]; !declared {
+			continue
+		}
+		if value := qv.safeDefault(param, fn, sent); value != "" {
+			values[param] = value
+			added[param] = value
+		}
+	}
+	return added
+}
+
+// fallbackTerm is the rule term that replaces an invalid value with the
+// parameter's default instead of rejecting it, as in "number AND fallback".
+// Parameters without a default are rejected as usual.
+const fallbackTerm = "fallback"
+
+// fallBack replaces the invalid values of falling-back parameters with their
+// defaults. It returns a warning for each replaced value and the new values.
+func (qv *QueryValidator) fallBack(ctx context.Context, values, rules map[string]string) ([]QueryValidationError, map[string]string) {
+	var warnings []QueryValidationError
+	var replaced map[string]string
+	sent := values
+	for param, value := range values {
+		rule, ok := rules[param]
+		if !ok || !strings.Contains(rule, fallbackTerm) || !slices.Contains(ruleTerms(rule), fallbackTerm) {
+			continue
+		}
+		fn, ok := qv.defaults[param]
+		if !ok {
+			continue
+		}
+		if _, valid := qv.checkParamValue(ctx, value, rule); valid {
+			continue
+		}
+		if replaced == nil {
+			// Defaults see what the client sent, not earlier replacements.
+			sent = maps.Clone(values)
+			replaced = make(map[string]string)
+		}
+		fallback := qv.safeDefault(param, fn, sent)
+		if fallback == "" {
+			continue
+		}
+		warning := newValidationError(param, strings.Clone(value), fail(MsgFellBack, fallback))
+		warning.Severity = SeverityWarning
+		warnings = append(warnings, warning)
+		values[param], replaced[param] = fallback, fallback
+	}
+	return warnings, replaced
+}
@@ -0,0 +1,132 @@
+package validator
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDefault("sort", DefaultIfPresent("q", "relevance", "created_at"))
+	qv.SetDefault("limit", StaticDefault("20"))
+	qv.SetDefault("cursor", StaticDefault(""))
+	rules := map[string]string{
+		"q":      "string",
+		"sort":   "in:relevance,created_at,name",
+		"limit":  "integer",
+		"cursor": "string",
+	}
+	tests := []struct {
+		sent map[string]string
+		want map[string]string
+	}{
+		{
+			map[string]string{"q": "shoes"},
+			map[string]string{"q": "shoes", "sort": "relevance", "limit": "20"},
+		},
+		{
+			map[string]string{},
+			map[string]string{"sort": "created_at", "limit": "20"},
+		},
+		{
+			map[string]string{"sort": "name", "limit": "5"},
+			map[string]string{"sort": "name", "limit": "5"},
+		},
+	}
+	for _, tt := range tests {
+		values := maps.Clone(tt.sent)
+		if errs := qv.ValidateMap(values, rules); len(errs) > 0 {
+			t.Errorf("%v: errors %v", tt.sent, errorCodes(errs))
+		}
+		if !maps.Equal(values, tt.want) {
+			t.Errorf("%v: values %v, want %v", tt.sent, values, tt.want)
+		}
+	}
+}
+
+func TestDefaultsSeeOnlySentValues(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDefault("a", StaticDefault("1"))
+	qv.SetDefault("b", DefaultIfPresent("a", "a-sent", "a-absent"))
+	for range 20 {
+		values := map[string]string{}
+		qv.ValidateMap(values, map[string]string{"a": "string", "b": "string"})
+		if values["b"] != "a-absent" {
+			t.Fatalf("b = %q, want a-absent: defaults must not see other defaults", values["b"])
+		}
+	}
+}
+
+func TestFallback(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDefault("limit", StaticDefault("20"))
+	rules := map[string]string{"limit": "integer AND fallback", "page": "integer AND fallback"}
+
+	values := map[string]string{"limit": "lots"}
+	errs := qv.ValidateMap(values, rules)
+	if got := errorCodes(errs); len(got) != 1 || got[0] != "limit:FELL_BACK" || errs[0].Severity != SeverityWarning {
+		t.Errorf("errors %v, want a limit:FELL_BACK warning", errs)
+	}
+	if values["limit"] != "20" {
+		t.Errorf("limit = %q, want the default 20", values["limit"])
+	}
+
+	checkCodes(t, qv, map[string]string{"page": "x"}, rules, "page:INVALID_TYPE")
+	checkCodes(t, qv, map[string]string{"limit": "7"}, rules)
+}
+
+func TestDefaultsOnlyForDeclaredParams(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDefault("region", StaticDefault("eu"))
+	values := map[string]string{"q": "a"}
+	checkCodes(t, qv, values, map[string]string{"q": "string"})
+	if _, ok := values["region"]; ok {
+		t.Errorf("default filled for a parameter the rules do not declare: %v", values)
+	}
+	values = map[string]string{"q": "a"}
+	checkCodes(t, qv, values, map[string]string{"q": "string", "region": "in:eu,us"})
+	if values["region"] != "eu" {
+		t.Errorf("region = %q, want the default eu", values["region"])
+	}
+}
+
+func TestFallbackCases(t *testing.T) {
+	// sort falls back to the order the client's mode implies.
+	bySent := func(values map[string]string) string {
+		if values["mode"] == "recent" {
+			return "date"
+		}
+		return "name"
+	}
+	tests := []struct {
+		name   string
+		rule   string
+		values map[string]string
+		want   []string
+		sort   string
+	}{
+		{"valid value kept", "in:name,date AND fallback", map[string]string{"sort": "date"}, nil, "date"},
+		{"invalid value replaced", "in:name,date AND fallback", map[string]string{"sort": "size"}, []string{"sort:FELL_BACK"}, "name"},
+		{"default sees the query", "in:name,date AND fallback", map[string]string{"sort": "size", "mode": "recent"}, []string{"sort:FELL_BACK"}, "date"},
+		{"pipe chain", "in:name,date|fallback", map[string]string{"sort": "size"}, []string{"sort:FELL_BACK"}, "name"},
+		{"no fallback term", "in:name,date", map[string]string{"sort": "size"}, []string{"sort:NOT_ALLOWED"}, "size"},
+		{"typed rule", String(NotIn("size"), Fallback()).String(), map[string]string{"sort": "size"}, []string{"sort:FELL_BACK"}, "name"},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		qv.SetDefault("sort", bySent)
+		errors := qv.ValidateMap(tt.values, map[string]string{"sort": tt.rule, "mode": "string"})
+		if got := errorCodes(errors); !slices.Equal(got, tt.want) || tt.values["sort"] != tt.sort {
+			t.Errorf("%s: errors %v, sort %q, want %v, %q", tt.name, got, tt.values["sort"], tt.want, tt.sort)
+		}
+	}
+
+	qv := NewQueryValidator()
+	qv.SetDefault("sort", StaticDefault("name"))
+	if got := errorMessage(t, qv, "sort", "size", "in:name,date AND fallback"); got != "invalid value was replaced with the default name" {
+		t.Errorf("message %q", got)
+	}
+	qv.SetDefault("sort", StaticDefault(""))
+	checkCodes(t, qv, map[string]string{"sort": "size"}, map[string]string{"sort": "in:name,date AND fallback"}, "sort:NOT_ALLOWED")
+}
@@ -0,0 +1,41 @@
+package validator
+
+// SetDescription attaches a human description to param, e.g. "Filters users
+// created after this date". Descriptions feed generated API docs and, when
+// enabled with SetErrorDescriptions, the description field of errors.
+func (qv *QueryValidator) SetDescription(param, description string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.descriptions[param] = description
+}
+
+// Description returns the description registered for param, if any.
+func (qv *QueryValidator) Description(param string) string {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	return qv.descriptions[param]
+}
+
+// SetErrorDescriptions controls whether errors carry the description of the
+// offending parameter.
+func (qv *QueryValidator) SetErrorDescriptions(enabled bool) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.errorDescriptions = enabled
+}
+
+// finishErrors annotates errors before they are returned.
+func (qv *QueryValidator) finishErrors(errors []QueryValidationError) {
+	qv.applyMessages(errors)
+	qv.describeErrors(errors)
+	qv.applySeverities(errors)
+}
+
+func (qv *QueryValidator) describeErrors(errors []QueryValidationError) {
+	if !qv.errorDescriptions {
+		return
+	}
+	for i := range errors {
+		errors[i].Description = qv.descriptions[errors[i].Parameter]
+	}
+}
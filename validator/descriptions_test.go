@@ -0,0 +1,47 @@
+package validator
+
+import "testing"
+
+func TestDescriptions(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDescription("created_after", "Filters users created after this date")
+	if got := qv.Description("created_after"); got != "Filters users created after this date" {
+		t.Errorf("Description = %q", got)
+	}
+	if got := qv.Description("q"); got != "" {
+		t.Errorf("Description of an undescribed parameter = %q", got)
+	}
+
+	values := map[string]string{"created_after": "yesterday", "q": ""}
+	rules := map[string]string{"created_after": "date", "q": "required"}
+	for _, err := range qv.ValidateMap(values, rules) {
+		if err.Description != "" {
+			t.Errorf("%s: description %q on errors before SetErrorDescriptions", err.Parameter, err.Description)
+		}
+	}
+
+	qv.SetErrorDescriptions(true)
+	want := map[string]string{"created_after": "Filters users created after this date", "q": ""}
+	errs := qv.ValidateMap(map[string]string{"created_after": "yesterday"}, rules)
+	if len(errs) != 2 {
+		t.Fatalf("errors %v, want 2", errorCodes(errs))
+	}
+	for _, err := range errs {
+		if err.Description != want[err.Parameter] {
+			t.Errorf("%s: description %q, want %q", err.Parameter, err.Description, want[err.Parameter])
+		}
+	}
+}
+
+func TestDescriptionsInSchemas(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDescription("created_after", "Filters users created after this date")
+	qv.RegisterRoute("/users", map[string]string{"created_after": "date", "q": "string"})
+	schema, ok := qv.Schema("/users")
+	if !ok || len(schema.Params) != 2 {
+		t.Fatalf("schema %+v, %v", schema, ok)
+	}
+	if schema.Params[0].Description != "Filters users created after this date" || schema.Params[1].Description != "" {
+		t.Errorf("param descriptions %+v", schema.Params)
+	}
+}
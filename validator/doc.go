@@ -0,0 +1,11 @@
+// Package validator validates HTTP query parameters against rule maps.
+//
+// The engine itself (types, constraints, rule expressions, ValidateMap and
+// the offline helpers) depends on no web framework. The Fiber and net/http
+// adapters and the OpenAPI conversions, which need kin-openapi, are left
+// out of TinyGo and WASM builds, so the same rules can run in edge workers
+// and as browser-side preflight checks:
+//
+//	GOOS=js GOARCH=wasm go build ./validator
+//	tinygo build -target wasm ./validator
+package validator
@@ -0,0 +1,167 @@
+package validator
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// decryptTerm marks parameters whose values are encrypted with a key ring,
+// as in "decrypt:sessions AND int:64 AND min:1". The value is decrypted
+// before validation and the rest of the rule checks the plaintext, which
+// handlers then read in place of the blob.
+const decryptTerm = "decrypt"
+
+// KeyRing holds the AES-GCM keys encrypted parameters are sealed with, by
+// key id. Values are the id of their key, a dot, and the base64url nonce and
+// ciphertext, so keys can be rotated while blobs sealed with older ones
+// still open.
+type KeyRing struct {
+	aeads   map[string]cipher.AEAD
+	primary string
+}
+
+// NewKeyRing builds a key ring from 16, 24 or 32 byte AES keys. Encrypt
+// seals with the key named primary.
+func NewKeyRing(keys map[string][]byte, primary string) (*KeyRing, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("no key %q", primary)
+	}
+	ring := &KeyRing{aeads: make(map[string]cipher.AEAD, len(keys)), primary: primary}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ".") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %v", id, err)
+		}
+		ring.aeads[id] = aead
+	}
+	return ring, nil
+}
+
+// Encrypt seals plaintext as a value of param with the primary key, e.g. to
+// issue the values clients later send back. The blob only opens as a value
+// of param, so it cannot be replayed into another parameter sharing the ring.
+func (r *KeyRing) Encrypt(param, plaintext string) (string, error) {
+	aead := r.aeads[r.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), additionalData(r.primary, param))
+	return r.primary + "." + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+var errUndecryptable = errors.New("value could not be decrypted")
+
+// Decrypt opens a value of param sealed by Encrypt with any key of the ring.
+func (r *KeyRing) Decrypt(param, value string) (string, error) {
+	id, blob, ok := strings.Cut(value, ".")
+	aead, known := r.aeads[id]
+	if !ok || !known {
+		return "", errUndecryptable
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(blob)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errUndecryptable
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(id, param))
+	if err != nil {
+		return "", errUndecryptable
+	}
+	return string(plaintext), nil
+}
+
+// additionalData binds a blob to its key and parameter. Key ids hold no
+// dots, so the two cannot run into each other.
+func additionalData(id, param string) []byte {
+	return []byte(id + "." + param)
+}
+
+// AddKeyRing registers ring for decrypt:name rule terms.
+func (qv *QueryValidator) AddKeyRing(name string, ring *KeyRing) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.keyRings[name] = ring
+}
+
+// decryptConstraint checks the ring exists; decryptValues does the work.
+func (qv *QueryValidator) decryptConstraint(arg string) (func(string) bool, error) {
+	if _, ok := qv.keyRings[arg]; !ok {
+		return nil, fmt.Errorf("unknown key ring %q", arg)
+	}
+	return func(string) bool { return true }, nil
+}
+
+// decryptValues replaces the values of decrypting rules with their
+// plaintext. Rules are looked up as validateParam does, so parameters
+// declared by wildcard or bracket keys decrypt too once run has expanded
+// them. It returns an error for each value that does not open, the
+// decrypted values, and the blobs that were sent, by parameter.
+func (qv *QueryValidator) decryptValues(values, rules map[string]string, req validationRequest) (errors []QueryValidationError, decrypted, sealed map[string]string) {
+	for param, value := range values {
+		rule, ok := qv.declaredRule(rules, req, param)
+		if !ok || !strings.Contains(rule, decryptTerm) {
+			continue
+		}
+		ring := qv.ruleKeyRing(rule)
+		if ring == nil {
+			continue
+		}
+		if sealed == nil {
+			decrypted, sealed = make(map[string]string), make(map[string]string)
+		}
+		// Adapters write the plaintext back into buffers value may alias.
+		sealed[param] = strings.Clone(value)
+		plaintext, err := ring.Decrypt(param, value)
+		if err != nil {
+			errors = append(errors, newValidationError(param, sealed[param], failTerm(decryptTerm, MsgUndecryptable)))
+			continue
+		}
+		values[param], decrypted[param] = plaintext, plaintext
+	}
+	return errors, decrypted, sealed
+}
+
+func (qv *QueryValidator) ruleKeyRing(rule string) *KeyRing {
+	for _, term := range ruleTerms(rule) {
+		if name, arg, _ := strings.Cut(term, ":"); name == decryptTerm {
+			return qv.keyRings[arg]
+		}
+	}
+	return nil
+}
+
+// sealErrors reports errors of encrypted parameters with the blob that was
+// sent rather than its plaintext, and drops those of blobs that did not
+// open, whose only error is the decryption failure.
+func sealErrors(errors []QueryValidationError, decrypted, sealed map[string]string) []QueryValidationError {
+	if len(sealed) == 0 {
+		return errors
+	}
+	kept := errors[:0]
+	for _, err := range errors {
+		blob, isSealed := sealed[err.Parameter]
+		if !isSealed {
+			kept = append(kept, err)
+			continue
+		}
+		if _, opened := decrypted[err.Parameter]; !opened {
+			continue
+		}
+		err.Value = blob
+		kept = append(kept, err)
+	}
+	return kept
+}
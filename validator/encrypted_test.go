@@ -0,0 +1,165 @@
+package validator
+
+import (
+	"bytes"
+	"maps"
+	"testing"
+)
+
+func testKeyRing(t *testing.T, primary string) *KeyRing {
+	t.Helper()
+	ring, err := NewKeyRing(map[string][]byte{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 16),
+	}, primary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring
+}
+
+func TestKeyRingRoundTrip(t *testing.T) {
+	old, current := testKeyRing(t, "k1"), testKeyRing(t, "k2")
+	sealedOld, err := old.Encrypt("account", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := current.Encrypt("account", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := []byte(sealed)
+	tampered[len(tampered)-1] ^= 'A' ^ 'B'
+
+	tests := []struct {
+		name   string
+		param  string
+		value  string
+		want   string
+		opened bool
+	}{
+		{"current key", "account", sealed, "42", true},
+		{"rotated key", "account", sealedOld, "42", true},
+		{"other parameter", "user", sealed, "", false},
+		{"tampered", "account", string(tampered), "", false},
+		{"unknown key", "account", "k9." + sealed[3:], "", false},
+		{"no key id", "account", "plain", "", false},
+		{"bad base64", "account", "k1.!!!", "", false},
+	}
+	for _, tt := range tests {
+		got, err := current.Decrypt(tt.param, tt.value)
+		if (err == nil) != tt.opened || got != tt.want {
+			t.Errorf("%s: Decrypt = %q, %v, want %q, opened %v", tt.name, got, err, tt.want, tt.opened)
+		}
+	}
+}
+
+func TestNewKeyRingErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    map[string][]byte
+		primary string
+	}{
+		{"missing primary", map[string][]byte{"k1": make([]byte, 16)}, "k2"},
+		{"dotted id", map[string][]byte{"k.1": make([]byte, 16)}, "k.1"},
+		{"short key", map[string][]byte{"k1": make([]byte, 10)}, "k1"},
+	}
+	for _, tt := range tests {
+		if _, err := NewKeyRing(tt.keys, tt.primary); err == nil {
+			t.Errorf("%s: NewKeyRing returned no error", tt.name)
+		}
+	}
+}
+
+func TestDecryptRule(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	qv := NewQueryValidator()
+	qv.AddKeyRing("ids", ring)
+	seal := func(param, plaintext string) string {
+		v, err := ring.Encrypt(param, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	rules := map[string]string{
+		"account": "decrypt:ids AND int:64 AND min:1",
+		"user":    "decrypt:ids AND int:64",
+	}
+	replayed := seal("account", "7")
+
+	tests := []struct {
+		values map[string]string
+		want   []string
+	}{
+		{map[string]string{"account": seal("account", "7")}, nil},
+		{map[string]string{"account": seal("account", "0")}, []string{"account:TOO_SMALL"}},
+		{map[string]string{"user": replayed}, []string{"user:UNDECRYPTABLE"}},
+		{map[string]string{"account": "7"}, []string{"account:UNDECRYPTABLE"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, tt.values, rules, tt.want...)
+	}
+
+	blob := seal("account", "0")
+	errors := qv.ValidateMap(map[string]string{"account": blob}, rules)
+	if len(errors) != 1 || errors[0].Value != blob {
+		t.Errorf("errors %+v, want one reporting the sealed value", errors)
+	}
+}
+
+func TestDecryptWildcardKeys(t *testing.T) {
+	ring := testKeyRing(t, "k1")
+	qv := NewQueryValidator()
+	qv.AddKeyRing("ids", ring)
+	seal := func(param, plaintext string) string {
+		v, err := ring.Encrypt(param, plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	rules := map[string]string{
+		"token_*":   "decrypt:ids AND int:64 AND min:1",
+		"filter[*]": "decrypt:ids AND in:open,closed",
+	}
+	tests := []struct {
+		values map[string]string
+		want   []string
+	}{
+		{map[string]string{"token_a": seal("token_a", "7"), "filter[status]": seal("filter[status]", "open")}, nil},
+		{map[string]string{"token_a": seal("token_a", "0")}, []string{"token_a:TOO_SMALL"}},
+		{map[string]string{"filter[status]": seal("filter[status]", "lost")}, []string{"filter[status]:NOT_ALLOWED"}},
+		{map[string]string{"token_b": seal("token_a", "7")}, []string{"token_b:UNDECRYPTABLE"}},
+		{map[string]string{"token_a": "7"}, []string{"token_a:UNDECRYPTABLE"}},
+	}
+	for _, tt := range tests {
+		values := maps.Clone(tt.values)
+		checkCodes(t, qv, values, rules, tt.want...)
+	}
+
+	values := map[string]string{"token_a": seal("token_a", "42")}
+	if errs := qv.ValidateMap(values, rules); len(errs) != 0 || values["token_a"] != "42" {
+		t.Errorf("token_a = %q, errors %v, want the plaintext 42", values["token_a"], errorCodes(errs))
+	}
+
+	// Compiled sets look rules up in their own table.
+	rules["account"] = "decrypt:ids AND int:64"
+	for _, set := range []map[string]string{rules, {"account": rules["account"]}} {
+		compiled, err := qv.CompileRules(set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := map[string]string{"account": seal("account", "9")}
+		if _, ok := set["token_*"]; ok {
+			values["token_a"] = seal("token_a", "0")
+		}
+		errs := compiled.ValidateMap(values)
+		if values["account"] != "9" {
+			t.Errorf("compiled %v: account = %q, want the plaintext 9", set, values["account"])
+		}
+		if _, ok := set["token_*"]; ok && (len(errs) != 1 || errs[0].Code != MsgTooSmall) {
+			t.Errorf("compiled %v: errors %v, want token_a:TOO_SMALL", set, errorCodes(errs))
+		}
+	}
+}
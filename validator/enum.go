@@ -0,0 +1,130 @@
+package validator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnumProvider supplies the allowed values of an enum, e.g. from a database
+// table, a config file or a remote service.
+type EnumProvider interface {
+	EnumValues(ctx context.Context) ([]string, error)
+}
+
+// EnumProviderFunc adapts a function to EnumProvider.
+type EnumProviderFunc func(ctx context.Context) ([]string, error)
+
+func (f EnumProviderFunc) EnumValues(ctx context.Context) ([]string, error) {
+	return f(ctx)
+}
+
+// EnumFile provides the non-empty lines of a file, skipping # comments. The
+// file is re-read on every refresh.
+func EnumFile(path string) EnumProvider {
+	return EnumProviderFunc(func(context.Context) ([]string, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		var values []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				values = append(values, line)
+			}
+		}
+		return values, scanner.Err()
+	})
+}
+
+// EnumOptions controls how an enum's values are cached.
+type EnumOptions struct {
+	// Refresh is how long loaded values are used before they are reloaded in
+	// the background. Zero loads them once.
+	Refresh time.Duration
+	// Timeout bounds each load. Zero means 5 seconds.
+	Timeout time.Duration
+}
+
+// AddEnum registers an enum usable as "enum:name" in rules. Values load on
+// first use; until that load succeeds every value is rejected. Afterwards
+// a failed refresh keeps the previous values, so an outage of the provider
+// does not start rejecting valid requests.
+func (qv *QueryValidator) AddEnum(name string, provider EnumProvider, opts EnumOptions) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	qv.enums[name] = &enumCache{provider: provider, opts: opts}
+}
+
+type enumCache struct {
+	provider EnumProvider
+	opts     EnumOptions
+
+	mu         sync.Mutex
+	values     map[string]struct{}
+	loadedAt   time.Time
+	refreshing bool
+}
+
+func (e *enumCache) contains(value string) bool {
+	e.mu.Lock()
+	if e.values == nil {
+		e.mu.Unlock()
+		e.load()
+		e.mu.Lock()
+	} else if e.opts.Refresh > 0 && !e.refreshing && time.Since(e.loadedAt) > e.opts.Refresh {
+		e.refreshing = true
+		go e.load()
+	}
+	_, ok := e.values[value]
+	e.mu.Unlock()
+	return ok
+}
+
+// list returns the loaded values, without loading them.
+func (e *enumCache) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	values := make([]string, 0, len(e.values))
+	for v := range e.values {
+		values = append(values, v)
+	}
+	return values
+}
+
+func (e *enumCache) load() {
+	ctx, cancel := context.WithTimeout(context.Background(), e.opts.Timeout)
+	defer cancel()
+	values, err := e.provider.EnumValues(ctx)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refreshing = false
+	if err != nil {
+		return
+	}
+	set := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		set[v] = struct{}{}
+	}
+	e.values, e.loadedAt = set, time.Now()
+}
+
+func (qv *QueryValidator) enumConstraint(arg string) (func(string) bool, error) {
+	enum, ok := qv.enums[arg]
+	if !ok {
+		return nil, fmt.Errorf("unknown enum %s", arg)
+	}
+	return enum.contains, nil
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// enumSource is an EnumProvider whose values and failures tests set.
+type enumSource struct {
+	mu     sync.Mutex
+	values []string
+	err    error
+	loads  int
+}
+
+func (s *enumSource) EnumValues(context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	return s.values, s.err
+}
+
+func (s *enumSource) set(values []string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values, s.err = values, err
+}
+
+func (s *enumSource) loadCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loads
+}
+
+func TestAddEnum(t *testing.T) {
+	qv := NewQueryValidator()
+	src := &enumSource{err: errors.New("database down")}
+	qv.AddEnum("plans", src, EnumOptions{})
+	rules := map[string]string{"plan": "enum:plans"}
+
+	checkCodes(t, qv, map[string]string{"plan": "pro"}, rules, "plan:NOT_ALLOWED")
+	src.set([]string{"free", "pro"}, nil)
+	checkCodes(t, qv, map[string]string{"plan": "pro"}, rules)
+	loads := src.loadCount()
+	checkCodes(t, qv, map[string]string{"plan": "gold"}, rules, "plan:NOT_ALLOWED")
+	src.set([]string{"gold"}, nil)
+	checkCodes(t, qv, map[string]string{"plan": "pro"}, rules)
+	if n := src.loadCount(); n != loads {
+		t.Errorf("provider loaded %d more times without a refresh interval", n-loads)
+	}
+
+	checkCodes(t, qv, map[string]string{"plan": "pro"}, map[string]string{"plan": "enum:missing"}, "plan:INVALID_RULE")
+}
+
+func TestEnumRefresh(t *testing.T) {
+	qv := NewQueryValidator()
+	src := &enumSource{values: []string{"de", "fr"}}
+	qv.AddEnum("countries", src, EnumOptions{Refresh: 10 * time.Millisecond})
+	rules := map[string]string{"country": "enum:countries"}
+
+	checkCodes(t, qv, map[string]string{"country": "de"}, rules)
+	src.set(nil, errors.New("timeout"))
+	time.Sleep(20 * time.Millisecond)
+	checkCodes(t, qv, map[string]string{"country": "de"}, rules)
+	for src.loadCount() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	checkCodes(t, qv, map[string]string{"country": "de"}, rules)
+
+	src.set([]string{"nl"}, nil)
+	deadline := time.Now().Add(time.Second)
+	for len(qv.ValidateMap(map[string]string{"country": "nl"}, rules)) != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("refreshed values never used")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	checkCodes(t, qv, map[string]string{"country": "de"}, rules, "country:NOT_ALLOWED")
+}
+
+func TestEnumFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plans.txt")
+	if err := os.WriteFile(path, []byte("# plans\nfree\n\n  pro  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	values, err := EnumFile(path).EnumValues(context.Background())
+	if err != nil || len(values) != 2 || values[0] != "free" || values[1] != "pro" {
+		t.Errorf("EnumValues = %q, %v, want free and pro", values, err)
+	}
+	if _, err := EnumFile(filepath.Join(t.TempDir(), "missing")).EnumValues(context.Background()); err == nil {
+		t.Error("missing file gave no error")
+	}
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"encoding/json"
+)
+
+// ErrorFormatter renders the body of a response rejecting a query with
+// status, returning its content type and bytes.
+type ErrorFormatter func(status int, errors []QueryValidationError) (contentType string, body []byte)
+
+// JSONErrorFormatter renders an ErrorResponse, the body described by
+// ErrorSchema. It is the default formatter.
+func JSONErrorFormatter(status int, errors []QueryValidationError) (string, []byte) {
+	body, _ := json.Marshal(NewErrorResponse(errors))
+	return "application/json", body
+}
+
+// ProblemDetails is an RFC 7807 problem with the invalid-params extension,
+// one entry per failed parameter.
+type ProblemDetails struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params"`
+}
+
+// InvalidParam describes a failed parameter of ProblemDetails.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	Code   string `json:"code,omitempty"`
+	Index  *int   `json:"index,omitempty"`
+}
+
+var statusTitles = map[int]string{
+	statusBadRequest: "Bad Request",
+	statusForbidden:  "Forbidden",
+}
+
+// ProblemErrorFormatter returns a formatter rendering application/problem+json
+// ProblemDetails whose type is typeURI, or about:blank when it is empty.
+func ProblemErrorFormatter(typeURI string) ErrorFormatter {
+	if typeURI == "" {
+		typeURI = "about:blank"
+	}
+	return func(status int, errors []QueryValidationError) (string, []byte) {
+		problem := ProblemDetails{
+			Type:          typeURI,
+			Title:         statusTitles[status],
+			Status:        status,
+			Detail:        ErrorSummary(errors),
+			InvalidParams: make([]InvalidParam, len(errors)),
+		}
+		for i, err := range errors {
+			problem.InvalidParams[i] = InvalidParam{Name: err.Parameter, Reason: err.Message, Code: err.Code, Index: err.Index}
+		}
+		body, _ := json.Marshal(problem)
+		return "application/problem+json", body
+	}
+}
+
+// SetErrorFormatter sets the formatter Middleware and FormatErrors use
+// unless configured otherwise.
+func (qv *QueryValidator) SetErrorFormatter(formatter ErrorFormatter) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.errorFormatter = formatter
+}
+
+// FormatErrors renders the response rejecting a query with errors using the
+// formatter from SetErrorFormatter, for handlers outside Middleware:
+//
+//	status, contentType, body := qv.FormatErrors(errors)
+//	w.Header().Set("Content-Type", contentType)
+//	w.WriteHeader(status)
+//	w.Write(body)
+func (qv *QueryValidator) FormatErrors(errors []QueryValidationError) (status int, contentType string, body []byte) {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	return formatErrors(qv.errorFormatter, errors)
+}
+
+func formatErrors(formatter ErrorFormatter, errors []QueryValidationError) (int, string, []byte) {
+	if formatter == nil {
+		formatter = JSONErrorFormatter
+	}
+	status := ErrorStatus(errors)
+	contentType, body := formatter(status, errors)
+	return status, contentType, body
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestProblemErrorFormatter(t *testing.T) {
+	one := 1
+	qv := NewQueryValidator()
+	qv.RequireScope("debug", "admin")
+	rules := map[string]string{"ids": "array:integer", "debug": "boolean"}
+	tests := []struct {
+		name    string
+		typeURI string
+		values  map[string]string
+		status  int
+		title   string
+		want    []InvalidParam
+	}{
+		{"bad request", "https://example.com/problems/query", map[string]string{"ids": "1,x"}, 400, "Bad Request",
+			[]InvalidParam{{Name: "ids", Reason: "invalid value for type integer", Code: MsgInvalidType, Index: &one}}},
+		{"forbidden", "", map[string]string{"debug": "true"}, 403, "Forbidden",
+			[]InvalidParam{{Name: "debug", Reason: "parameter requires scope admin", Code: MsgForbiddenParam}}},
+	}
+	for _, tt := range tests {
+		errors := qv.ValidateMap(tt.values, rules)
+		qv.SetErrorFormatter(ProblemErrorFormatter(tt.typeURI))
+		status, contentType, body := qv.FormatErrors(errors)
+		if status != tt.status || contentType != "application/problem+json" {
+			t.Errorf("%s: status %d, content type %s", tt.name, status, contentType)
+		}
+		var problem ProblemDetails
+		if err := json.Unmarshal(body, &problem); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		wantType := tt.typeURI
+		if wantType == "" {
+			wantType = "about:blank"
+		}
+		if problem.Type != wantType || problem.Title != tt.title || problem.Status != tt.status || problem.Detail != "1 invalid query parameter" {
+			t.Errorf("%s: problem %s", tt.name, body)
+		}
+		if len(problem.InvalidParams) != len(tt.want) {
+			t.Fatalf("%s: invalid-params %+v, want %+v", tt.name, problem.InvalidParams, tt.want)
+		}
+		for i, got := range problem.InvalidParams {
+			want := tt.want[i]
+			if got.Name != want.Name || got.Reason != want.Reason || got.Code != want.Code || (got.Index == nil) != (want.Index == nil) ||
+				got.Index != nil && *got.Index != *want.Index {
+				t.Errorf("%s: invalid param %+v, want %+v", tt.name, got, want)
+			}
+		}
+	}
+}
+
+func TestFormatErrorsDefault(t *testing.T) {
+	qv := NewQueryValidator()
+	errors := qv.ValidateMap(map[string]string{"n": "x"}, map[string]string{"n": "integer"})
+	status, contentType, body := qv.FormatErrors(errors)
+	want, _ := json.Marshal(NewErrorResponse(errors))
+	if status != 400 || contentType != "application/json" || string(body) != string(want) {
+		t.Errorf("FormatErrors = %d, %s, %s, want 400, application/json, %s", status, contentType, body, want)
+	}
+}
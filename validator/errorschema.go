@@ -0,0 +1,34 @@
+package validator
+
+import (
+	_ "embed"
+)
+
+// WellKnownErrorSchemaPath is where ErrorSchemaHandler is conventionally mounted.
+const WellKnownErrorSchemaPath = "/.well-known/query-error-schema"
+
+// ErrorSchemaVersion identifies the error response format described by
+// ErrorSchema. Within a version fields are only ever added, never removed,
+// renamed or given a new meaning, so clients generated from the schema keep
+// working; incompatible changes ship as a new version.
+const ErrorSchemaVersion = "1"
+
+//go:embed error_schema_v1.json
+var errorSchema []byte
+
+// ErrorResponse is the body sent when a query fails validation.
+type ErrorResponse struct {
+	Message string                 `json:"message"`
+	Errors  []QueryValidationError `json:"errors"`
+}
+
+// NewErrorResponse builds the response body for errors.
+func NewErrorResponse(errors []QueryValidationError) ErrorResponse {
+	return ErrorResponse{Message: ErrorSummary(errors), Errors: errors}
+}
+
+// ErrorSchema returns the JSON Schema of ErrorResponse for the current
+// ErrorSchemaVersion. The returned slice must not be modified.
+func ErrorSchema() []byte {
+	return errorSchema
+}
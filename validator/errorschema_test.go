@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// jsonSchema holds the JSON Schema keywords the tests inspect.
+type jsonSchema struct {
+	ID         string                `json:"$id"`
+	Required   []string              `json:"required"`
+	Properties map[string]jsonSchema `json:"properties"`
+	Defs       map[string]jsonSchema `json:"$defs"`
+	Pattern    string                `json:"pattern"`
+	Enum       []string              `json:"enum"`
+}
+
+func TestErrorSchemaDescribesErrors(t *testing.T) {
+	var schema jsonSchema
+	if err := json.Unmarshal(ErrorSchema(), &schema); err != nil {
+		t.Fatalf("error schema is not JSON: %v", err)
+	}
+	if !strings.HasSuffix(schema.ID, "/v"+ErrorSchemaVersion) {
+		t.Errorf("$id %q does not end in /v%s", schema.ID, ErrorSchemaVersion)
+	}
+
+	// Every field the error payload can carry must be declared.
+	errorDef := schema.Defs["error"]
+	typ := reflect.TypeOf(QueryValidationError{})
+	for i := range typ.NumField() {
+		tag := typ.Field(i).Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		if _, ok := errorDef.Properties[name]; !ok {
+			t.Errorf("field %s is missing from the error schema", name)
+		}
+		if opts != "omitempty" && !slices.Contains(errorDef.Required, name) {
+			t.Errorf("always-present field %s is not required by the schema", name)
+		}
+	}
+	if got := errorDef.Properties["severity"].Enum; !slices.Equal(got, []string{string(SeverityError), string(SeverityWarning)}) {
+		t.Errorf("severity enum %v", got)
+	}
+
+	code := regexp.MustCompile(errorDef.Properties["code"].Pattern)
+	for _, key := range append(slices.Collect(maps.Keys(englishMessages)), countedMessages...) {
+		if !code.MatchString(key) {
+			t.Errorf("code %s does not match the schema pattern", key)
+		}
+	}
+}
+
+func TestNewErrorResponse(t *testing.T) {
+	qv := NewQueryValidator()
+	errs := qv.ValidateMap(map[string]string{"age": "x"}, map[string]string{"age": "number", "q": "required"})
+	body, err := json.Marshal(NewErrorResponse(errs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp struct {
+		Message string `json:"message"`
+		Errors  []map[string]any
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != "2 invalid query parameters" || len(resp.Errors) != 2 {
+		t.Errorf("response %s", body)
+	}
+	for _, e := range resp.Errors {
+		for _, field := range []string{"parameter", "value", "message", "code"} {
+			if _, ok := e[field]; !ok {
+				t.Errorf("error %v lacks %s", e, field)
+			}
+		}
+	}
+}
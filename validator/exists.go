@@ -0,0 +1,151 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Repository looks up whether referenced entities exist, for rules such as
+// "exists:users.id". It is implemented by the application, typically with
+// a single SELECT ... WHERE column IN (...) per call.
+type Repository interface {
+	// Exists reports which of values are present in column of table.
+	// Values missing from the result count as absent.
+	Exists(ctx context.Context, table, column string, values []string) (map[string]bool, error)
+}
+
+// RepositoryOptions controls existence lookups.
+type RepositoryOptions struct {
+	// CacheSize bounds the number of cached lookup results. Zero means 1024.
+	CacheSize int
+	// CacheTTL is how long a result is cached. Zero caches until evicted.
+	CacheTTL time.Duration
+	// Timeout bounds each lookup. Zero means 2 seconds.
+	Timeout time.Duration
+}
+
+// SetRepository enables the exists:table.column constraint. The values of
+// all exists-checked parameters of a request are looked up in one call per
+// table and column before the parameters are validated, and both hits and
+// misses are cached. A failed lookup rejects the value and is not cached.
+func (qv *QueryValidator) SetRepository(repo Repository, opts RepositoryOptions) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = 1024
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	qv.repository = &existsChecker{
+		repo:  repo,
+		opts:  opts,
+		cache: newMemoCache[typeOutcome](opts.CacheSize, opts.CacheTTL),
+	}
+}
+
+type existsChecker struct {
+	repo  Repository
+	opts  RepositoryOptions
+	cache *memoCache[typeOutcome]
+}
+
+// existsRef is the table and column named by an exists constraint.
+type existsRef struct {
+	table, column string
+}
+
+func parseExistsRef(arg string) (existsRef, error) {
+	table, column, ok := strings.Cut(arg, ".")
+	if !ok || table == "" || column == "" {
+		return existsRef{}, fmt.Errorf("want table.column, got %q", arg)
+	}
+	return existsRef{table: table, column: column}, nil
+}
+
+func (r existsRef) key(value string) string {
+	return r.table + "." + r.column + "\x00" + value
+}
+
+// lookup asks the repository about the uncached values and caches the answers.
+func (e *existsChecker) lookup(ref existsRef, values []string) {
+	var missing []string
+	for _, v := range values {
+		if _, ok := e.cache.get(ref.key(v)); !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.opts.Timeout)
+	defer cancel()
+	found, err := e.repo.Exists(ctx, ref.table, ref.column, missing)
+	if err != nil {
+		return
+	}
+	for _, v := range missing {
+		outcome := typeInvalid
+		if found[v] {
+			outcome = typeValid
+		}
+		e.cache.put(ref.key(v), outcome)
+	}
+}
+
+func (e *existsChecker) exists(ref existsRef, value string) bool {
+	outcome, ok := e.cache.get(ref.key(value))
+	if !ok {
+		e.lookup(ref, []string{value})
+		outcome, ok = e.cache.get(ref.key(value))
+	}
+	return ok && outcome == typeValid
+}
+
+func (qv *QueryValidator) existsConstraint(arg string) (func(string) bool, error) {
+	ref, err := parseExistsRef(arg)
+	if err != nil {
+		return nil, err
+	}
+	if qv.repository == nil {
+		return nil, errors.New("no repository set")
+	}
+	return func(value string) bool {
+		return qv.repository.exists(ref, value)
+	}, nil
+}
+
+// prefetchExists batches the existence lookups of a request, so a query
+// referencing several entities costs one round trip per table and column.
+// The round trips run concurrently under SetParallelism.
+func (qv *QueryValidator) prefetchExists(queries, rules map[string]string) {
+	if qv.repository == nil {
+		return
+	}
+	batches := make(map[existsRef][]string)
+	for param, value := range queries {
+		rule, ok := rules[param]
+		if !ok || !strings.Contains(rule, "exists:") {
+			continue
+		}
+		for _, tok := range qv.splitPipes(tokenizeTypeExpr(rule)) {
+			arg, ok := strings.CutPrefix(tok, "exists:")
+			if !ok {
+				continue
+			}
+			if ref, err := parseExistsRef(arg); err == nil {
+				batches[ref] = append(batches[ref], value)
+			}
+		}
+	}
+	refs := slices.Collect(maps.Keys(batches))
+	qv.inParallel(len(refs), func(i int) {
+		qv.repository.lookup(refs[i], batches[refs[i]])
+	})
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRepository knows the rows in rows, keyed by "table.column", and
+// records each lookup as "table.column:values".
+type fakeRepository struct {
+	rows map[string][]string
+	err  error
+
+	mu      sync.Mutex
+	lookups []string
+}
+
+func (r *fakeRepository) Exists(ctx context.Context, table, column string, values []string) (map[string]bool, error) {
+	sorted := slices.Sorted(slices.Values(values))
+	r.mu.Lock()
+	r.lookups = append(r.lookups, table+"."+column+":"+strings.Join(sorted, ","))
+	r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	found := make(map[string]bool)
+	for _, v := range values {
+		found[v] = slices.Contains(r.rows[table+"."+column], v)
+	}
+	return found, nil
+}
+
+func (r *fakeRepository) takeLookups() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lookups := r.lookups
+	r.lookups = nil
+	slices.Sort(lookups)
+	return lookups
+}
+
+func TestExists(t *testing.T) {
+	qv := NewQueryValidator()
+	repo := &fakeRepository{rows: map[string][]string{"users.id": {"1", "2"}, "teams.slug": {"core"}}}
+	qv.SetRepository(repo, RepositoryOptions{})
+	rules := map[string]string{
+		"author":   "integer AND exists:users.id",
+		"reviewer": "integer|exists:users.id",
+		"team":     "exists:teams.slug",
+	}
+
+	checkCodes(t, qv, map[string]string{"author": "1", "reviewer": "3", "team": "core"}, rules, "reviewer:NOT_FOUND")
+	if got, want := repo.takeLookups(), []string{"teams.slug:core", "users.id:1,3"}; !slices.Equal(got, want) {
+		t.Errorf("lookups %v, want one per table and column: %v", got, want)
+	}
+
+	checkCodes(t, qv, map[string]string{"author": "3", "reviewer": "1"}, rules, "author:NOT_FOUND")
+	if got := repo.takeLookups(); len(got) != 0 {
+		t.Errorf("cached values looked up again: %v", got)
+	}
+
+	checkCodes(t, qv, map[string]string{"author": "x"}, rules, "author:INVALID_TYPE")
+	if got := repo.takeLookups(); !slices.Equal(got, []string{"users.id:x"}) {
+		t.Errorf("lookups %v, want the batch of the request", got)
+	}
+}
+
+func TestExistsLookupFailure(t *testing.T) {
+	qv := NewQueryValidator()
+	repo := &fakeRepository{rows: map[string][]string{"users.id": {"1"}}, err: errors.New("connection refused")}
+	qv.SetRepository(repo, RepositoryOptions{})
+	rules := map[string]string{"author": "exists:users.id"}
+
+	checkCodes(t, qv, map[string]string{"author": "1"}, rules, "author:NOT_FOUND")
+	repo.err = nil
+	checkCodes(t, qv, map[string]string{"author": "1"}, rules)
+	if got := repo.takeLookups(); len(got) < 2 {
+		t.Errorf("lookups %v: failed lookup was cached", got)
+	}
+}
+
+func TestExistsRules(t *testing.T) {
+	qv := NewQueryValidator()
+	checkCodes(t, qv, map[string]string{"p": "1"}, map[string]string{"p": "exists:users.id"}, "p:INVALID_RULE")
+	qv.SetRepository(&fakeRepository{}, RepositoryOptions{})
+	for _, rule := range []string{"exists:users", "exists:.id", "exists:users."} {
+		checkCodes(t, qv, map[string]string{"p": "1"}, map[string]string{"p": rule}, "p:INVALID_RULE")
+	}
+	if got := errorMessage(t, qv, "p", "1", "exists:users.id"); got != "no users.id matches the value" {
+		t.Errorf("message %q", got)
+	}
+}
@@ -0,0 +1,40 @@
+package validator
+
+import "context"
+
+// SetReportAllFailures makes a parameter report every failed operand of its
+// rule's AND chain, e.g. both the type and the range, instead of stopping at
+// the first, so clients can fix everything in one round trip. Every operand
+// is then evaluated, including the expensive ones priorities would skip.
+func (qv *QueryValidator) SetReportAllFailures(all bool) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.reportAll = all
+}
+
+func (qv *QueryValidator) checkParamValueAll(ctx context.Context, value, rule string) []failure {
+	expr, err := qv.parseTypeExpr(rule)
+	if err != nil {
+		return []failure{fail(MsgInvalidRule, rule, err)}
+	}
+	return collectFailures(ctx, qv, expr, value)
+}
+
+// collectFailures checks every operand of AND chains. Other expressions fail
+// as a whole.
+func collectFailures(ctx context.Context, qv *QueryValidator, expr typeExpr, value string) []failure {
+	switch e := expr.(type) {
+	case skipExpr:
+		return collectFailures(ctx, qv, e.expr, value)
+	case andExpr:
+		var failures []failure
+		for _, operand := range e {
+			failures = append(failures, collectFailures(ctx, qv, operand, value)...)
+		}
+		return failures
+	}
+	if reason, ok := expr.check(ctx, qv, value); !ok {
+		return []failure{reason}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package validator
+
+import "testing"
+
+func TestSetReportAllFailures(t *testing.T) {
+	rules := map[string]string{
+		"n":    "int:8 AND min:5 AND in:1,2,3,300",
+		"q":    "skip_if:off AND string AND minlen:3 AND regex:^[a-z]+$",
+		"id":   "(uuid OR email) AND maxlen:10",
+		"tags": "array:integer AND min:1 AND max:9",
+		"off":  "boolean",
+	}
+	tests := []struct {
+		values map[string]string
+		first  []string
+		all    []string
+	}{
+		{map[string]string{"n": "4"}, []string{"n:TOO_SMALL"}, []string{"n:NOT_ALLOWED", "n:TOO_SMALL"}},
+		{map[string]string{"n": "300"}, []string{"n:CONSTRAINT_FAILED"}, []string{"n:CONSTRAINT_FAILED"}},
+		{map[string]string{"n": "x"}, []string{"n:CONSTRAINT_FAILED"}, []string{"n:CONSTRAINT_FAILED", "n:NOT_ALLOWED", "n:TOO_SMALL"}},
+		{map[string]string{"n": "2"}, []string{"n:TOO_SMALL"}, []string{"n:TOO_SMALL"}},
+		{map[string]string{"q": "A1"}, []string{"q:TOO_SHORT"}, []string{"q:PATTERN_MISMATCH", "q:TOO_SHORT"}},
+		{map[string]string{"q": "A1", "off": "true"}, nil, nil},
+		{map[string]string{"id": "not an id at all"}, []string{"id:NO_ALTERNATIVE_MATCHED"}, []string{"id:NO_ALTERNATIVE_MATCHED", "id:TOO_LONG"}},
+		{map[string]string{"tags": "0,5,10"}, []string{"tags:TOO_LARGE", "tags:TOO_SMALL"}, []string{"tags:TOO_LARGE", "tags:TOO_SMALL"}},
+		{map[string]string{"tags": "x"}, []string{"tags:INVALID_TYPE"}, []string{"tags:INVALID_TYPE", "tags:TOO_LARGE", "tags:TOO_SMALL"}},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		checkCodes(t, qv, tt.values, rules, tt.first...)
+		qv.SetReportAllFailures(true)
+		checkCodes(t, qv, tt.values, rules, tt.all...)
+	}
+}
@@ -0,0 +1,565 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// This file holds the Fiber adapter, which core builds leave out.
+
+// frameworkHooks holds the request-dependent hooks of the Fiber adapter.
+type frameworkHooks struct {
+	principal    PrincipalFunc
+	scopeChecker ScopeChecker
+	trusted      TrustedSource
+}
+
+// request describes c to the core.
+func (qv *QueryValidator) request(c fiber.Ctx) validationRequest {
+	return validationRequest{
+		ctx:      c.UserContext(),
+		route:    c.Route().Path,
+		hasScope: func(scope string) bool { return qv.hasScope(c, scope) },
+		trusted:  func() bool { return qv.trusted != nil && qv.trusted(c) },
+	}
+}
+
+// TrustedSource reports whether c comes from a source allowed to send
+// reserved parameters, e.g. an internal network or a signed service token.
+type TrustedSource func(c fiber.Ctx) bool
+
+// SetTrustedSource sets the predicate that lets requests use the
+// namespaces reserved with ReserveNamespace.
+func (qv *QueryValidator) SetTrustedSource(fn TrustedSource) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.trusted = fn
+}
+
+// ValidateQuery validates the query of a Fiber request. It is a thin adapter
+// over the core ValidateValues adds to: role overlays and scopes come from
+// the request, its query args get the defaults, changed values and stripped
+// parameters so handlers reading c.Query see the validated query, and
+// messages follow the locale in its Locals.
+func (qv *QueryValidator) ValidateQuery(c fiber.Ctx, rules map[string]string) []QueryValidationError {
+	_, errors, _ := qv.validateQuery(c, rules)
+	localize(c, errors)
+	return errors
+}
+
+// validateQuery is ValidateQuery before localization. It also returns the
+// rules that applied and the changes written into c's query args.
+func (qv *QueryValidator) validateQuery(c fiber.Ctx, rules map[string]string) (map[string]string, []QueryValidationError, queryChanges) {
+	rules = qv.rulesFor(c, rules)
+	errors, changes := qv.run(queryValues(c, rules), rules, qv.request(c))
+	applyChanges(c, changes)
+	return rules, errors, changes
+}
+
+func applyChanges(c fiber.Ctx, changes queryChanges) {
+	args := c.Request().URI().QueryArgs()
+	for param, value := range changes.set {
+		args.Set(param, value)
+	}
+	for _, param := range changes.removed {
+		args.Del(param)
+	}
+}
+
+// ValidateQueryResult is ValidateQuery returning a ValidationResult.
+func (qv *QueryValidator) ValidateQueryResult(c fiber.Ctx, rules map[string]string) ValidationResult {
+	rules, errors, _ := qv.validateQuery(c, rules)
+	localize(c, errors)
+	return newValidationResult(queryValues(c, rules), rules, errors)
+}
+
+// cachedQuery is what Middleware replays for a request it has cached.
+type cachedQuery struct {
+	errors  []QueryValidationError
+	changes queryChanges
+	result  ValidationResult
+}
+
+// cachedQueryResult is ValidateQueryResult served from cache when a request
+// with the same key was validated recently.
+func (qv *QueryValidator) cachedQueryResult(c fiber.Ctx, rules map[string]string, cache *memoCache[cachedQuery], key string) ValidationResult {
+	entry, ok := cache.get(key)
+	if ok {
+		applyChanges(c, entry.changes)
+	} else {
+		var errors []QueryValidationError
+		var changes queryChanges
+		rules, errors, changes = qv.validateQuery(c, rules)
+		// Fiber reuses the buffers the request's strings point into once
+		// it is done, so the cached entry keeps copies.
+		entry.errors = cloneErrorStrings(errors)
+		entry.changes = queryChanges{set: cloneStrings(changes.set), removed: make([]string, len(changes.removed))}
+		for i, param := range changes.removed {
+			entry.changes.removed[i] = strings.Clone(param)
+		}
+		entry.result = newValidationResult(cloneStrings(queryValues(c, rules)), rules, entry.errors)
+		cache.put(key, entry)
+	}
+	// localize renders in place, and clients may differ in locale.
+	result := entry.result
+	result.Errors = slices.Clone(entry.errors)
+	localize(c, result.Errors)
+	return result
+}
+
+// cloneStrings copies the keys and values of m.
+func cloneStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	clone := make(map[string]string, len(m))
+	for key, value := range m {
+		clone[strings.Clone(key)] = strings.Clone(value)
+	}
+	return clone
+}
+
+// cloneErrorStrings copies errors with the parameters and values they took
+// from the request.
+func cloneErrorStrings(errors []QueryValidationError) []QueryValidationError {
+	clone := slices.Clone(errors)
+	for i := range clone {
+		clone[i].Parameter = strings.Clone(clone[i].Parameter)
+		clone[i].Value = strings.Clone(clone[i].Value)
+	}
+	return clone
+}
+
+// queryCacheKey identifies the client, route and query of c, with the
+// query's parameters sorted so their order does not matter, along with
+// every other input deciding the outcome: the media types picking overlays,
+// the caller's role, the scopes it holds of those parameters require, and
+// whether it is a trusted source. Clients sharing an ID thus never get each
+// other's results.
+func (qv *QueryValidator) queryCacheKey(c fiber.Ctx, client string) string {
+	var pairs [][2]string
+	c.Request().URI().QueryArgs().VisitAll(func(key, value []byte) {
+		pairs = append(pairs, [2]string{string(key), string(value)})
+	})
+	// Repeated parameters keep their order, which arrays may depend on.
+	slices.SortStableFunc(pairs, func(a, b [2]string) int { return strings.Compare(a[0], b[0]) })
+
+	qv.mu.RLock()
+	role := qv.principalFor(c).Role
+	var granted []string
+	for _, scope := range slices.Compact(slices.Sorted(maps.Values(qv.paramScopes))) {
+		if qv.hasScope(c, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	trusted := qv.trusted != nil && qv.trusted(c)
+	qv.mu.RUnlock()
+
+	var key strings.Builder
+	for _, field := range []string{
+		client, c.Route().Path, c.Get(fiber.HeaderAccept), c.Get(fiber.HeaderContentType),
+		role, strings.Join(granted, ","), strconv.FormatBool(trusted),
+	} {
+		// Escaped fields hold no NUL, so they cannot run into each other.
+		key.WriteString(url.QueryEscape(field) + "\x00")
+	}
+	for _, pair := range pairs {
+		key.WriteString(url.QueryEscape(pair[0]) + "=" + url.QueryEscape(pair[1]) + "&")
+	}
+	return key.String()
+}
+
+// queryValues is c.Queries with the repeated values of array parameters
+// joined.
+func queryValues(c fiber.Ctx, rules map[string]string) map[string]string {
+	values := c.Queries()
+	args := c.Request().URI().QueryArgs()
+	joinArrayValues(values, func(param string) []string {
+		var vs []string
+		for _, v := range args.PeekMulti(param) {
+			vs = append(vs, string(v))
+		}
+		return vs
+	}, rules)
+	return values
+}
+
+// QueryResult returns the ValidationResult Middleware stored for c.
+func QueryResult(c fiber.Ctx) ValidationResult {
+	result, _ := c.Locals(LocalsResult).(ValidationResult)
+	return result
+}
+
+// localize re-renders errors with the overrides found in c's Locals and
+// the locale found there or, failing that, in its Accept-Language header.
+func localize(c fiber.Ctx, errors []QueryValidationError) {
+	overrides, _ := c.Locals(LocalsMessageOverrides).(map[string]string)
+	printer := printerFromLocals(c)
+	if printer == nil {
+		printer = acceptLanguagePrinter(c.Get(fiber.HeaderAcceptLanguage))
+	}
+	if printer == nil && len(overrides) == 0 {
+		return
+	}
+	if printer == nil {
+		printer = messages
+	}
+	renderErrors(errors, printer, overrides)
+}
+
+func printerFromLocals(c fiber.Ctx) *message.Printer {
+	var tag language.Tag
+	switch locale := c.Locals(LocalsLocale).(type) {
+	case language.Tag:
+		tag = locale
+	case string:
+		parsed, err := language.Parse(locale)
+		if err != nil {
+			return nil
+		}
+		tag = parsed
+	default:
+		return nil
+	}
+	return newPrinter(tag)
+}
+
+// PrincipalFunc extracts the caller from a request, typically from claims an
+// earlier auth middleware stored in Locals.
+type PrincipalFunc func(c fiber.Ctx) Principal
+
+// SetPrincipalFunc registers how the validator learns who is calling.
+func (qv *QueryValidator) SetPrincipalFunc(fn PrincipalFunc) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.principal = fn
+}
+
+func (qv *QueryValidator) principalFor(c fiber.Ctx) Principal {
+	if qv.principal == nil {
+		return Principal{}
+	}
+	return qv.principal(c)
+}
+
+// rulesFor returns rules with the overlays for the negotiated media type and
+// the caller's role applied.
+func (qv *QueryValidator) rulesFor(c fiber.Ctx, rules map[string]string) map[string]string {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	rules = qv.rulesForMediaType(c.Get(fiber.HeaderAccept), c.Get(fiber.HeaderContentType), rules)
+	if qv.principal == nil || len(qv.roleRules) == 0 {
+		return rules
+	}
+	return qv.rulesForRole(qv.principalFor(c).Role, rules)
+}
+
+// ScopeChecker reports whether the caller of c holds scope.
+type ScopeChecker func(c fiber.Ctx, scope string) bool
+
+// SetScopeChecker overrides how scopes are checked. By default the scopes of
+// the Principal from SetPrincipalFunc are consulted.
+func (qv *QueryValidator) SetScopeChecker(fn ScopeChecker) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.scopeChecker = fn
+}
+
+func (qv *QueryValidator) hasScope(c fiber.Ctx, scope string) bool {
+	if qv.scopeChecker != nil {
+		return qv.scopeChecker(c, scope)
+	}
+	return slices.Contains(qv.principalFor(c).Scopes, scope)
+}
+
+// ValidateQueryStream is like ValidateQuery but scans the raw query string
+// pair by pair, checking limits before building a map of its parameters.
+// It stops at the first limit breach, so adversarially long URLs cost
+// bounded work and memory; queries within the limits are then validated
+// like ValidateQuery does.
+func (qv *QueryValidator) ValidateQueryStream(c fiber.Ctx, rules map[string]string, limits StreamLimits) []QueryValidationError {
+	values, breach := scanQuery(c.Request().URI().QueryString(), limits)
+	if breach != nil {
+		errors := []QueryValidationError{*breach}
+		qv.mu.RLock()
+		qv.finishErrors(errors)
+		qv.mu.RUnlock()
+		localize(c, errors)
+		return errors
+	}
+
+	rules = qv.rulesFor(c, rules)
+	errors, changes := qv.run(flattenValues(values, rules), rules, qv.request(c))
+	applyChanges(c, changes)
+	if limits.MaxErrors > 0 && len(errors) > limits.MaxErrors {
+		errors = errors[:limits.MaxErrors]
+	}
+	localize(c, errors)
+	return errors
+}
+
+// CanonicalQuery validates the request's query and returns its canonical
+// form: defaults applied, values normalized per their rule's type and keys
+// sorted, encoded as a query string. Requests that mean the same thing get
+// the same string, so it suits cache keys and deduplication. It returns the
+// validation errors instead when the query is invalid.
+func (qv *QueryValidator) CanonicalQuery(c fiber.Ctx, rules map[string]string) (string, []QueryValidationError) {
+	if errors := qv.ValidateQuery(c, rules); len(errors) > 0 {
+		return "", errors
+	}
+	rules = qv.rulesFor(c, rules)
+	return qv.canonicalize(queryValues(c, rules), rules), nil
+}
+
+// CacheKey validates the request's query and returns a stable hash of its
+// canonical form, restricted to the parameters rules declares, for use in
+// CDN or memcache keys. Combine it with the path; it only covers the query.
+func (qv *QueryValidator) CacheKey(c fiber.Ctx, rules map[string]string) (string, []QueryValidationError) {
+	if errors := qv.ValidateQuery(c, rules); len(errors) > 0 {
+		return "", errors
+	}
+	rules = qv.rulesFor(c, rules)
+	return cacheKey(qv.canonicalize(declaredValues(c.Queries(), rules), rules)), nil
+}
+
+// SchemaDocumentHandler serves Schemas as JSON, typically at WellKnownSchemaPath.
+func (qv *QueryValidator) SchemaDocumentHandler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		return c.JSON(qv.Schemas())
+	}
+}
+
+// SchemaHandler serves the schema of a registered route as JSON. Mount it
+// with a wildcard, e.g. app.Get("/_schema/*", qv.SchemaHandler()), and
+// request /_schema/users for the route "/users".
+func (qv *QueryValidator) SchemaHandler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		schema, ok := qv.Schema(c.Params("*"))
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "unknown route",
+			})
+		}
+		return c.JSON(schema)
+	}
+}
+
+// ErrorSchemaHandler serves ErrorSchema, typically at WellKnownErrorSchemaPath.
+func ErrorSchemaHandler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "application/schema+json")
+		return c.Send(errorSchema)
+	}
+}
+
+// Handler returns middleware recording the query of every request until the
+// learning period ends.
+func (l *Learner) Handler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if l.until.IsZero() || time.Now().Before(l.until) {
+			l.Observe(c.Route().Path, c.Queries())
+		}
+		return c.Next()
+	}
+}
+
+// MatrixParams returns middleware that strips matrix parameters from the
+// request path and adds them to the query, so routes match the plain path
+// and query rules apply to them. Parameters also sent in the query keep the
+// query's value. Register it with app.Use before the routes it serves.
+func MatrixParams() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		clean, params := ParseMatrixParams(c.Path())
+		if params == nil {
+			return c.Next()
+		}
+		args := c.Request().URI().QueryArgs()
+		for key, values := range params {
+			if args.Has(key) {
+				continue
+			}
+			for _, value := range values {
+				args.Add(key, value)
+			}
+		}
+		c.Path(clean)
+		return c.Next()
+	}
+}
+
+// MiddlewareConfig configures Middleware.
+type MiddlewareConfig struct {
+	// ErrorHandler responds to a request whose query was rejected. It gets
+	// only the rejections; warnings never stop a request. The default
+	// responds with ErrorStatus and a body rendered by Formatter.
+	ErrorHandler func(c fiber.Ctx, errors []QueryValidationError) error
+	// Formatter renders the default error handler's body. It defaults to the
+	// validator's SetErrorFormatter formatter, then to JSONErrorFormatter.
+	Formatter ErrorFormatter
+	// Next lets the request through unvalidated when it returns true.
+	Next func(c fiber.Ctx) bool
+	// SkipMethods and SkipPaths let requests through unvalidated by method,
+	// e.g. OPTIONS, or by path, as a path.Match pattern such as "/healthz"
+	// or "/debug/*". Skipped requests have no ValidationResult.
+	SkipMethods []string
+	SkipPaths   []string
+	// CacheSize and CacheTTL, when both are set, keep the results of up to
+	// CacheSize recent requests for CacheTTL, by client, route and query,
+	// so clients polling the same URL are not validated again until their
+	// entry expires. Cached requests reuse the outcome of defaults, exists
+	// lookups and context validators, so only enable it for rules whose
+	// outcome holds for CacheTTL.
+	CacheSize int
+	CacheTTL  time.Duration
+	// ClientID identifies the client of cached requests. It defaults to
+	// c.IP(). The caller's role, scopes and trusted state and the request's
+	// media types are part of the cache key whatever the ID.
+	ClientID func(c fiber.Ctx) string
+}
+
+// skip reports whether cfg lets c through unvalidated.
+func (cfg MiddlewareConfig) skip(c fiber.Ctx) bool {
+	if cfg.Next != nil && cfg.Next(c) {
+		return true
+	}
+	if slices.ContainsFunc(cfg.SkipMethods, func(m string) bool { return strings.EqualFold(m, c.Method()) }) {
+		return true
+	}
+	for _, pattern := range cfg.SkipPaths {
+		if ok, _ := path.Match(pattern, c.Path()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware returns a handler that validates the query before the next
+// handler runs and responds with the configured error handler when it is
+// rejected. Handlers get the typed values from QueryResult. A nil rules map
+// uses RouteRules for the matched route; SetRuleConflictPolicy decides what
+// happens when rules are given for a registered route as well, and its
+// errors go to Fiber's error handler.
+//
+// Fiber runs app.Use handlers in the order they were added, then a route's
+// middleware arguments in order, then its handler. Add Middleware after the
+// middleware that sets the locale, role or principal it reads, and ahead of
+// those reading QueryResult:
+//
+//	app.Use(auth)
+//	app.Get("/users", listUsers, qv.Middleware(listUsersRules, validator.MiddlewareConfig{
+//		SkipMethods: []string{fiber.MethodOptions},
+//	}))
+func (qv *QueryValidator) Middleware(rules map[string]string, config ...MiddlewareConfig) fiber.Handler {
+	var cfg MiddlewareConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = func(c fiber.Ctx, errors []QueryValidationError) error {
+			formatter := cfg.Formatter
+			if formatter == nil {
+				qv.mu.RLock()
+				formatter = qv.errorFormatter
+				qv.mu.RUnlock()
+			}
+			status, contentType, body := formatErrors(formatter, errors)
+			c.Set(fiber.HeaderContentType, contentType)
+			return c.Status(status).Send(body)
+		}
+	}
+
+	var cache *memoCache[cachedQuery]
+	if cfg.CacheSize > 0 && cfg.CacheTTL > 0 {
+		cache = newMemoCache[cachedQuery](cfg.CacheSize, cfg.CacheTTL)
+		if cfg.ClientID == nil {
+			cfg.ClientID = func(c fiber.Ctx) string { return c.IP() }
+		}
+	}
+
+	return func(c fiber.Ctx) error {
+		if cfg.skip(c) {
+			return c.Next()
+		}
+		routeRules, ok, err := qv.resolveRouteRules(c.Route().Path, rules)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return c.Next()
+		}
+		var result ValidationResult
+		if cache != nil {
+			result = qv.cachedQueryResult(c, routeRules, cache, qv.queryCacheKey(c, cfg.ClientID(c)))
+		} else {
+			result = qv.ValidateQueryResult(c, routeRules)
+		}
+		if errors := Rejections(result.Errors); len(errors) > 0 {
+			return cfg.ErrorHandler(c, errors)
+		}
+		c.Locals(LocalsResult, result)
+		return c.Next()
+	}
+}
+
+// Middleware is QueryValidator.Middleware with the compiled rules.
+func (s *CompiledRuleSet) Middleware(config ...MiddlewareConfig) fiber.Handler {
+	return s.qv.Middleware(s.rules, config...)
+}
+
+// ValidateQuery is QueryValidator.ValidateQuery with the compiled rules.
+func (s *CompiledRuleSet) ValidateQuery(c fiber.Ctx) []QueryValidationError {
+	return s.qv.ValidateQuery(c, s.rules)
+}
+
+// UpgradeMiddleware is Middleware for WebSocket and server-sent event
+// endpoints. It validates the query of WebSocket upgrade requests and of
+// requests accepting text/event-stream, so a rejected subscription gets the
+// error response before the connection is upgraded or the stream starts.
+// Other requests pass through to the upgrade handler, which decides what to
+// do with them. Mount it ahead of the upgrade handler; the WebSocket
+// handler reads the ValidationResult from its connection's Locals:
+//
+//	app.Use("/ws", qv.UpgradeMiddleware(wsRules))
+//	app.Get("/ws", websocket.New(func(conn *websocket.Conn) {
+//		result, _ := conn.Locals(validator.LocalsResult).(validator.ValidationResult)
+//	}))
+func (qv *QueryValidator) UpgradeMiddleware(rules map[string]string, config ...MiddlewareConfig) fiber.Handler {
+	validate := qv.Middleware(rules, config...)
+	return func(c fiber.Ctx) error {
+		if !isWebSocketUpgrade(c) && !acceptsEventStream(c) {
+			return c.Next()
+		}
+		return validate(c)
+	}
+}
+
+func isWebSocketUpgrade(c fiber.Ctx) bool {
+	return headerHasToken(c.Get(fiber.HeaderConnection), "upgrade") &&
+		headerHasToken(c.Get(fiber.HeaderUpgrade), "websocket")
+}
+
+func acceptsEventStream(c fiber.Ctx) bool {
+	return slices.Contains(acceptedMediaTypes(c.Get(fiber.HeaderAccept)), "text/event-stream")
+}
+
+// headerHasToken reports whether the comma-separated header has token,
+// ignoring case.
+func headerHasToken(header, token string) bool {
+	for _, part := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,423 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"golang.org/x/text/language"
+)
+
+// fiberResponse is what serveQuery's handler saw and answered.
+type fiberResponse struct {
+	Status int
+	// Query is the request's query string after validation.
+	Query  string
+	Errors []QueryValidationError
+}
+
+// serveQuery requests target from an app routing path to validate, which
+// returns the errors to report, and records the outcome.
+func serveQuery(t *testing.T, path, target string, validate func(c fiber.Ctx) []QueryValidationError, middleware ...fiber.Handler) fiberResponse {
+	t.Helper()
+	app := fiber.New()
+	var got fiberResponse
+	handler := func(c fiber.Ctx) error {
+		if validate != nil {
+			got.Errors = validate(c)
+		}
+		got.Query = c.Request().URI().QueryArgs().String()
+		if len(Rejections(got.Errors)) > 0 {
+			return c.SendStatus(ErrorStatus(got.Errors))
+		}
+		return c.SendStatus(fiber.StatusOK)
+	}
+	// Fiber runs the middleware before the handler.
+	app.Get(path, handler, middleware...)
+	resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	got.Status = resp.StatusCode
+	if got.Status >= 400 && validate == nil {
+		body, _ := io.ReadAll(resp.Body)
+		var payload struct {
+			Errors []QueryValidationError `json:"errors"`
+		}
+		json.Unmarshal(body, &payload)
+		got.Errors = payload.Errors
+	}
+	return got
+}
+
+func TestLocalsMessageOverrides(t *testing.T) {
+	if err := RegisterLocale(language.German, map[string]string{
+		MsgMissingRequired: "Pflichtparameter fehlt",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	qv := NewQueryValidator()
+	rules := map[string]string{"q": "required", "limit": "integer"}
+	tests := []struct {
+		name      string
+		locals    map[string]any
+		header    string
+		wantQ     string
+		wantLimit string
+	}{
+		{"english", nil, "", "missing required parameter", "invalid value for type integer"},
+		{"accept-language", nil, "de-DE", "Pflichtparameter fehlt", "invalid value for type integer"},
+		{"locale in locals", map[string]any{LocalsLocale: "de"}, "", "Pflichtparameter fehlt", "invalid value for type integer"},
+		{"locals win over header", map[string]any{LocalsLocale: language.English}, "de", "missing required parameter", "invalid value for type integer"},
+		{"tenant overrides", map[string]any{LocalsMessageOverrides: map[string]string{
+			MsgInvalidType: "{param} must be an {constraint}, got {value}",
+		}}, "de", "Pflichtparameter fehlt", "limit must be an integer, got ten"},
+	}
+	for _, tt := range tests {
+		got := serveQuery(t, "/", "/?limit=ten", func(c fiber.Ctx) []QueryValidationError {
+			return qv.ValidateQuery(c, rules)
+		}, func(c fiber.Ctx) error {
+			for key, value := range tt.locals {
+				c.Locals(key, value)
+			}
+			if tt.header != "" {
+				c.Request().Header.Set(fiber.HeaderAcceptLanguage, tt.header)
+			}
+			return c.Next()
+		})
+		messages := make(map[string]string)
+		for _, err := range got.Errors {
+			messages[err.Parameter] = err.Message
+		}
+		if messages["q"] != tt.wantQ || messages["limit"] != tt.wantLimit {
+			t.Errorf("%s: messages %v, want q %q and limit %q", tt.name, messages, tt.wantQ, tt.wantLimit)
+		}
+		for _, err := range got.Errors {
+			if err.Code != MsgMissingRequired && err.Code != MsgInvalidType {
+				t.Errorf("%s: code %s changed by localization", tt.name, err.Code)
+			}
+		}
+	}
+}
+
+func TestMiddlewareErrorFormatter(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetErrorFormatter(ProblemErrorFormatter(""))
+	rules := map[string]string{"n": "integer"}
+	text := MiddlewareConfig{Formatter: func(status int, errors []QueryValidationError) (string, []byte) {
+		return "text/plain", []byte(errors[0].Code)
+	}}
+	tests := []struct {
+		name        string
+		mw          fiber.Handler
+		contentType string
+		body        string
+	}{
+		{"validator formatter", qv.Middleware(rules), "application/problem+json", `"invalid-params":[{"name":"n"`},
+		{"config formatter", qv.Middleware(rules, text), "text/plain", MsgInvalidType},
+		{"default formatter", NewQueryValidator().Middleware(rules), "application/json", `"errors":[{"parameter":"n"`},
+	}
+	for _, tt := range tests {
+		app := fiber.New()
+		app.Get("/", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }, tt.mw)
+		resp, err := app.Test(httptest.NewRequest("GET", "/?n=x", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != 400 || resp.Header.Get(fiber.HeaderContentType) != tt.contentType || !strings.Contains(string(body), tt.body) {
+			t.Errorf("%s: status %d, content type %s, body %s", tt.name, resp.StatusCode, resp.Header.Get(fiber.HeaderContentType), body)
+		}
+	}
+}
+
+func TestMiddlewareSkip(t *testing.T) {
+	qv := NewQueryValidator()
+	cfg := MiddlewareConfig{
+		Next:        func(c fiber.Ctx) bool { return c.Get("X-Internal") == "1" },
+		SkipMethods: []string{"options"},
+		SkipPaths:   []string{"/healthz", "/debug/*"},
+	}
+	app := fiber.New()
+	app.Use(qv.Middleware(map[string]string{"n": "integer"}, cfg))
+	handler := func(c fiber.Ctx) error {
+		_, validated := c.Locals(LocalsResult).(ValidationResult)
+		return c.SendString(strconv.FormatBool(validated))
+	}
+	app.Get("/*", handler)
+	app.Options("/*", handler)
+	tests := []struct {
+		method, target, header string
+		status                 int
+		validated              bool
+	}{
+		{"GET", "/users?n=1", "", 200, true},
+		{"GET", "/users?n=x", "", 400, false},
+		{"OPTIONS", "/users?n=x", "", 200, false},
+		{"GET", "/healthz?n=x", "", 200, false},
+		{"GET", "/debug/vars?n=x", "", 200, false},
+		{"GET", "/debug/a/b?n=x", "", 400, false},
+		{"GET", "/users?n=x", "1", 200, false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		if tt.header != "" {
+			req.Header.Set("X-Internal", tt.header)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status || tt.status == 200 && string(body) != strconv.FormatBool(tt.validated) {
+			t.Errorf("%s %s: status %d, validated %s, want %d, %v", tt.method, tt.target, resp.StatusCode, body, tt.status, tt.validated)
+		}
+	}
+}
+
+func TestMiddlewareCache(t *testing.T) {
+	qv := NewQueryValidator()
+	var calls atomic.Int32
+	qv.AddTypeValidator("counted", func(v string) bool {
+		calls.Add(1)
+		return v != "bad"
+	})
+	app := fiber.New()
+	app.Get("/poll", func(c fiber.Ctx) error {
+		n, _ := QueryResult(c).String("n")
+		return c.SendString(n)
+	}, qv.Middleware(map[string]string{"n": "counted", "m": "string"}, MiddlewareConfig{
+		CacheSize: 8,
+		CacheTTL:  50 * time.Millisecond,
+		ClientID:  func(c fiber.Ctx) string { return c.Get("X-Client") },
+	}))
+	tests := []struct {
+		name, target, client string
+		status               int
+		// validated is whether the request was validated rather than served
+		// from the cache.
+		validated bool
+	}{
+		{"first poll", "/poll?n=1&m=x", "a", 200, true},
+		{"same poll", "/poll?n=1&m=x", "a", 200, false},
+		{"reordered query", "/poll?m=x&n=1", "a", 200, false},
+		{"other client", "/poll?n=1&m=x", "b", 200, true},
+		{"other query", "/poll?n=2&m=x", "a", 200, true},
+		{"rejected", "/poll?n=bad", "a", 400, true},
+		{"rejected again", "/poll?n=bad", "a", 400, false},
+		{"expired", "/poll?n=1&m=x", "a", 200, true},
+	}
+	for _, tt := range tests {
+		if tt.name == "expired" {
+			time.Sleep(60 * time.Millisecond)
+		}
+		before := calls.Load()
+		req := httptest.NewRequest("GET", tt.target, nil)
+		req.Header.Set("X-Client", tt.client)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if validated := calls.Load() != before; resp.StatusCode != tt.status || validated != tt.validated {
+			t.Errorf("%s: status %d, validated %v, want %d, %v", tt.name, resp.StatusCode, validated, tt.status, tt.validated)
+		}
+		if want, _ := url.ParseQuery(strings.TrimPrefix(tt.target, "/poll?")); tt.status == 200 && string(body) != want.Get("n") {
+			t.Errorf("%s: handler saw n=%q", tt.name, body)
+		}
+	}
+}
+
+func TestMiddlewareCacheKey(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetPrincipalFunc(func(c fiber.Ctx) Principal {
+		return Principal{Role: c.Get("X-Role"), Scopes: strings.Fields(c.Get("X-Scopes"))}
+	})
+	qv.AddRoleRules("admin", map[string]string{"debug": "boolean"})
+	qv.RequireScope("export", "reports")
+	qv.ReserveNamespace("x_internal_*")
+	qv.SetTrustedSource(func(c fiber.Ctx) bool { return c.Get("X-Trusted") == "1" })
+	qv.AddMediaTypeRules("text/csv", map[string]string{"delimiter": "in:comma,tab"})
+	rules := map[string]string{"export": "boolean", "x_internal_trace": "string"}
+	app := fiber.New()
+	app.Get("/poll", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }, qv.Middleware(rules, MiddlewareConfig{
+		CacheSize: 16,
+		CacheTTL:  time.Minute,
+	}))
+	// Every request comes from the same IP, so only the other inputs of
+	// the rules tell the callers apart.
+	tests := []struct {
+		name    string
+		target  string
+		headers map[string]string
+		status  int
+	}{
+		{"admin role", "/poll?debug=true", map[string]string{"X-Role": "admin"}, 200},
+		{"other role", "/poll?debug=true", map[string]string{"X-Role": "guest"}, 400},
+		{"scope held", "/poll?export=true", map[string]string{"X-Scopes": "reports"}, 200},
+		{"scope lacking", "/poll?export=true", nil, 403},
+		{"trusted", "/poll?x_internal_trace=1", map[string]string{"X-Trusted": "1"}, 200},
+		{"untrusted", "/poll?x_internal_trace=1", nil, 403},
+		{"csv", "/poll?delimiter=tab", map[string]string{"Accept": "text/csv"}, 200},
+		{"json", "/poll?delimiter=tab", map[string]string{"Accept": "application/json"}, 400},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.target, nil)
+		for key, value := range tt.headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, resp.StatusCode, tt.status)
+		}
+	}
+}
+
+func TestMiddlewareSeverities(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetSeverityPolicy(SeverityPolicy{MsgUnexpected: SeverityWarning})
+	rules := map[string]string{"n": "integer"}
+	tests := []struct {
+		target string
+		status int
+		// warnings are those the handler finds in QueryResult.
+		warnings []string
+	}{
+		{"/?n=1", 200, nil},
+		{"/?n=1&utm=x", 200, []string{"utm:UNEXPECTED_PARAM"}},
+		{"/?n=x&utm=x", 400, nil},
+	}
+	for _, tt := range tests {
+		got := serveQuery(t, "/", tt.target, func(c fiber.Ctx) []QueryValidationError {
+			return QueryResult(c).Errors
+		}, qv.Middleware(rules))
+		if codes := errorCodes(Warnings(got.Errors)); got.Status != tt.status || !slices.Equal(codes, tt.warnings) {
+			t.Errorf("%s: status %d, warnings %v, want %d, %v", tt.target, got.Status, codes, tt.status, tt.warnings)
+		}
+	}
+}
+
+func TestValidateQueryMatchesValidateValues(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"page": "required AND int:32 AND min:1", "tags": "array:integer", "q": "string"}
+	for _, query := range []string{"page=1&q=go", "page=0", "q=go", "page=1&tags=1,x", "page=2&other=1"} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := serveQuery(t, "/", "/?"+query, func(c fiber.Ctx) []QueryValidationError {
+			return qv.ValidateQuery(c, rules)
+		})
+		if want := errorCodes(qv.ValidateValues(values, rules)); !slices.Equal(errorCodes(got.Errors), want) {
+			t.Errorf("%s: ValidateQuery errors %v, ValidateValues errors %v", query, errorCodes(got.Errors), want)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RegisterRoute("/users/:id", map[string]string{"expand": "in:teams"})
+	rules := map[string]string{"n": "required AND integer"}
+	teapot := MiddlewareConfig{ErrorHandler: func(c fiber.Ctx, errors []QueryValidationError) error {
+		return c.Status(fiber.StatusTeapot).SendString(errors[0].Code)
+	}}
+	tests := []struct {
+		name   string
+		path   string
+		target string
+		mw     fiber.Handler
+		status int
+		want   []string
+	}{
+		{"valid", "/", "/?n=1", qv.Middleware(rules), 200, nil},
+		{"rejected", "/", "/?n=x&m=1", qv.Middleware(rules), 400, []string{"m:UNEXPECTED_PARAM", "n:INVALID_TYPE"}},
+		{"missing", "/", "/", qv.Middleware(rules), 400, []string{"n:MISSING_REQUIRED"}},
+		{"error handler", "/", "/?n=x", qv.Middleware(rules, teapot), fiber.StatusTeapot, nil},
+		{"registered rules", "/users/:id", "/users/7?expand=teams", qv.Middleware(nil), 200, nil},
+		{"registered rules rejected", "/users/:id", "/users/7?expand=all", qv.Middleware(nil), 400, []string{"expand:NOT_ALLOWED"}},
+		{"unregistered route", "/orders", "/orders?anything=1", qv.Middleware(nil), 200, nil},
+	}
+	for _, tt := range tests {
+		got := serveQuery(t, tt.path, tt.target, nil, tt.mw)
+		if codes := errorCodes(got.Errors); got.Status != tt.status || !slices.Equal(codes, tt.want) {
+			t.Errorf("%s: status %d, errors %v, want %d, %v", tt.name, got.Status, codes, tt.status, tt.want)
+		}
+	}
+}
+
+func TestValidateQuerySuggestions(t *testing.T) {
+	qv := NewQueryValidator()
+	got := serveQuery(t, "/", "/?status=actve", func(c fiber.Ctx) []QueryValidationError {
+		return qv.ValidateQuery(c, map[string]string{"status": "in:active,inactive"})
+	})
+	want := `value must be one of active,inactive (did you mean "active"?)`
+	if len(got.Errors) != 1 || got.Errors[0].Suggestion != "active" || got.Errors[0].Message != want {
+		t.Errorf("errors %+v, want the suggestion in the message", got.Errors)
+	}
+}
+
+func TestSetTrustedSource(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.ReserveNamespace("x_internal_*")
+	qv.SetTrustedSource(func(c fiber.Ctx) bool { return c.Get("X-Service-Token") == "secret" })
+	rules := map[string]string{"x_internal_trace": "boolean"}
+	for _, tt := range []struct {
+		token  string
+		status int
+	}{{"secret", 200}, {"guess", 403}, {"", 403}} {
+		got := serveQuery(t, "/", "/?x_internal_trace=true", nil, func(c fiber.Ctx) error {
+			if tt.token != "" {
+				c.Request().Header.Set("X-Service-Token", tt.token)
+			}
+			return c.Next()
+		}, qv.Middleware(rules))
+		if got.Status != tt.status {
+			t.Errorf("token %q: status %d, want %d", tt.token, got.Status, tt.status)
+		}
+	}
+}
+
+func TestUpgradeMiddleware(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"channel": "required AND in:news,sport"}
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"plain request", nil, 200},
+		{"websocket", map[string]string{fiber.HeaderConnection: "keep-alive, Upgrade", fiber.HeaderUpgrade: "WebSocket"}, 400},
+		{"upgrade to another protocol", map[string]string{fiber.HeaderConnection: "Upgrade", fiber.HeaderUpgrade: "h2c"}, 200},
+		{"event stream", map[string]string{fiber.HeaderAccept: "text/event-stream"}, 400},
+		{"event stream refused", map[string]string{fiber.HeaderAccept: "text/event-stream;q=0, text/html"}, 200},
+	}
+	for _, tt := range tests {
+		got := serveQuery(t, "/", "/?channel=weather", nil, func(c fiber.Ctx) error {
+			for k, v := range tt.headers {
+				c.Request().Header.Set(k, v)
+			}
+			return c.Next()
+		}, qv.UpgradeMiddleware(rules))
+		if got.Status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, got.Status, tt.status)
+		}
+	}
+}
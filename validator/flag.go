@@ -0,0 +1,26 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// flagType is the type of presence flags: ?verbose alone means true, and
+// the boolean spellings are accepted too, so ?verbose=false turns it off.
+const flagType = "flag"
+
+// parseFlag reads the value of a flag that was sent.
+func parseFlag(v string) (bool, error) {
+	if v == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(strings.ToLower(v))
+}
+
+func validFlag(v string) bool {
+	if v == "" {
+		return true
+	}
+	v = strings.ToLower(v)
+	return v == "true" || v == "false" || v == "1" || v == "0"
+}
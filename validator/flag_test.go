@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestFlagType(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		value string
+		on    bool
+		valid bool
+	}{
+		{"", true, true},
+		{"true", true, true},
+		{"TRUE", true, true},
+		{"1", true, true},
+		{"false", false, true},
+		{"0", false, true},
+		{"yes", false, false},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(map[string]string{"verbose": tt.value}, map[string]string{"verbose": "flag"})
+		if (len(errs) == 0) != tt.valid {
+			t.Errorf("verbose=%q: errors %v, want valid %v", tt.value, errorCodes(errs), tt.valid)
+		}
+		if !tt.valid {
+			continue
+		}
+		result := qv.ValidateValuesResult(url.Values{"verbose": {tt.value}}, map[string]string{"verbose": "flag"})
+		if result.Flag("verbose") != tt.on {
+			t.Errorf("verbose=%q: Flag = %v, want %v", tt.value, result.Flag("verbose"), tt.on)
+		}
+		if on, ok := Flag().Value(map[string]string{"verbose": tt.value}, "verbose"); !ok || on != tt.on {
+			t.Errorf("verbose=%q: typed Value = %v, %v, want %v", tt.value, on, ok, tt.on)
+		}
+		want := "verbose=false"
+		if tt.on {
+			want = "verbose=true"
+		}
+		if got, _ := qv.CanonicalMap(map[string]string{"verbose": tt.value}, map[string]string{"verbose": "flag"}); got != want {
+			t.Errorf("verbose=%q: canonical %q, want %q", tt.value, got, want)
+		}
+	}
+	if _, ok := Flag().Value(map[string]string{}, "verbose"); ok {
+		t.Error("absent flag has a value")
+	}
+}
@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// ValidUUID reports whether v is a UUID in its hyphenated form, of any
+// version, as in RFC 9562.
+func ValidUUID(v string) bool {
+	if len(v) != 36 {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if v[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(v[i : i+1]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ValidUUIDv4 reports whether v is a random (version 4) UUID with the RFC
+// 9562 variant.
+func ValidUUIDv4(v string) bool {
+	return ValidUUID(v) && v[14] == '4' && strings.IndexByte("89abAB", v[19]) >= 0
+}
+
+// ValidEmail reports whether v is a bare address such as user@example.com
+// whose domain is a valid hostname in either IDN form. Display names and
+// angle brackets are rejected.
+func ValidEmail(v string) bool {
+	return NewEmailValidator(IDNAcceptBoth)(v)
+}
+
+// NewEmailValidator returns a type validator accepting bare addresses whose
+// domain is in the forms allowed by policy, as CanonicalHostname checks.
+func NewEmailValidator(policy IDNPolicy) func(string) bool {
+	return func(v string) bool {
+		addr, err := mail.ParseAddress(v)
+		if err != nil || addr.Name != "" || addr.Address != v {
+			return false
+		}
+		at := strings.LastIndexByte(v, '@')
+		return at >= 0 && validHost(v[at+1:], policy)
+	}
+}
+
+// ValidURL reports whether v is an absolute URL with a scheme and a host,
+// which must be an IP address or a valid hostname in either IDN form.
+func ValidURL(v string) bool {
+	return NewURLValidator(IDNAcceptBoth)(v)
+}
+
+// NewURLValidator returns a type validator accepting absolute URLs whose
+// host is an IP address or a hostname in the forms allowed by policy.
+func NewURLValidator(policy IDNPolicy) func(string) bool {
+	return func(v string) bool {
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != "" && u.Host != "" && validHost(u.Hostname(), policy)
+	}
+}
+
+// validHost reports whether host is an IP address, bracketed or not, or a
+// hostname CanonicalHostname accepts under policy.
+func validHost(host string, policy IDNPolicy) bool {
+	if _, err := netip.ParseAddr(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")); err == nil {
+		return true
+	}
+	_, err := CanonicalHostname(host, policy)
+	return err == nil
+}
+
+// ValidIP reports whether v is an IPv4 or IPv6 address.
+func ValidIP(v string) bool {
+	_, err := netip.ParseAddr(v)
+	return err == nil
+}
+
+// ValidIPv4 reports whether v is a dotted-quad IPv4 address.
+func ValidIPv4(v string) bool {
+	addr, err := netip.ParseAddr(v)
+	return err == nil && addr.Is4()
+}
+
+// ValidIPv6 reports whether v is an IPv6 address, including IPv4-mapped ones.
+func ValidIPv6(v string) bool {
+	addr, err := netip.ParseAddr(v)
+	return err == nil && addr.Is6()
+}
+
+// ValidCIDR reports whether v is an IPv4 or IPv6 prefix such as 10.0.0.0/8.
+// Prefixes with host bits set, such as 10.0.0.1/8, are rejected.
+func ValidCIDR(v string) bool {
+	prefix, err := netip.ParsePrefix(v)
+	return err == nil && prefix.Masked() == prefix
+}
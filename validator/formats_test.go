@@ -0,0 +1,56 @@
+package validator
+
+import "testing"
+
+func TestFormatTypes(t *testing.T) {
+	tests := []struct {
+		typ   string
+		value string
+		want  bool
+	}{
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123E4567-E89B-12D3-A456-426614174000", true},
+		{"uuid", "123e4567e89b12d3a456426614174000", false},
+		{"uuid", "123e4567-e89b-12d3-a456-42661417400g", false},
+		{"uuidv4", "f47ac10b-58cc-4372-a567-0e02b2c3d479", true},
+		{"uuidv4", "123e4567-e89b-12d3-a456-426614174000", false},
+		{"uuidv4", "f47ac10b-58cc-4372-c567-0e02b2c3d479", false},
+		{"email", "user@example.com", true},
+		{"email", "Gopher <user@example.com>", false},
+		{"email", "user", false},
+		{"email", "user@bücher.example", true},
+		{"email", "user@[192.0.2.1]", true},
+		{"email", "user@xn--a.com", false},
+		{"email", "user@under_score.example", false},
+		{"url", "https://example.com/path?q=1", true},
+		{"url", "/relative/path", false},
+		{"url", "example.com", false},
+		{"url", "http://127.0.0.1:8080/", true},
+		{"url", "http://[::1]/", true},
+		{"url", "https://bücher.example/", true},
+		{"url", "https://xn--a.com/", false},
+		{"url", "https://under_score.example/", false},
+		{"ip", "192.0.2.1", true},
+		{"ip", "2001:db8::1", true},
+		{"ip", "192.0.2.256", false},
+		{"ipv4", "192.0.2.1", true},
+		{"ipv4", "2001:db8::1", false},
+		{"ipv6", "2001:db8::1", true},
+		{"ipv6", "::ffff:192.0.2.1", true},
+		{"ipv6", "192.0.2.1", false},
+		{"cidr", "10.0.0.0/8", true},
+		{"cidr", "2001:db8::/32", true},
+		{"cidr", "10.0.0.1/8", false},
+		{"cidr", "2001:db8::1/32", false},
+		{"cidr", "10.0.0.1/32", true},
+		{"cidr", "10.0.0.0", false},
+		{"cidr", "10.0.0.0/33", false},
+	}
+	qv := NewQueryValidator()
+	for _, tt := range tests {
+		errors := qv.ValidateMap(map[string]string{"v": tt.value}, map[string]string{"v": tt.typ})
+		if got := len(errors) == 0; got != tt.want {
+			t.Errorf("%s %q valid = %v, want %v", tt.typ, tt.value, got, tt.want)
+		}
+	}
+}
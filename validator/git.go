@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"strings"
+)
+
+// Git object name lengths for SHA-1 and SHA-256 repositories.
+const (
+	gitSHA1Length   = 40
+	gitSHA256Length = 64
+	gitShortSHAMin  = 7
+)
+
+// ValidGitSHA reports whether v is a full SHA-1 or SHA-256 object name.
+func ValidGitSHA(v string) bool {
+	return (len(v) == gitSHA1Length || len(v) == gitSHA256Length) && isHex(v)
+}
+
+// ValidGitShortSHA reports whether v is an abbreviated or full object name.
+func ValidGitShortSHA(v string) bool {
+	return len(v) >= gitShortSHAMin && len(v) <= gitSHA256Length && isHex(v)
+}
+
+// ValidGitRefName reports whether v is a ref name git-check-ref-format would
+// accept with --allow-onelevel, so both "main" and "refs/tags/v1.2.0" pass.
+func ValidGitRefName(v string) bool {
+	if v == "" || v == "@" {
+		return false
+	}
+	if strings.HasPrefix(v, "/") || strings.HasSuffix(v, "/") || strings.HasSuffix(v, ".") {
+		return false
+	}
+	if strings.Contains(v, "..") || strings.Contains(v, "//") || strings.Contains(v, "@{") {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c < 0x20 || c == 0x7f || strings.IndexByte(" ~^:?*[\\", c) >= 0 {
+			return false
+		}
+	}
+	for _, component := range strings.Split(v, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return false
+		}
+	}
+	return true
+}
+
+func isHex(v string) bool {
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
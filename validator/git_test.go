@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGitSHA(t *testing.T) {
+	sha1 := "3f786850e387550fdab836ed7e6dc881de23001b"
+	sha256 := strings.Repeat("ab", 32)
+	tests := []struct {
+		value       string
+		full, short bool
+	}{
+		{sha1, true, true},
+		{strings.ToUpper(sha1), true, true},
+		{sha256, true, true},
+		{sha1[:7], false, true},
+		{sha1[:12], false, true},
+		{sha1[:6], false, false},
+		{sha1[:39] + "g", false, false},
+		{sha256 + "a", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if got := ValidGitSHA(tt.value); got != tt.full {
+			t.Errorf("ValidGitSHA(%q) = %v, want %v", tt.value, got, tt.full)
+		}
+		if got := ValidGitShortSHA(tt.value); got != tt.short {
+			t.Errorf("ValidGitShortSHA(%q) = %v, want %v", tt.value, got, tt.short)
+		}
+	}
+}
+
+func TestValidGitRefName(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"main", true},
+		{"refs/tags/v1.2.0", true},
+		{"feature/login-form", true},
+		{"a.b", true},
+		{"", false},
+		{"@", false},
+		{"/main", false},
+		{"main/", false},
+		{"main.", false},
+		{"a..b", false},
+		{"a//b", false},
+		{"a@{1}", false},
+		{"has space", false},
+		{"a~1", false},
+		{"a^", false},
+		{"a:b", false},
+		{"a?", false},
+		{"a*", false},
+		{"a[b", false},
+		{`a\b`, false},
+		{"a\x7fb", false},
+		{"refs/.hidden", false},
+		{"refs/heads/main.lock", false},
+	}
+	for _, tt := range tests {
+		if got := ValidGitRefName(tt.ref); got != tt.want {
+			t.Errorf("ValidGitRefName(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestGitTypes(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"commit": "sha", "base": "shortsha", "ref": "gitref"}
+	checkCodes(t, qv, map[string]string{"commit": strings.Repeat("a", 40), "base": "abc1234", "ref": "main"}, rules)
+	checkCodes(t, qv, map[string]string{"commit": "abc1234", "base": "xyz", "ref": "a..b"}, rules,
+		"commit:INVALID_TYPE", "base:INVALID_TYPE", "ref:INVALID_TYPE")
+}
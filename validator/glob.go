@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// globMetacharacters are the characters path.Match gives special meaning to.
+const globMetacharacters = `*?[]\`
+
+// GlobOptions bounds the patterns accepted by a glob validator.
+type GlobOptions struct {
+	// AllowedMeta lists the metacharacters a pattern may use; any other
+	// glob metacharacter makes the value invalid.
+	AllowedMeta string
+	// MaxWildcards caps the number of '*' and '?' in a pattern. Zero means no limit.
+	MaxWildcards int
+	// MaxLength caps the pattern length in bytes. Zero means no limit.
+	MaxLength int
+}
+
+// DefaultGlobOptions is used for the built-in "glob" type.
+var DefaultGlobOptions = GlobOptions{
+	AllowedMeta:  "*?",
+	MaxWildcards: 4,
+	MaxLength:    128,
+}
+
+// NewGlobValidator returns a type validator accepting values that are safe
+// glob patterns under opts, e.g. `prod-*`.
+func NewGlobValidator(opts GlobOptions) func(string) bool {
+	return func(v string) bool {
+		if v == "" {
+			return false
+		}
+		if opts.MaxLength > 0 && len(v) > opts.MaxLength {
+			return false
+		}
+
+		wildcards := 0
+		for _, r := range v {
+			if unicode.IsControl(r) {
+				return false
+			}
+			if !strings.ContainsRune(globMetacharacters, r) {
+				continue
+			}
+			if !strings.ContainsRune(opts.AllowedMeta, r) {
+				return false
+			}
+			if r == '*' || r == '?' {
+				wildcards++
+			}
+		}
+		if opts.MaxWildcards > 0 && wildcards > opts.MaxWildcards {
+			return false
+		}
+
+		// Catches unbalanced classes and dangling escapes.
+		_, err := path.Match(v, "")
+		return err == nil
+	}
+}
@@ -0,0 +1,43 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlobValidator(t *testing.T) {
+	valid := NewGlobValidator(DefaultGlobOptions)
+	withClasses := NewGlobValidator(GlobOptions{AllowedMeta: `*?[]\`})
+	tests := []struct {
+		value        string
+		def, classes bool
+	}{
+		{"prod-*", true, true},
+		{"us-?-1", true, true},
+		{"exact", true, true},
+		{"", false, false},
+		{"a*b*c*d*", true, true},
+		{"*a*b*c*d*", false, true},
+		{"[abc]-*", false, true},
+		{"[abc", false, false},
+		{`a\`, false, false},
+		{`a\*b`, false, true},
+		{"tab\there", false, false},
+		{strings.Repeat("a", 129), false, true},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.value); got != tt.def {
+			t.Errorf("default glob %q = %v, want %v", tt.value, got, tt.def)
+		}
+		if got := withClasses(tt.value); got != tt.classes {
+			t.Errorf("glob with classes %q = %v, want %v", tt.value, got, tt.classes)
+		}
+	}
+}
+
+func TestGlobType(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"env": "glob"}
+	checkCodes(t, qv, map[string]string{"env": "prod-*"}, rules)
+	checkCodes(t, qv, map[string]string{"env": "[prod]"}, rules, "env:INVALID_TYPE")
+}
@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GraphQLOptions configures GraphQLRules. Zero lengths leave a parameter
+// uncapped.
+type GraphQLOptions struct {
+	// MaxQueryLength, MaxVariablesLength and MaxExtensionsLength cap the
+	// query, variables and extensions parameters in characters.
+	MaxQueryLength      int
+	MaxVariablesLength  int
+	MaxExtensionsLength int
+	// PersistedOnly rejects query documents, so only persisted queries
+	// named by extensions.persistedQuery.sha256Hash can run.
+	PersistedOnly bool
+}
+
+// DefaultGraphQLOptions suit typical public GraphQL endpoints.
+var DefaultGraphQLOptions = GraphQLOptions{
+	MaxQueryLength:      8 << 10,
+	MaxVariablesLength:  8 << 10,
+	MaxExtensionsLength: 1 << 10,
+}
+
+// GraphQLRules returns rules for the GraphQL-over-GET parameters: query,
+// operationName, variables as a JSON object and extensions, whose
+// persistedQuery must carry version 1 and a SHA-256 hex hash. A query is
+// required unless extensions is sent. Add GraphQLPersistedQueryHash as a
+// cross rule to also check that a sent query matches its hash.
+//
+//	app.Get("/graphql", handler, qv.Middleware(validator.GraphQLRules(validator.DefaultGraphQLOptions)))
+func GraphQLRules(opts GraphQLOptions) Rules {
+	rules := Rules{
+		"operationName": "graphqlName",
+		"variables":     "jsonObject" + maxLenTerm(opts.MaxVariablesLength),
+	}
+	if opts.PersistedOnly {
+		rules["extensions"] = "required AND graphqlPersistedQuery" + maxLenTerm(opts.MaxExtensionsLength)
+		return rules
+	}
+	rules["query"] = "string AND required_unless:extensions" + maxLenTerm(opts.MaxQueryLength)
+	rules["extensions"] = "graphqlExtensions" + maxLenTerm(opts.MaxExtensionsLength)
+	return rules
+}
+
+func maxLenTerm(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return " AND maxlen:" + strconv.Itoa(n)
+}
+
+// GraphQLPersistedQueryHash is a cross rule rejecting requests whose query
+// does not hash to extensions.persistedQuery.sha256Hash, as automatic
+// persisted query registrations send both.
+func GraphQLPersistedQueryHash(values map[string]string) []QueryValidationError {
+	query, ok := values["query"]
+	if !ok {
+		return nil
+	}
+	pq, ok := parsePersistedQuery(values["extensions"])
+	if !ok || pq == nil {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(query))
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), pq.SHA256Hash) {
+		return []QueryValidationError{newValidationError("extensions", values["extensions"], fail(MsgChecksumMismatch, "query"))}
+	}
+	return nil
+}
+
+var graphQLName = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// ValidGraphQLName reports whether v is a GraphQL name, as operation names are.
+func ValidGraphQLName(v string) bool {
+	return graphQLName.MatchString(v)
+}
+
+// ValidJSONObject reports whether v is a JSON object.
+func ValidJSONObject(v string) bool {
+	var object map[string]json.RawMessage
+	return json.Unmarshal([]byte(v), &object) == nil && object != nil
+}
+
+// ValidGraphQLExtensions reports whether v is a JSON object whose
+// persistedQuery, if any, is well formed.
+func ValidGraphQLExtensions(v string) bool {
+	_, ok := parsePersistedQuery(v)
+	return ok
+}
+
+// ValidGraphQLPersistedQuery is ValidGraphQLExtensions also requiring a
+// persistedQuery.
+func ValidGraphQLPersistedQuery(v string) bool {
+	pq, ok := parsePersistedQuery(v)
+	return ok && pq != nil
+}
+
+type persistedQuery struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+// parsePersistedQuery parses extensions, yielding a nil persistedQuery when
+// it has none. ok is false for malformed extensions.
+func parsePersistedQuery(extensions string) (pq *persistedQuery, ok bool) {
+	var object struct {
+		PersistedQuery *persistedQuery `json:"persistedQuery"`
+	}
+	if !ValidJSONObject(extensions) || json.Unmarshal([]byte(extensions), &object) != nil {
+		return nil, false
+	}
+	pq = object.PersistedQuery
+	if pq != nil && (pq.Version != 1 || len(pq.SHA256Hash) != sha256.Size*2 || !isHex(pq.SHA256Hash)) {
+		return nil, false
+	}
+	return pq, true
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+const graphQLQuery = "{ viewer { login } }"
+
+// persistedExtensions returns extensions naming the persisted query hash.
+func persistedExtensions(hash string) string {
+	return `{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}`
+}
+
+func queryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestGraphQLRules(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := GraphQLRules(GraphQLOptions{MaxQueryLength: 30, MaxVariablesLength: 20})
+	hash := queryHash(graphQLQuery)
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []string
+	}{
+		{"query", map[string]string{"query": graphQLQuery, "operationName": "Viewer", "variables": `{"id":1}`}, nil},
+		{"persisted query", map[string]string{"extensions": persistedExtensions(hash)}, nil},
+		{"nothing to run", map[string]string{"operationName": "Viewer"}, []string{"query:REQUIRED_WITHOUT"}},
+		{"query too long", map[string]string{"query": strings.Repeat("x", 31)}, []string{"query:TOO_LONG"}},
+		{"bad operation name", map[string]string{"query": graphQLQuery, "operationName": "1st"}, []string{"operationName:INVALID_TYPE"}},
+		{"variables array", map[string]string{"query": graphQLQuery, "variables": "[1]"}, []string{"variables:INVALID_TYPE"}},
+		{"variables too long", map[string]string{"query": graphQLQuery, "variables": `{"a":"` + strings.Repeat("x", 20) + `"}`}, []string{"variables:TOO_LONG"}},
+		{"short hash", map[string]string{"extensions": persistedExtensions(hash[:10])}, []string{"extensions:INVALID_TYPE"}},
+		{"wrong version", map[string]string{"extensions": `{"persistedQuery":{"version":2,"sha256Hash":"` + hash + `"}}`}, []string{"extensions:INVALID_TYPE"}},
+		{"other extensions", map[string]string{"query": graphQLQuery, "extensions": `{"tracing":true}`}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkCodes(t, qv, tt.values, rules, tt.want...)
+		})
+	}
+}
+
+func TestGraphQLPersistedOnly(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := GraphQLRules(GraphQLOptions{PersistedOnly: true})
+	checkCodes(t, qv, map[string]string{"extensions": persistedExtensions(queryHash(graphQLQuery))}, rules)
+	checkCodes(t, qv, map[string]string{"query": graphQLQuery}, rules, "extensions:MISSING_REQUIRED", "query:UNEXPECTED_PARAM")
+	checkCodes(t, qv, map[string]string{"extensions": `{"tracing":true}`}, rules, "extensions:INVALID_TYPE")
+}
+
+func TestGraphQLPersistedQueryHash(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddCrossRule(GraphQLPersistedQueryHash)
+	rules := GraphQLRules(DefaultGraphQLOptions)
+	hash := queryHash(graphQLQuery)
+	checkCodes(t, qv, map[string]string{"query": graphQLQuery, "extensions": persistedExtensions(hash)}, rules)
+	checkCodes(t, qv, map[string]string{"query": graphQLQuery, "extensions": persistedExtensions(strings.ToUpper(hash))}, rules)
+	checkCodes(t, qv, map[string]string{"query": "{ other }", "extensions": persistedExtensions(hash)}, rules, "extensions:CHECKSUM_MISMATCH")
+	checkCodes(t, qv, map[string]string{"query": graphQLQuery}, rules)
+}
@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// IDNPolicy controls which forms of an internationalized domain name are
+// accepted by hostname-based types.
+type IDNPolicy int
+
+const (
+	// IDNAcceptBoth accepts the unicode (U-label) and punycode (A-label) forms.
+	IDNAcceptBoth IDNPolicy = iota
+	// IDNRequireASCII only accepts the punycode form, e.g. xn--bcher-kva.example.
+	IDNRequireASCII
+	// IDNRequireUnicode only accepts the unicode form, e.g. bücher.example.
+	IDNRequireUnicode
+)
+
+var hostnameProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.ValidateLabels(true),
+	idna.StrictDomainName(true),
+	idna.VerifyDNSLength(true),
+)
+
+// NewHostnameValidator returns a type validator accepting DNS hostnames in the
+// forms allowed by policy.
+func NewHostnameValidator(policy IDNPolicy) func(string) bool {
+	return func(v string) bool {
+		_, err := CanonicalHostname(v, policy)
+		return err == nil
+	}
+}
+
+// SetIDNPolicy sets the forms of internationalized domain names the
+// hostname type accepts, and the email and url types in their domains.
+// IDNAcceptBoth is the default.
+func (qv *QueryValidator) SetIDNPolicy(policy IDNPolicy) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.typeValidators["hostname"] = NewHostnameValidator(policy)
+	qv.typeValidators["email"] = NewEmailValidator(policy)
+	qv.typeValidators["url"] = NewURLValidator(policy)
+}
+
+// CanonicalHostname validates host under policy and returns its canonical
+// form: unicode for IDNRequireUnicode, punycode otherwise. Comparing
+// canonical forms lets both spellings of the same domain match.
+func CanonicalHostname(host string, policy IDNPolicy) (string, error) {
+	host = strings.TrimSuffix(host, ".")
+
+	switch policy {
+	case IDNRequireASCII:
+		if !isASCII(host) {
+			return "", fmt.Errorf("hostname %s is not in punycode form", host)
+		}
+	case IDNRequireUnicode:
+		for _, label := range strings.Split(host, ".") {
+			if strings.HasPrefix(strings.ToLower(label), "xn--") {
+				return "", fmt.Errorf("hostname %s is not in unicode form", host)
+			}
+		}
+	}
+
+	ascii, err := hostnameProfile.ToASCII(host)
+	if err != nil {
+		return "", err
+	}
+	if policy != IDNRequireUnicode {
+		return ascii, nil
+	}
+	return hostnameProfile.ToUnicode(ascii)
+}
+
+// ToASCIIHostname converts a hostname to its punycode form.
+func ToASCIIHostname(host string) (string, error) {
+	return hostnameProfile.ToASCII(strings.TrimSuffix(host, "."))
+}
+
+// ToUnicodeHostname converts a hostname to its unicode form.
+func ToUnicodeHostname(host string) (string, error) {
+	ascii, err := ToASCIIHostname(host)
+	if err != nil {
+		return "", err
+	}
+	return hostnameProfile.ToUnicode(ascii)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
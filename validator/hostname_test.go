@@ -0,0 +1,75 @@
+package validator
+
+import "testing"
+
+func TestCanonicalHostname(t *testing.T) {
+	tests := []struct {
+		host   string
+		policy IDNPolicy
+		want   string
+		ok     bool
+	}{
+		{"example.com", IDNAcceptBoth, "example.com", true},
+		{"Example.COM.", IDNAcceptBoth, "example.com", true},
+		{"bücher.example", IDNAcceptBoth, "xn--bcher-kva.example", true},
+		{"xn--bcher-kva.example", IDNAcceptBoth, "xn--bcher-kva.example", true},
+		{"bücher.example", IDNRequireASCII, "", false},
+		{"xn--bcher-kva.example", IDNRequireASCII, "xn--bcher-kva.example", true},
+		{"bücher.example", IDNRequireUnicode, "bücher.example", true},
+		{"xn--bcher-kva.example", IDNRequireUnicode, "", false},
+		{"under_score.example", IDNAcceptBoth, "", false},
+		{"-leading.example", IDNAcceptBoth, "", false},
+		{"a..b", IDNAcceptBoth, "", false},
+		{"", IDNAcceptBoth, "", false},
+	}
+	for _, tt := range tests {
+		got, err := CanonicalHostname(tt.host, tt.policy)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("CanonicalHostname(%q, %d) = %q, %v, want %q, ok %v", tt.host, tt.policy, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHostnameConversions(t *testing.T) {
+	if got, err := ToASCIIHostname("münchen.de."); err != nil || got != "xn--mnchen-3ya.de" {
+		t.Errorf("ToASCIIHostname = %q, %v", got, err)
+	}
+	if got, err := ToUnicodeHostname("xn--mnchen-3ya.de"); err != nil || got != "münchen.de" {
+		t.Errorf("ToUnicodeHostname = %q, %v", got, err)
+	}
+}
+
+func TestHostnameType(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"host": "hostname"}
+	checkCodes(t, qv, map[string]string{"host": "bücher.example"}, rules)
+	checkCodes(t, qv, map[string]string{"host": "xn--bcher-kva.example"}, rules)
+	checkCodes(t, qv, map[string]string{"host": "not a host"}, rules, "host:INVALID_TYPE")
+}
+
+func TestIDNPolicyAppliesToDomains(t *testing.T) {
+	// "p\u0430ypal.com" spells paypal with a Cyrillic a.
+	tests := []struct {
+		policy IDNPolicy
+		ok     bool
+	}{
+		{IDNAcceptBoth, true},
+		{IDNRequireASCII, false},
+	}
+	rules := map[string]string{"host": "hostname", "email": "email", "url": "url"}
+	values := map[string]string{
+		"host":  "p\u0430ypal.com",
+		"email": "user@p\u0430ypal.com",
+		"url":   "https://p\u0430ypal.com/login",
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		qv.SetIDNPolicy(tt.policy)
+		if tt.ok {
+			checkCodes(t, qv, values, rules)
+		} else {
+			checkCodes(t, qv, values, rules, "email:INVALID_TYPE", "host:INVALID_TYPE", "url:INVALID_TYPE")
+		}
+		checkCodes(t, qv, map[string]string{"email": "user@xn--pypal-4ve.com", "url": "https://xn--pypal-4ve.com/"}, rules)
+	}
+}
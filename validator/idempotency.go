@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"strings"
+)
+
+// IdempotencyStore reports whether an idempotency key has already been used.
+type IdempotencyStore interface {
+	Seen(key string) bool
+}
+
+// IdempotencyKeyOptions configures an idempotency key validator.
+type IdempotencyKeyOptions struct {
+	// AllowUUID accepts canonical UUIDs regardless of Charset and length bounds.
+	AllowUUID bool
+	// MinLength and MaxLength bound opaque tokens in bytes.
+	MinLength int
+	MaxLength int
+	// Charset lists the bytes an opaque token may contain.
+	Charset string
+	// Store, when set, rejects keys it has already seen.
+	Store IdempotencyStore
+}
+
+const urlSafeCharset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// DefaultIdempotencyKeyOptions is used for the built-in "idempotencyKey" type.
+var DefaultIdempotencyKeyOptions = IdempotencyKeyOptions{
+	AllowUUID: true,
+	MinLength: 16,
+	MaxLength: 64,
+	Charset:   urlSafeCharset,
+}
+
+// NewIdempotencyKeyValidator returns a type validator accepting UUIDs or
+// opaque tokens as configured by opts.
+func NewIdempotencyKeyValidator(opts IdempotencyKeyOptions) func(string) bool {
+	return func(v string) bool {
+		if !(opts.AllowUUID && ValidUUID(v)) && !validOpaqueToken(v, opts) {
+			return false
+		}
+		return opts.Store == nil || !opts.Store.Seen(v)
+	}
+}
+
+func validOpaqueToken(v string, opts IdempotencyKeyOptions) bool {
+	if len(v) == 0 || len(v) < opts.MinLength {
+		return false
+	}
+	if opts.MaxLength > 0 && len(v) > opts.MaxLength {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if strings.IndexByte(opts.Charset, v[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
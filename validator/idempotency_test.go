@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+type seenKeys map[string]bool
+
+func (s seenKeys) Seen(key string) bool { return s[key] }
+
+func TestIdempotencyKeyValidator(t *testing.T) {
+	const uuid = "3f2504e0-4f89-41d3-9a0c-0305e82c3301"
+	valid := NewIdempotencyKeyValidator(DefaultIdempotencyKeyOptions)
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{uuid, true},
+		{strings.ToUpper(uuid), true},
+		{"{3f2504e0-4f89-41d3-9a0c-0305e82c3301}", false},
+		{"3f2504e0_4f89_41d3_9a0c_0305e82c3301", true},
+		{"abcdefghijklmnop", true},
+		{"abcdefghijklmno", false},
+		{strings.Repeat("a", 64), true},
+		{strings.Repeat("a", 65), false},
+		{"abcdefgh+ijklmnop", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.key); got != tt.want {
+			t.Errorf("idempotency key %q = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestIdempotencyKeyOptions(t *testing.T) {
+	const uuid = "3f2504e0-4f89-41d3-9a0c-0305e82c3301"
+	opts := IdempotencyKeyOptions{MinLength: 4, Charset: "0123456789", Store: seenKeys{"1234": true}}
+	valid := NewIdempotencyKeyValidator(opts)
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"5678", true},
+		{"1234", false},
+		{"123", false},
+		{strings.Repeat("9", 200), true},
+		{"12a4", false},
+		{uuid, false},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.key); got != tt.want {
+			t.Errorf("idempotency key %q = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestIdempotencyKeyType(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"key": "idempotencyKey"}
+	checkCodes(t, qv, map[string]string{"key": "3f2504e0-4f89-41d3-9a0c-0305e82c3301"}, rules)
+	checkCodes(t, qv, map[string]string{"key": "short"}, rules, "key:INVALID_TYPE")
+}
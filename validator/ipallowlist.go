@@ -0,0 +1,58 @@
+package validator
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// NewIPAllowListValidator returns a type validator accepting IP addresses
+// contained in one of cidrs. Bare addresses are treated as single-host
+// prefixes. The prefixes are parsed once, here, rather than per request.
+func NewIPAllowListValidator(cidrs ...string) (func(string) bool, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		var (
+			prefix netip.Prefix
+			err    error
+		)
+		if strings.Contains(cidr, "/") {
+			prefix, err = netip.ParsePrefix(cidr)
+		} else {
+			var addr netip.Addr
+			addr, err = netip.ParseAddr(cidr)
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %s: %v", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return func(v string) bool {
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, prefix := range prefixes {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// AddIPAllowList registers a type named name that only accepts addresses
+// inside cidrs.
+func (qv *QueryValidator) AddIPAllowList(name string, cidrs ...string) error {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	validator, err := NewIPAllowListValidator(cidrs...)
+	if err != nil {
+		return fmt.Errorf("invalid allow-list for %s: %v", name, err)
+	}
+	qv.typeValidators[name] = validator
+	return nil
+}
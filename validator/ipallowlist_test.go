@@ -0,0 +1,49 @@
+package validator
+
+import "testing"
+
+func TestIPAllowListValidator(t *testing.T) {
+	valid, err := NewIPAllowListValidator("10.0.0.0/8", "192.168.1.7", "2001:db8::/32", "172.16.5.4/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.1.2.3", true},
+		{"11.0.0.1", false},
+		{"192.168.1.7", true},
+		{"192.168.1.8", false},
+		{"::ffff:10.0.0.1", true},
+		{"2001:db8::1", true},
+		{"2001:db9::1", false},
+		{"172.16.200.1", true},
+		{"10.0.0.1/32", false},
+		{"not-an-ip", false},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.addr); got != tt.want {
+			t.Errorf("allow-list %s = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"10.0.0.0/33", "example.com", ""} {
+		if _, err := NewIPAllowListValidator(bad); err == nil {
+			t.Errorf("NewIPAllowListValidator(%q) returned no error", bad)
+		}
+	}
+}
+
+func TestAddIPAllowList(t *testing.T) {
+	qv := NewQueryValidator()
+	if err := qv.AddIPAllowList("office", "203.0.113.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if err := qv.AddIPAllowList("broken", "203.0.113.0/99"); err == nil {
+		t.Error("invalid allow-list registered")
+	}
+	rules := map[string]string{"client": "office"}
+	checkCodes(t, qv, map[string]string{"client": "203.0.113.9"}, rules)
+	checkCodes(t, qv, map[string]string{"client": "198.51.100.1"}, rules, "client:INVALID_TYPE")
+}
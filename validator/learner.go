@@ -0,0 +1,179 @@
+package validator
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// learnableTypes are tried in order when inferring a parameter's type; the
+// first type that accepted every observed value wins.
+var learnableTypes = []string{"date", "number", "boolean", "sha"}
+
+// maxLearnedValues is how many distinct values are kept per parameter to
+// suggest an enum.
+const maxLearnedValues = 10
+
+// Learner watches live traffic for a while and drafts rules for endpoints
+// nobody documented. It never rejects a request.
+type Learner struct {
+	qv    *QueryValidator
+	until time.Time
+
+	mu     sync.Mutex
+	routes map[string]map[string]*paramObservation
+}
+
+type paramObservation struct {
+	count      int
+	candidates []string
+	minNumber  float64
+	maxNumber  float64
+	minLength  int
+	maxLength  int
+	values     []string
+	manyValues bool
+}
+
+// LearnedParam is what a Learner concluded about one parameter.
+type LearnedParam struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+	// Min and Max are the observed numeric range for number parameters.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// MinLength and MaxLength are the observed lengths in runes.
+	MinLength int `json:"minLength"`
+	MaxLength int `json:"maxLength"`
+	// Values holds every distinct value seen, when there were few enough
+	// to suggest an enum.
+	Values []string `json:"values,omitempty"`
+}
+
+// LearnedRoute is the draft schema for one route.
+type LearnedRoute struct {
+	Route  string         `json:"route"`
+	Params []LearnedParam `json:"params"`
+}
+
+// NewLearner returns a Learner that observes requests for period, inferring
+// types from qv's type validators. A zero period observes indefinitely.
+func (qv *QueryValidator) NewLearner(period time.Duration) *Learner {
+	l := &Learner{
+		qv:     qv,
+		routes: make(map[string]map[string]*paramObservation),
+	}
+	if period > 0 {
+		l.until = time.Now().Add(period)
+	}
+	return l
+}
+
+// Observe records one request's query for route.
+func (l *Learner) Observe(route string, values map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	params := l.routes[route]
+	if params == nil {
+		params = make(map[string]*paramObservation)
+		l.routes[route] = params
+	}
+	for name, value := range values {
+		obs := params[name]
+		if obs == nil {
+			obs = &paramObservation{
+				candidates: slices.Clone(learnableTypes),
+				minNumber:  math.Inf(1),
+				maxNumber:  math.Inf(-1),
+				minLength:  math.MaxInt,
+			}
+			params[name] = obs
+		}
+		l.record(obs, value)
+	}
+}
+
+func (l *Learner) record(obs *paramObservation, value string) {
+	l.qv.mu.RLock()
+	defer l.qv.mu.RUnlock()
+	obs.count++
+	obs.candidates = slices.DeleteFunc(obs.candidates, func(t string) bool {
+		return !l.qv.validateParamValue(value, t)
+	})
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		obs.minNumber = min(obs.minNumber, n)
+		obs.maxNumber = max(obs.maxNumber, n)
+	}
+	length := utf8.RuneCountInString(value)
+	obs.minLength = min(obs.minLength, length)
+	obs.maxLength = max(obs.maxLength, length)
+
+	if obs.manyValues || slices.Contains(obs.values, value) {
+		return
+	}
+	if len(obs.values) == maxLearnedValues {
+		obs.values, obs.manyValues = nil, true
+		return
+	}
+	obs.values = append(obs.values, value)
+}
+
+// Draft returns what was learned so far, ordered by route and parameter.
+func (l *Learner) Draft() []LearnedRoute {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	draft := make([]LearnedRoute, 0, len(l.routes))
+	for route, params := range l.routes {
+		learned := LearnedRoute{Route: route, Params: make([]LearnedParam, 0, len(params))}
+		for name, obs := range params {
+			learned.Params = append(learned.Params, obs.learned(name))
+		}
+		sort.Slice(learned.Params, func(i, j int) bool {
+			return learned.Params[i].Name < learned.Params[j].Name
+		})
+		draft = append(draft, learned)
+	}
+	sort.Slice(draft, func(i, j int) bool { return draft[i].Route < draft[j].Route })
+	return draft
+}
+
+// DraftRules returns the inferred rules per route, ready to review and pass
+// to ValidateQuery.
+func (l *Learner) DraftRules() map[string]map[string]string {
+	rules := make(map[string]map[string]string)
+	for _, route := range l.Draft() {
+		rules[route.Route] = make(map[string]string, len(route.Params))
+		for _, p := range route.Params {
+			rules[route.Route][p.Name] = p.Type
+		}
+	}
+	return rules
+}
+
+func (obs *paramObservation) learned(name string) LearnedParam {
+	p := LearnedParam{
+		Name:      name,
+		Type:      "string",
+		Count:     obs.count,
+		MinLength: obs.minLength,
+		MaxLength: obs.maxLength,
+	}
+	if len(obs.candidates) > 0 {
+		p.Type = obs.candidates[0]
+	}
+	if p.Type == "number" {
+		lo, hi := obs.minNumber, obs.maxNumber
+		p.Min, p.Max = &lo, &hi
+	}
+	if !obs.manyValues {
+		p.Values = slices.Clone(obs.values)
+		sort.Strings(p.Values)
+	}
+	return p
+}
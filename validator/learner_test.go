@@ -0,0 +1,90 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"fmt"
+	"maps"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestLearnerDraft(t *testing.T) {
+	l := NewQueryValidator().NewLearner(0)
+	l.Observe("/users", map[string]string{"age": "30", "since": "2024-01-02", "active": "true", "q": "bob"})
+	l.Observe("/users", map[string]string{"age": "18.5", "since": "2024-03-04", "active": "0", "q": "alice"})
+	l.Observe("/orders", map[string]string{"rev": "0123456789abcdef0123456789abcdef01234567"})
+	for i := range maxLearnedValues + 1 {
+		l.Observe("/orders", map[string]string{"id": fmt.Sprint(i)})
+	}
+
+	lo, hi := 18.5, 30.0
+	idLo, idHi := 0.0, float64(maxLearnedValues)
+	want := []LearnedRoute{
+		{Route: "/orders", Params: []LearnedParam{
+			{Name: "id", Type: "number", Count: maxLearnedValues + 1, Min: &idLo, Max: &idHi, MinLength: 1, MaxLength: 2},
+			{Name: "rev", Type: "sha", Count: 1, MinLength: 40, MaxLength: 40,
+				Values: []string{"0123456789abcdef0123456789abcdef01234567"}},
+		}},
+		{Route: "/users", Params: []LearnedParam{
+			{Name: "active", Type: "boolean", Count: 2, MinLength: 1, MaxLength: 4, Values: []string{"0", "true"}},
+			{Name: "age", Type: "number", Count: 2, Min: &lo, Max: &hi, MinLength: 2, MaxLength: 4, Values: []string{"18.5", "30"}},
+			{Name: "q", Type: "string", Count: 2, MinLength: 3, MaxLength: 5, Values: []string{"alice", "bob"}},
+			{Name: "since", Type: "date", Count: 2, MinLength: 10, MaxLength: 10, Values: []string{"2024-01-02", "2024-03-04"}},
+		}},
+	}
+	if got := l.Draft(); !reflect.DeepEqual(got, want) {
+		t.Errorf("draft =\n%+v\nwant\n%+v", got, want)
+	}
+
+	rules := l.DraftRules()
+	if want := map[string]string{"active": "boolean", "age": "number", "q": "string", "since": "date"}; !maps.Equal(rules["/users"], want) {
+		t.Errorf("draft rules for /users = %v, want %v", rules["/users"], want)
+	}
+}
+
+func TestLearnerNarrowsTypes(t *testing.T) {
+	l := NewQueryValidator().NewLearner(0)
+	l.Observe("/", map[string]string{"v": "1"})
+	if got := l.DraftRules()["/"]["v"]; got != "number" {
+		t.Errorf("type after 1 = %q, want number", got)
+	}
+	l.Observe("/", map[string]string{"v": "yes"})
+	if got := l.DraftRules()["/"]["v"]; got != "string" {
+		t.Errorf("type after 1 and yes = %q, want string", got)
+	}
+}
+
+func TestLearnerHandler(t *testing.T) {
+	tests := []struct {
+		period time.Duration
+		wait   time.Duration
+		want   int
+	}{
+		{0, 0, 1},
+		{time.Hour, 0, 1},
+		{time.Millisecond, 5 * time.Millisecond, 0},
+	}
+	for _, tt := range tests {
+		l := NewQueryValidator().NewLearner(tt.period)
+		time.Sleep(tt.wait)
+		app := fiber.New()
+		app.Use(l.Handler())
+		app.Get("/users", func(c fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+		resp, err := app.Test(httptest.NewRequest("GET", "/users?q=bob", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("period %v: status %d, want the request passed through", tt.period, resp.StatusCode)
+		}
+		if got := len(l.Draft()); got != tt.want {
+			t.Errorf("period %v: %d routes learned, want %d", tt.period, got, tt.want)
+		}
+	}
+}
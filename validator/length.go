@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// LengthUnit selects how a length validator measures a value.
+type LengthUnit int
+
+const (
+	// LengthBytes counts UTF-8 bytes.
+	LengthBytes LengthUnit = iota
+	// LengthRunes counts Unicode code points.
+	LengthRunes
+	// LengthGraphemes counts user-perceived characters, so an emoji with
+	// modifiers or a letter with combining marks counts once.
+	LengthGraphemes
+)
+
+// LengthOptions bounds the length of a value.
+type LengthOptions struct {
+	// Min is the minimum length. Zero means no lower bound.
+	Min int
+	// Max is the maximum length. Zero means no upper bound.
+	Max  int
+	Unit LengthUnit
+}
+
+// NewLengthValidator returns a type validator accepting values whose length,
+// measured in opts.Unit, lies within opts.Min and opts.Max.
+func NewLengthValidator(opts LengthOptions) func(string) bool {
+	return func(v string) bool {
+		n := valueLength(v, opts.Unit)
+		if n < opts.Min {
+			return false
+		}
+		return opts.Max <= 0 || n <= opts.Max
+	}
+}
+
+func valueLength(v string, unit LengthUnit) int {
+	switch unit {
+	case LengthRunes:
+		return utf8.RuneCountInString(v)
+	case LengthGraphemes:
+		return uniseg.GraphemeClusterCount(v)
+	default:
+		return len(v)
+	}
+}
+
+// lengthUnits name the units of len, minlen and maxlen terms.
+var lengthUnits = map[string]LengthUnit{
+	"byte":      LengthBytes,
+	"bytes":     LengthBytes,
+	"rune":      LengthRunes,
+	"runes":     LengthRunes,
+	"grapheme":  LengthGraphemes,
+	"graphemes": LengthGraphemes,
+}
+
+// splitLengthArg splits the argument of a length term, such as 10:graphemes,
+// into its bounds and unit, which is runes when absent.
+func splitLengthArg(arg string) (bounds string, unit LengthUnit, err error) {
+	bounds, name, hasUnit := strings.Cut(arg, ":")
+	if !hasUnit {
+		return bounds, LengthRunes, nil
+	}
+	unit, ok := lengthUnits[name]
+	if !ok {
+		return "", 0, fmt.Errorf("unknown length unit %q", name)
+	}
+	return bounds, unit, nil
+}
+
+// maxLength is NewLengthValidator for terms whose maximum always applies, so
+// a maximum of zero accepts only empty values.
+func maxLength(opts LengthOptions) func(string) bool {
+	if opts.Max <= 0 {
+		return func(v string) bool { return v == "" && opts.Min <= 0 }
+	}
+	return NewLengthValidator(opts)
+}
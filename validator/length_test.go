@@ -0,0 +1,61 @@
+package validator
+
+import "testing"
+
+func TestNewLengthValidator(t *testing.T) {
+	tests := []struct {
+		value string
+		opts  LengthOptions
+		want  bool
+	}{
+		{"h\u00e9llo", LengthOptions{Max: 5, Unit: LengthBytes}, false},
+		{"h\u00e9llo", LengthOptions{Max: 5, Unit: LengthRunes}, true},
+		{"e\u0301", LengthOptions{Max: 1, Unit: LengthRunes}, false},
+		{"e\u0301", LengthOptions{Max: 1, Unit: LengthGraphemes}, true},
+		{"\U0001F44D\U0001F3FD\U0001F44D\U0001F3FD", LengthOptions{Min: 2, Max: 2, Unit: LengthGraphemes}, true},
+		{"ab", LengthOptions{Min: 3}, false},
+		{"abc", LengthOptions{Min: 3}, true},
+	}
+	for _, tt := range tests {
+		if got := NewLengthValidator(tt.opts)(tt.value); got != tt.want {
+			t.Errorf("NewLengthValidator(%+v)(%q) = %v, want %v", tt.opts, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLengthTerms(t *testing.T) {
+	tests := []struct {
+		rule  string
+		value string
+		want  []string
+	}{
+		{"maxlen:1", "e\u0301", []string{"p:TOO_LONG"}},
+		{"maxlen:1:grapheme", "e\u0301", nil},
+		{"maxlen:1:graphemes", "\U0001F44D\U0001F3FD", nil},
+		{"maxlen:2:bytes", "\u00e9", nil},
+		{"maxlen:1:bytes", "\u00e9", []string{"p:TOO_LONG"}},
+		{"maxlen:0", "", nil},
+		{"maxlen:0", "a", []string{"p:TOO_LONG"}},
+		{"minlen:2:graphemes", "e\u0301", []string{"p:TOO_SHORT"}},
+		{"minlen:2", "e\u0301", nil},
+		{"len:2", "ab", nil},
+		{"len:2,3:graphemes", "e\u0301e\u0301", nil},
+		{"len:2,3:graphemes", "e\u0301", []string{"p:WRONG_LENGTH"}},
+		{"maxlen:1:words", "a", []string{"p:INVALID_RULE"}},
+	}
+	qv := NewQueryValidator()
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{"p": tt.value}, map[string]string{"p": tt.rule}, tt.want...)
+	}
+}
+
+func TestLengthTermMessage(t *testing.T) {
+	qv := NewQueryValidator()
+	errors := qv.ValidateMap(map[string]string{"p": "abcd"}, map[string]string{"p": "len:2,3:graphemes"})
+	if len(errors) != 1 {
+		t.Fatalf("got %d errors, want 1", len(errors))
+	}
+	if want := "value must be 2 to 3 characters long"; errors[0].Message != want {
+		t.Errorf("message = %q, want %q", errors[0].Message, want)
+	}
+}
@@ -0,0 +1,45 @@
+package validator
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ParseMatrixParams splits matrix parameters off the segments of path, as
+// legacy clients send them in /users;region=eu;active=true. It returns the
+// path without them and the parameters in the order sent; a key without
+// '=' has an empty value.
+func ParseMatrixParams(path string) (string, url.Values) {
+	if !strings.Contains(path, ";") {
+		return path, nil
+	}
+	params := make(url.Values)
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		name, matrix, found := strings.Cut(segment, ";")
+		if !found {
+			continue
+		}
+		segments[i] = name
+		for _, pair := range strings.Split(matrix, ";") {
+			if pair == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(pair, "=")
+			params.Add(unescapeQueryComponent([]byte(key)), unescapeQueryComponent([]byte(value)))
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// ValidateMatrixParams validates the matrix parameters of path against rules
+// and returns the path without them. Repeated keys keep the last value.
+func (qv *QueryValidator) ValidateMatrixParams(path string, rules map[string]string) (string, []QueryValidationError) {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	clean, params := ParseMatrixParams(path)
+	values := flattenValues(params, rules)
+	errors := qv.validate(values, rules, validationRequest{route: clean})
+	qv.finishErrors(errors)
+	return clean, errors
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestParseMatrixParams(t *testing.T) {
+	tests := []struct {
+		path   string
+		clean  string
+		params url.Values
+	}{
+		{"/users", "/users", nil},
+		{"/users;region=eu;active=true", "/users", url.Values{"region": {"eu"}, "active": {"true"}}},
+		{"/users;region=eu/42;v=2", "/users/42", url.Values{"region": {"eu"}, "v": {"2"}}},
+		{"/a;flag;;x=1;x=2", "/a", url.Values{"flag": {""}, "x": {"1", "2"}}},
+		{"/a;name=J%C3%B6rg+M", "/a", url.Values{"name": {"Jörg M"}}},
+		{"/a;bad=%zz", "/a", url.Values{"bad": {"%zz"}}},
+	}
+	for _, tt := range tests {
+		clean, params := ParseMatrixParams(tt.path)
+		if clean != tt.clean || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("ParseMatrixParams(%q) = %q, %v, want %q, %v", tt.path, clean, params, tt.clean, tt.params)
+		}
+	}
+}
+
+func TestValidateMatrixParams(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"region": "in:eu,us", "active": "boolean"}
+	tests := []struct {
+		path  string
+		clean string
+		want  []string
+	}{
+		{"/users;region=eu;active=true", "/users", nil},
+		{"/users;region=asia", "/users", []string{"region:NOT_ALLOWED"}},
+		{"/users;region=us;region=mars", "/users", []string{"region:NOT_ALLOWED"}},
+		{"/users;x=1", "/users", []string{"x:UNEXPECTED_PARAM"}},
+		{"/users", "/users", nil},
+	}
+	for _, tt := range tests {
+		clean, errs := qv.ValidateMatrixParams(tt.path, rules)
+		if got := errorCodes(errs); clean != tt.clean || !slices.Equal(got, tt.want) {
+			t.Errorf("ValidateMatrixParams(%q) = %q, %v, want %q, %v", tt.path, clean, got, tt.clean, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package validator
+
+import (
+	"maps"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AddMediaTypeRules overlays rules for requests negotiating mediaType, in
+// the same way as AddRoleRules: entries add or replace the handler's rules
+// and an empty rule removes the parameter. The media type is taken from the
+// Accept header, preferring the ranges with the highest q, and from
+// Content-Type when Accept names none of the registered types.
+//
+//	qv.AddMediaTypeRules("text/csv", map[string]string{"delimiter": "in:comma,tab,semicolon"})
+func (qv *QueryValidator) AddMediaTypeRules(mediaType string, rules map[string]string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	mediaType = strings.ToLower(mediaType)
+	if qv.mediaTypeRules[mediaType] == nil {
+		qv.mediaTypeRules[mediaType] = make(map[string]string)
+	}
+	maps.Copy(qv.mediaTypeRules[mediaType], rules)
+}
+
+// rulesForMediaType returns rules with the overlay for the media type
+// negotiated by the accept and contentType headers applied.
+func (qv *QueryValidator) rulesForMediaType(accept, contentType string, rules map[string]string) map[string]string {
+	if len(qv.mediaTypeRules) == 0 {
+		return rules
+	}
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if overlay, ok := qv.mediaTypeRules[mediaType]; ok {
+			return applyOverlay(rules, overlay)
+		}
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if overlay, ok := qv.mediaTypeRules[mediaType]; ok {
+			return applyOverlay(rules, overlay)
+		}
+	}
+	return rules
+}
+
+// acceptedMediaTypes lists the media ranges of an Accept header from the
+// most to the least preferred, leaving out those with q=0.
+func acceptedMediaTypes(accept string) []string {
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	types := make([]string, len(ranges))
+	for i, r := range ranges {
+		types[i] = r.mediaType
+	}
+	return types
+}
@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestAcceptedMediaTypes(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   []string
+	}{
+		{"", []string{}},
+		{"text/csv", []string{"text/csv"}},
+		{"application/json;q=0.5, text/csv", []string{"text/csv", "application/json"}},
+		{"text/csv;q=0, application/json", []string{"application/json"}},
+		{"TEXT/CSV, */*;q=0.1", []string{"text/csv", "*/*"}},
+		{"text/csv;q=high, application/json, ;;", []string{"application/json"}},
+	}
+	for _, tt := range tests {
+		if got := acceptedMediaTypes(tt.accept); !slices.Equal(got, tt.want) {
+			t.Errorf("acceptedMediaTypes(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestRulesForMediaType(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddMediaTypeRules("text/CSV", map[string]string{"delimiter": "in:comma,tab", "fields": ""})
+	qv.AddMediaTypeRules("application/xml", map[string]string{"pretty": "boolean"})
+	rules := map[string]string{"q": "string", "fields": "array:string"}
+	csv := map[string]string{"q": "string", "delimiter": "in:comma,tab"}
+	xml := map[string]string{"q": "string", "fields": "array:string", "pretty": "boolean"}
+	tests := []struct {
+		accept, contentType string
+		want                map[string]string
+	}{
+		{"", "", rules},
+		{"text/csv", "", csv},
+		{"application/json", "", rules},
+		{"application/xml;q=0.9, text/csv", "", csv},
+		{"application/xml, text/csv;q=0.9", "", xml},
+		{"application/json", "application/xml; charset=utf-8", xml},
+		{"text/csv", "application/xml", csv},
+	}
+	for _, tt := range tests {
+		if got := qv.rulesForMediaType(tt.accept, tt.contentType, rules); !maps.Equal(got, tt.want) {
+			t.Errorf("Accept %q, Content-Type %q: rules %v, want %v", tt.accept, tt.contentType, got, tt.want)
+		}
+	}
+	if len(rules) != 2 {
+		t.Errorf("overlay changed the handler's rules: %v", rules)
+	}
+}
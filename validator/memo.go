@@ -0,0 +1,85 @@
+package validator
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Memoize caches the outcome of the named type's validator for up to size
+// distinct values, each for ttl, so values that recur across requests skip
+// expensive checks such as remote lookups or heavy parsing. The least
+// recently used value is evicted when the cache is full; a zero ttl keeps
+// outcomes until evicted. Timeouts and panics are never cached.
+func (qv *QueryValidator) Memoize(typeName string, size int, ttl time.Duration) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	if size <= 0 {
+		delete(qv.memos, typeName)
+		return
+	}
+	qv.memos[typeName] = newMemoCache[typeOutcome](size, ttl)
+}
+
+// memoCache is an LRU cache of values of type V by key, whose entries
+// expire after ttl unless it is zero.
+type memoCache[V any] struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+func newMemoCache[V any](size int, ttl time.Duration) *memoCache[V] {
+	return &memoCache[V]{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+type memoEntry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+func (m *memoCache[V]) get(key string) (value V, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	elem, ok := m.entries[key]
+	if !ok {
+		return value, false
+	}
+	entry := elem.Value.(*memoEntry[V])
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+		return value, false
+	}
+	m.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (m *memoCache[V]) put(key string, value V) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := &memoEntry[V]{key: key, value: value}
+	if m.ttl > 0 {
+		entry.expires = time.Now().Add(m.ttl)
+	}
+	if elem, ok := m.entries[key]; ok {
+		elem.Value = entry
+		m.order.MoveToFront(elem)
+		return
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	if m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoEntry[V]).key)
+	}
+}
@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoCache(t *testing.T) {
+	m := newMemoCache[int](2, 0)
+	m.put("a", 1)
+	m.put("b", 2)
+	m.get("a")
+	m.put("c", 3)
+	if _, ok := m.get("b"); ok {
+		t.Error("least recently used entry b was not evicted")
+	}
+	for key, want := range map[string]int{"a": 1, "c": 3} {
+		if got, ok := m.get(key); !ok || got != want {
+			t.Errorf("get(%s) = %d, %v, want %d", key, got, ok, want)
+		}
+	}
+	m.put("a", 10)
+	if got, _ := m.get("a"); got != 10 {
+		t.Errorf("updated a = %d, want 10", got)
+	}
+
+	expiring := newMemoCache[int](2, 10*time.Millisecond)
+	expiring.put("a", 1)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := expiring.get("a"); ok {
+		t.Error("expired entry returned")
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	qv := NewQueryValidator()
+	calls := 0
+	qv.AddTypeValidator("costly", func(v string) bool { calls++; return v == "ok" })
+	qv.Memoize("costly", 10, time.Minute)
+	rules := map[string]string{"p": "costly"}
+	for range 3 {
+		checkCodes(t, qv, map[string]string{"p": "ok"}, rules)
+		// Digits have no suggestion candidates to validate.
+		checkCodes(t, qv, map[string]string{"p": "42"}, rules, "p:INVALID_TYPE")
+	}
+	if calls != 2 {
+		t.Errorf("validator ran %d times, want once per distinct value", calls)
+	}
+
+	qv.Memoize("costly", 0, 0)
+	checkCodes(t, qv, map[string]string{"p": "ok"}, rules)
+	if calls != 3 {
+		t.Errorf("validator ran %d times after memoization was turned off, want 3", calls)
+	}
+}
+
+func TestMemoizeSkipsTimeouts(t *testing.T) {
+	qv := NewQueryValidator()
+	// The validator runs on its own goroutine.
+	var calls atomic.Int32
+	var slow atomic.Bool
+	slow.Store(true)
+	qv.AddContextValidator("remote", 10*time.Millisecond, func(ctx context.Context, v string) (bool, error) {
+		calls.Add(1)
+		if slow.Load() {
+			<-ctx.Done()
+			return false, ctx.Err()
+		}
+		return true, nil
+	})
+	qv.Memoize("remote", 10, 0)
+	rules := map[string]string{"p": "remote"}
+	checkCodes(t, qv, map[string]string{"p": "x"}, rules, "p:VALIDATOR_TIMEOUT")
+	slow.Store(false)
+	checkCodes(t, qv, map[string]string{"p": "x"}, rules)
+	checkCodes(t, qv, map[string]string{"p": "x"}, rules)
+	if n := calls.Load(); n != 2 {
+		t.Errorf("validator ran %d times, want 2: a timeout must not be cached", n)
+	}
+}
@@ -0,0 +1,185 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// messageFragments is a message format split at its verbs, so messages
+// render by appending fragments and arguments to a pooled buffer rather
+// than through fmt or the catalog printer. Rejecting a flood of bad queries
+// then costs one allocation per message.
+type messageFragments struct {
+	// literals surround the verbs: literals[i] precedes verbs[i] and the
+	// last literal follows the last verb.
+	literals []string
+	verbs    []byte
+}
+
+// composeMessage splits format, which may only use the %s, %q and %v verbs
+// without flags; ok is false for other formats.
+func composeMessage(format string) (m messageFragments, ok bool) {
+	var literal strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			literal.WriteByte(format[i])
+			continue
+		}
+		if i+1 == len(format) {
+			return messageFragments{}, false
+		}
+		i++
+		switch verb := format[i]; verb {
+		case '%':
+			literal.WriteByte('%')
+		case 's', 'q', 'v':
+			m.literals = append(m.literals, literal.String())
+			m.verbs = append(m.verbs, verb)
+			literal.Reset()
+		default:
+			return messageFragments{}, false
+		}
+	}
+	m.literals = append(m.literals, literal.String())
+	return m, true
+}
+
+var messageBuffers = sync.Pool{New: func() any { return new([]byte) }}
+
+// render formats args like fmt.Sprintf would. ok is false when args do not
+// fit the verbs, or are of a type left to the printer, such as numbers,
+// which it spells per locale.
+func (m messageFragments) render(args []any) (message string, ok bool) {
+	if len(args) != len(m.verbs) {
+		return "", false
+	}
+	bufp := messageBuffers.Get().(*[]byte)
+	defer messageBuffers.Put(bufp)
+	buf := (*bufp)[:0]
+	for i, verb := range m.verbs {
+		buf = append(buf, m.literals[i]...)
+		var s string
+		switch arg := args[i].(type) {
+		case string:
+			s = arg
+		case error:
+			s = arg.Error()
+		case fmt.Stringer:
+			s = arg.String()
+		default:
+			return "", false
+		}
+		if verb == 'q' {
+			buf = strconv.AppendQuote(buf, s)
+		} else {
+			buf = append(buf, s...)
+		}
+	}
+	buf = append(buf, m.literals[len(m.literals)-1]...)
+	*bufp = buf
+	return string(buf), true
+}
+
+// englishFragments holds the composed English messages by key. It is
+// replaced as a whole when RegisterLocale changes English messages.
+var englishFragments atomic.Pointer[map[string]messageFragments]
+
+func init() {
+	composeEnglishMessages(englishMessages)
+}
+
+// composeEnglishMessages stores the fragments of the English texts by key,
+// on top of those composed before.
+func composeEnglishMessages(texts map[string]string) {
+	composed := make(map[string]messageFragments, len(englishMessages))
+	if prev := englishFragments.Load(); prev != nil {
+		for key, m := range *prev {
+			composed[key] = m
+		}
+	}
+	for key, text := range texts {
+		if m, ok := composeMessage(text); ok {
+			composed[key] = m
+		} else {
+			delete(composed, key)
+		}
+	}
+	englishFragments.Store(&composed)
+}
+
+// englishMessage renders the English message of key, falling back to the
+// catalog for messages that cannot be composed.
+func englishMessage(key string, args []any) string {
+	if m, ok := (*englishFragments.Load())[key]; ok {
+		if message, ok := m.render(args); ok {
+			return message
+		}
+	}
+	return messages.Sprintf(key, args...)
+}
+
+// templateFragments caches the composed templates of SetMessage and
+// SetConstraintMessage by template. Per-request overrides from Locals are
+// composed on each use instead, so requests cannot grow the cache.
+var templateFragments sync.Map
+
+// registerTemplate composes a template set at configuration time into
+// templateFragments.
+func registerTemplate(tmpl string) {
+	if _, ok := templateFragments.Load(tmpl); !ok {
+		templateFragments.Store(tmpl, splitTemplate(tmpl))
+	}
+}
+
+// templatePlaceholders are the placeholders of SetMessage templates, in the
+// order their values are passed to fill.
+var templatePlaceholders = []string{"{param}", "{value}", "{constraint}"}
+
+// composeTemplate returns the fragments of tmpl, cached when it was
+// registered.
+func composeTemplate(tmpl string) messageFragments {
+	if m, ok := templateFragments.Load(tmpl); ok {
+		return m.(messageFragments)
+	}
+	return splitTemplate(tmpl)
+}
+
+// splitTemplate splits a message template at its placeholders. The verbs of
+// the fragments index templatePlaceholders.
+func splitTemplate(tmpl string) messageFragments {
+	var m messageFragments
+	rest := tmpl
+	for {
+		at, which := -1, 0
+		for i, placeholder := range templatePlaceholders {
+			if j := strings.Index(rest, placeholder); j >= 0 && (at < 0 || j < at) {
+				at, which = j, i
+			}
+		}
+		if at < 0 {
+			break
+		}
+		m.literals = append(m.literals, rest[:at])
+		m.verbs = append(m.verbs, byte(which))
+		rest = rest[at+len(templatePlaceholders[which]):]
+	}
+	m.literals = append(m.literals, rest)
+	return m
+}
+
+// fill renders a template composed by composeTemplate with the values of
+// its placeholders.
+func (m messageFragments) fill(values [3]string) string {
+	bufp := messageBuffers.Get().(*[]byte)
+	defer messageBuffers.Put(bufp)
+	buf := (*bufp)[:0]
+	for i, which := range m.verbs {
+		buf = append(append(buf, m.literals[i]...), values[which]...)
+	}
+	buf = append(buf, m.literals[len(m.literals)-1]...)
+	*bufp = buf
+	return string(buf)
+}
@@ -0,0 +1,128 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestComposeMessage(t *testing.T) {
+	tests := []struct {
+		format string
+		args   []any
+		ok     bool
+	}{
+		{"plain", nil, true},
+		{"value must be at least %s", []any{"5"}, true},
+		{"invalid rule %q: %v", []any{"min:x", errors.New("bad bound")}, true},
+		{"100%% of %s", []any{language.French}, true},
+		{"%s and %s", []any{"a", "b"}, true},
+		{"count %d", nil, false},
+		{"width %5s", nil, false},
+		{"trailing %", nil, false},
+	}
+	for _, tt := range tests {
+		m, ok := composeMessage(tt.format)
+		if ok != tt.ok {
+			t.Errorf("composeMessage(%q) ok = %v, want %v", tt.format, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got, _ := m.render(tt.args); got != fmt.Sprintf(tt.format, tt.args...) {
+			t.Errorf("render(%q, %v) = %q, want %q", tt.format, tt.args, got, fmt.Sprintf(tt.format, tt.args...))
+		}
+	}
+}
+
+func TestRenderLeavesArgsToPrinter(t *testing.T) {
+	m, _ := composeMessage("list must have at most %s items")
+	for _, args := range [][]any{{3}, {}, {"a", "b"}} {
+		if got, ok := m.render(args); ok {
+			t.Errorf("render(%v) = %q, want it left to the printer", args, got)
+		}
+	}
+}
+
+func TestEnglishMessagesMatchCatalog(t *testing.T) {
+	for key, text := range englishMessages {
+		m, ok := composeMessage(text)
+		if !ok {
+			t.Errorf("%s: %q does not compose", key, text)
+			continue
+		}
+		args := make([]any, len(m.verbs))
+		for i := range args {
+			args[i] = fmt.Sprintf("arg%d", i)
+		}
+		if got, want := englishMessage(key, args), messages.Sprintf(key, args...); got != want {
+			t.Errorf("%s: message %q, catalog renders %q", key, got, want)
+		}
+	}
+	// Counts go through the printer, which picks the plural form.
+	if got := englishMessage(MsgTooManyItems, []any{1}); got != "list must have at most 1 item" {
+		t.Errorf("count message %q", got)
+	}
+}
+
+func TestComposeTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		want string
+	}{
+		{"{param} must be at least {constraint}, got {value}", "age must be at least 18, got 7"},
+		{"{value}{value}", "77"},
+		{"no placeholders", "no placeholders"},
+		{"{param", "{param"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := composeTemplate(tt.tmpl).fill([3]string{"age", "7", "18"}); got != tt.want {
+			t.Errorf("fill(%q) = %q, want %q", tt.tmpl, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateCacheKeepsRegisteredTemplates(t *testing.T) {
+	qv := NewQueryValidator()
+	registered := "{param} is out of range: {value}"
+	qv.SetMessage("age", "max", registered)
+	if _, ok := templateFragments.Load(registered); !ok {
+		t.Errorf("SetMessage template %q not cached", registered)
+	}
+	constraint := "{param} needs at least {constraint}"
+	qv.SetConstraintMessage("min", constraint)
+	if _, ok := templateFragments.Load(constraint); !ok {
+		t.Errorf("SetConstraintMessage template %q not cached", constraint)
+	}
+
+	errs := qv.ValidateMap(map[string]string{"age": "7"}, map[string]string{"age": "integer AND min:18"})
+	for i := range 3 {
+		override := fmt.Sprintf("request %d: {param} got {value}", i)
+		renderErrors(errs, newPrinter(language.English), map[string]string{MsgTooSmall: override})
+		if want := fmt.Sprintf("request %d: age got 7", i); len(errs) != 1 || errs[0].Message != want {
+			t.Fatalf("override message %v, want %q", errs, want)
+		}
+		if _, ok := templateFragments.Load(override); ok {
+			t.Errorf("per-request template %q cached", override)
+		}
+	}
+}
+
+func TestMessageAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable under the race detector")
+	}
+	m, _ := composeMessage("value must be at most %s")
+	args := []any{"120"}
+	if n := testing.AllocsPerRun(100, func() { m.render(args) }); n > 1 {
+		t.Errorf("render allocates %v times, want at most 1", n)
+	}
+	tmpl := composeTemplate("{param} must be at most {constraint}")
+	if n := testing.AllocsPerRun(100, func() { tmpl.fill([3]string{"age", "130", "120"}) }); n > 1 {
+		t.Errorf("fill allocates %v times, want at most 1", n)
+	}
+}
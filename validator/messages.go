@@ -0,0 +1,286 @@
+package validator
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// Message keys identify validator messages in the catalog and in overrides,
+// and are the stable error codes clients see in QueryValidationError.Code.
+const (
+	MsgInvalidName       = "INVALID_NAME"
+	MsgUnexpected        = "UNEXPECTED_PARAM"
+	MsgInvalidType       = "INVALID_TYPE"
+	MsgMustNotMatch      = "MUST_NOT_MATCH"
+	MsgNoAlternative     = "NO_ALTERNATIVE_MATCHED"
+	MsgConstraintFailed  = "CONSTRAINT_FAILED"
+	MsgChecksumMismatch  = "CHECKSUM_MISMATCH"
+	MsgForbiddenParam    = "FORBIDDEN_PARAM"
+	MsgInvalidRule       = "INVALID_RULE"
+	MsgLimitExceeded     = "LIMIT_EXCEEDED"
+	MsgValidatorTimeout  = "VALIDATOR_TIMEOUT"
+	MsgInternalError     = "INTERNAL_ERROR"
+	MsgInvalidEncoding   = "INVALID_ENCODING"
+	MsgMissingRequired   = "MISSING_REQUIRED"
+	MsgTooSmall          = "TOO_SMALL"
+	MsgTooLarge          = "TOO_LARGE"
+	MsgNotAllowed        = "NOT_ALLOWED"
+	MsgForbiddenValue    = "FORBIDDEN_VALUE"
+	MsgPatternMismatch   = "PATTERN_MISMATCH"
+	MsgWrongLength       = "WRONG_LENGTH"
+	MsgTooShort          = "TOO_SHORT"
+	MsgTooLong           = "TOO_LONG"
+	MsgNotFound          = "NOT_FOUND"
+	MsgAlreadyTaken      = "ALREADY_TAKEN"
+	MsgClamped           = "CLAMPED"
+	MsgFellBack          = "FELL_BACK"
+	MsgTooFewItems       = "TOO_FEW_ITEMS"
+	MsgTooManyItems      = "TOO_MANY_ITEMS"
+	MsgDidYouMean        = "DID_YOU_MEAN"
+	MsgReservedParam     = "RESERVED_PARAM"
+	MsgRequiredWith      = "REQUIRED_WITH"
+	MsgMutuallyExclusive = "MUTUALLY_EXCLUSIVE"
+	MsgGreaterThanField  = "GT_FIELD"
+	MsgAtLeastField      = "GTE_FIELD"
+	MsgLessThanField     = "LT_FIELD"
+	MsgAtMostField       = "LTE_FIELD"
+	MsgRequiredIf        = "REQUIRED_IF"
+	MsgRequiredUnless    = "REQUIRED_UNLESS"
+	MsgRequiredWithout   = "REQUIRED_WITHOUT"
+	MsgForbiddenIf       = "FORBIDDEN_IF"
+	MsgForbiddenWith     = "FORBIDDEN_WITH"
+	MsgForbiddenUnless   = "FORBIDDEN_UNLESS"
+	MsgForbiddenWithout  = "FORBIDDEN_WITHOUT"
+	MsgForeignRedirect   = "FOREIGN_REDIRECT"
+	MsgUndecryptable     = "UNDECRYPTABLE"
+	MsgInvalidCursor     = "INVALID_CURSOR"
+	msgInvalidParamCount = "INVALID_PARAM_COUNT"
+)
+
+// Locals keys read by ValidateQuery. An earlier middleware can store a
+// locale (a string or language.Tag) and a map[string]string of message
+// overrides keyed by message key, e.g. per tenant. A stored locale wins
+// over the Accept-Language header. Middleware stores the ValidationResult
+// under LocalsResult.
+const (
+	LocalsLocale           = "queryvalidator.locale"
+	LocalsMessageOverrides = "queryvalidator.messages"
+	LocalsResult           = "queryvalidator.result"
+)
+
+// messageCatalog holds the English messages and those added with
+// RegisterLocale. Count-bearing entries use CLDR
+// plural categories, so locales added later pick the right form for their
+// own plural rules rather than English "(s)" suffixes.
+var messageCatalog = newMessageCatalog()
+
+var messages = message.NewPrinter(language.English, message.Catalog(messageCatalog))
+
+// englishMessages are the catalog's English messages by key.
+var englishMessages = map[string]string{
+	MsgInvalidName:       "invalid parameter name format",
+	MsgUnexpected:        "unexpected parameter",
+	MsgInvalidType:       "invalid value for type %s",
+	MsgMustNotMatch:      "value must not match %s",
+	MsgNoAlternative:     "value must match one of: %s",
+	MsgConstraintFailed:  "value fails constraint %s",
+	MsgChecksumMismatch:  "checksum does not match %s",
+	MsgForbiddenParam:    "parameter requires scope %s",
+	MsgInvalidRule:       "invalid rule %q: %v",
+	MsgLimitExceeded:     "query exceeds the %s limit",
+	MsgValidatorTimeout:  "validation of %s timed out",
+	MsgInternalError:     "could not validate %s",
+	MsgInvalidEncoding:   "invalid percent-encoding: %v",
+	MsgMissingRequired:   "missing required parameter",
+	MsgTooSmall:          "value must be at least %s",
+	MsgTooLarge:          "value must be at most %s",
+	MsgNotAllowed:        "value must be one of %s",
+	MsgForbiddenValue:    "value must not be any of %s",
+	MsgPatternMismatch:   "value must match %s",
+	MsgWrongLength:       "value must be %s characters long",
+	MsgTooShort:          "value must be at least %s characters long",
+	MsgTooLong:           "value must be at most %s characters long",
+	MsgNotFound:          "no %s matches the value",
+	MsgAlreadyTaken:      "value is already taken in %s",
+	MsgClamped:           "value was clamped to %s",
+	MsgFellBack:          "invalid value was replaced with the default %s",
+	MsgDidYouMean:        "did you mean %q?",
+	MsgReservedParam:     "parameter is reserved for internal use",
+	MsgRequiredWith:      "parameter is required when %s is sent",
+	MsgMutuallyExclusive: "parameter cannot be combined with %s",
+	MsgGreaterThanField:  "value must be greater than %s",
+	MsgAtLeastField:      "value must be at least %s",
+	MsgLessThanField:     "value must be less than %s",
+	MsgAtMostField:       "value must be at most %s",
+	MsgRequiredIf:        "parameter is required when %s is %s",
+	MsgRequiredUnless:    "parameter is required unless %s is %s",
+	MsgRequiredWithout:   "parameter is required when %s is not sent",
+	MsgForbiddenIf:       "parameter is not allowed when %s is %s",
+	MsgForbiddenWith:     "parameter is not allowed when %s is sent",
+	MsgForbiddenUnless:   "parameter is only allowed when %s is %s",
+	MsgForbiddenWithout:  "parameter is only allowed when %s is sent",
+	MsgForeignRedirect:   "value must be a relative URL or point to one of %s",
+	MsgUndecryptable:     "value could not be decrypted",
+	MsgInvalidCursor:     "value is not a valid %s cursor",
+}
+
+// countedMessages are the keys whose English messages pick a plural form
+// by count, which setEnglishMessages sets apart from englishMessages.
+var countedMessages = []string{MsgTooFewItems, MsgTooManyItems}
+
+func newMessageCatalog() *catalog.Builder {
+	b := catalog.NewBuilder(catalog.Fallback(language.English))
+	setEnglishMessages(b, language.English)
+	return b
+}
+
+// setEnglishMessages sets the English messages for tag, which is how keys a
+// locale leaves out fall back to English.
+func setEnglishMessages(b *catalog.Builder, tag language.Tag) {
+	for key, text := range englishMessages {
+		b.SetString(tag, key, text)
+	}
+	b.Set(tag, msgInvalidParamCount, plural.Selectf(1, "%d",
+		plural.One, "%d invalid query parameter",
+		plural.Other, "%d invalid query parameters",
+	))
+	b.Set(tag, MsgTooFewItems, plural.Selectf(1, "%d",
+		plural.One, "list must have at least %d item",
+		plural.Other, "list must have at least %d items",
+	))
+	b.Set(tag, MsgTooManyItems, plural.Selectf(1, "%d",
+		plural.One, "list must have at most %d item",
+		plural.Other, "list must have at most %d items",
+	))
+}
+
+// failure is a message that has not been rendered for a request yet.
+type failure struct {
+	key  string
+	args []any
+	// template, when set, renders the message instead of the catalog.
+	template string
+	// term names the rule term that failed, for SetMessage.
+	term string
+}
+
+func fail(key string, args ...any) failure {
+	return failure{key: key, args: args}
+}
+
+// failTerm is fail for the failure of the rule term named term.
+func failTerm(term, key string, args ...any) failure {
+	return failure{key: key, args: args, term: term}
+}
+
+// newValidationError builds an error with the English message. ValidateQuery
+// re-renders it when the request asks for another locale or overrides.
+func newValidationError(param, value string, f failure) QueryValidationError {
+	message := englishMessage(f.key, f.args)
+	if f.template != "" {
+		message = renderTemplate(f.template, param, value, f)
+	}
+	return QueryValidationError{
+		Parameter: param,
+		Value:     value,
+		Message:   message,
+		Code:      f.key,
+		reason:    f,
+	}
+}
+
+// ErrorSummary describes errors in one line, e.g. "2 invalid query parameters".
+func ErrorSummary(errors []QueryValidationError) string {
+	return messages.Sprintf(msgInvalidParamCount, len(errors))
+}
+
+// RegisterLocale adds messages for tag to the catalog, keyed by message
+// key, or replaces those registered before. Messages take the arguments of
+// their English counterparts, and keys left out fall back to English:
+//
+//	validator.RegisterLocale(language.French, map[string]string{
+//		validator.MsgMissingRequired: "paramètre obligatoire manquant",
+//		validator.MsgInvalidType:     "valeur invalide pour le type %s",
+//	})
+func RegisterLocale(tag language.Tag, messages map[string]string) error {
+	for key := range messages {
+		if _, ok := englishMessages[key]; !ok && !slices.Contains(countedMessages, key) {
+			return fmt.Errorf("unknown message key %q", key)
+		}
+	}
+	if !slices.Contains(messageCatalog.Languages(), tag) {
+		setEnglishMessages(messageCatalog, tag)
+	}
+	for key, text := range messages {
+		if err := messageCatalog.SetString(tag, key, text); err != nil {
+			return fmt.Errorf("invalid message %s: %v", key, err)
+		}
+	}
+	if tag == language.English {
+		composeEnglishMessages(messages)
+	}
+	return nil
+}
+
+// Localize re-renders the messages of errors in the catalog language
+// closest to tag, for callers of ValidateValues and ValidateMap; the Fiber
+// adapter localizes on its own. Messages set with SetMessage or
+// SetConstraintMessage are kept.
+func Localize(errors []QueryValidationError, tag language.Tag) {
+	renderErrors(errors, newPrinter(tag), nil)
+}
+
+// renderErrors re-renders errors with printer, preferring the templates of
+// overrides, which are keyed by message key.
+func renderErrors(errors []QueryValidationError, printer *message.Printer, overrides map[string]string) {
+	for i := range errors {
+		reason := errors[i].reason
+		if reason.key == "" {
+			continue
+		}
+		if tmpl, ok := overrides[reason.key]; ok {
+			errors[i].Message = renderTemplate(tmpl, errors[i].Parameter, errors[i].Value, reason)
+			continue
+		}
+		if reason.template != "" {
+			continue
+		}
+		errors[i].Message = printer.Sprintf(reason.key, reason.args...) + didYouMean(printer, errors[i])
+	}
+}
+
+// newPrinter returns a printer for the catalog language closest to tags,
+// which are in order of preference.
+func newPrinter(tags ...language.Tag) *message.Printer {
+	supported := messageCatalog.Languages()
+	_, index, _ := language.NewMatcher(supported).Match(tags...)
+	return message.NewPrinter(supported[index], message.Catalog(messageCatalog))
+}
+
+// acceptLanguagePrinter returns a printer for an Accept-Language header, or
+// nil when the header is empty or malformed or only English is registered.
+func acceptLanguagePrinter(header string) *message.Printer {
+	if header == "" || len(messageCatalog.Languages()) < 2 {
+		return nil
+	}
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return nil
+	}
+	return newPrinter(tags...)
+}
+
+// renderTemplate fills {param}, {value} and {constraint} in an override. The
+// constraint is the type, constraint or scope the value was checked against.
+func renderTemplate(tmpl, param, value string, reason failure) string {
+	constraint := ""
+	if len(reason.args) > 0 {
+		constraint = fmt.Sprint(reason.args[0])
+	}
+	return composeTemplate(tmpl).fill([3]string{param, value, constraint})
+}
@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestItemCountMessagesPlural(t *testing.T) {
+	tests := []struct {
+		rule  string
+		value string
+		want  string
+	}{
+		{"array:string AND maxitems:1", "a,b", "list must have at most 1 item"},
+		{"array:string AND maxitems:2", "a,b,c", "list must have at most 2 items"},
+		{"array:string AND minitems:1", "", "list must have at least 1 item"},
+		{"array:string AND minitems:3", "a", "list must have at least 3 items"},
+	}
+	qv := NewQueryValidator()
+	for _, tt := range tests {
+		errors := qv.ValidateMap(map[string]string{"ids": tt.value}, map[string]string{"ids": tt.rule})
+		if len(errors) != 1 {
+			t.Errorf("%s with %q: got %d errors, want 1", tt.rule, tt.value, len(errors))
+			continue
+		}
+		if errors[0].Message != tt.want {
+			t.Errorf("%s with %q: message %q, want %q", tt.rule, tt.value, errors[0].Message, tt.want)
+		}
+	}
+}
+
+func TestErrorSummaryPlural(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{1, "1 invalid query parameter"},
+		{2, "2 invalid query parameters"},
+	}
+	for _, tt := range tests {
+		if got := ErrorSummary(make([]QueryValidationError, tt.n)); got != tt.want {
+			t.Errorf("ErrorSummary(%d errors) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterLocale(t *testing.T) {
+	err := RegisterLocale(language.Dutch, map[string]string{
+		MsgMissingRequired: "verplichte parameter ontbreekt",
+		MsgTooManyItems:    "lijst mag hoogstens %d elementen hebben",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterLocale(language.Dutch, map[string]string{"NO_SUCH_KEY": "x"}); err == nil {
+		t.Error("RegisterLocale accepted an unknown key")
+	}
+
+	qv := NewQueryValidator()
+	rules := map[string]string{"q": "required", "ids": "array:string AND maxitems:1", "n": "int:64"}
+	errors := qv.ValidateMap(map[string]string{"ids": "a,b", "n": "x"}, rules)
+	Localize(errors, language.Dutch)
+	want := map[string]string{
+		"q":   "verplichte parameter ontbreekt",
+		"ids": "lijst mag hoogstens 1 elementen hebben",
+		// Keys the locale leaves out fall back to English.
+		"n": errorMessage(t, NewQueryValidator(), "n", "x", "int:64"),
+	}
+	for _, err := range errors {
+		if err.Message != want[err.Parameter] {
+			t.Errorf("%s: message %q, want %q", err.Parameter, err.Message, want[err.Parameter])
+		}
+	}
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	if err := RegisterLocale(language.Spanish, map[string]string{
+		MsgMissingRequired: "falta un parámetro obligatorio",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "missing required parameter"},
+		{"es", "falta un parámetro obligatorio"},
+		{"es-MX", "falta un parámetro obligatorio"},
+		{"ja, es;q=0.5", "falta un parámetro obligatorio"},
+		{"en;q=0.9, es;q=0.8", "missing required parameter"},
+		{"ja", "missing required parameter"},
+		{"es;q=x;;", "missing required parameter"},
+	}
+	qv := NewQueryValidator()
+	for _, tt := range tests {
+		errors := qv.ValidateMap(nil, map[string]string{"q": "required"})
+		if printer := acceptLanguagePrinter(tt.header); printer != nil {
+			renderErrors(errors, printer, nil)
+		}
+		if len(errors) != 1 || errors[0].Message != tt.want {
+			t.Errorf("Accept-Language %q: errors %v, want message %q", tt.header, errors, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"maps"
+	"sync"
+)
+
+// MetricsHook receives observations made while validating, e.g. to export
+// them to a metrics backend.
+type MetricsHook interface {
+	// UnknownParam is called for each parameter sent to route that its
+	// rules do not declare.
+	UnknownParam(route, param string)
+}
+
+// SetMetricsHook registers the hook that receives validation observations.
+func (qv *QueryValidator) SetMetricsHook(hook MetricsHook) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.metrics = hook
+}
+
+// OtherUnknownParams is the bucket UnknownParamCounter uses once it tracks
+// its maximum number of distinct names.
+const OtherUnknownParams = "(other)"
+
+// UnknownParamCounter is a MetricsHook counting unknown parameter names per
+// route, so API owners can see undocumented client usage before tightening
+// rules. It is safe for concurrent use.
+type UnknownParamCounter struct {
+	// MaxNames bounds the distinct names tracked per route so clients cannot
+	// grow it without limit. Zero means 100.
+	MaxNames int
+
+	mu     sync.Mutex
+	counts map[string]map[string]int
+}
+
+// UnknownParam implements MetricsHook.
+func (u *UnknownParamCounter) UnknownParam(route, param string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.counts == nil {
+		u.counts = make(map[string]map[string]int)
+	}
+	names := u.counts[route]
+	if names == nil {
+		names = make(map[string]int)
+		u.counts[route] = names
+	}
+
+	maxNames := u.MaxNames
+	if maxNames <= 0 {
+		maxNames = 100
+	}
+	if _, seen := names[param]; !seen && len(names) >= maxNames {
+		param = OtherUnknownParams
+	}
+	names[param]++
+}
+
+// Counts returns a snapshot of the counts for route, keyed by parameter name.
+func (u *UnknownParamCounter) Counts(route string) map[string]int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return maps.Clone(u.counts[route])
+}
@@ -0,0 +1,64 @@
+package validator
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestUnknownParamMetricsInEveryMode(t *testing.T) {
+	modes := []struct {
+		name string
+		mode UnknownParamMode
+	}{
+		{"strict", UnknownStrict},
+		{"warn", UnknownWarn},
+		{"ignore", UnknownIgnore},
+		{"strip", UnknownStrip},
+	}
+	for _, m := range modes {
+		t.Run(m.name, func(t *testing.T) {
+			counter := &UnknownParamCounter{}
+			qv := NewQueryValidator()
+			qv.SetMetricsHook(counter)
+			qv.SetUnknownParamPolicy(UnknownParamPolicy{Mode: m.mode})
+			qv.ValidateMap(map[string]string{"utm_source": "x", "q": "go"}, map[string]string{"q": "string"})
+			want := map[string]int{"utm_source": 1}
+			if got := counter.Counts(""); !maps.Equal(got, want) {
+				t.Errorf("Counts = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnknownParamMetricsAllowList(t *testing.T) {
+	counter := &UnknownParamCounter{}
+	qv := NewQueryValidator()
+	qv.SetMetricsHook(counter)
+	qv.SetUnknownParamPolicy(UnknownParamPolicy{Mode: UnknownStrip, Allow: []string{"fbclid"}})
+	values := map[string]string{"fbclid": "1", "utm_source": "x"}
+	qv.ValidateMap(values, map[string]string{})
+	want := map[string]int{"fbclid": 1, "utm_source": 1}
+	if got := counter.Counts(""); !maps.Equal(got, want) {
+		t.Errorf("Counts = %v, want %v", got, want)
+	}
+	if _, ok := values["utm_source"]; ok {
+		t.Error("utm_source was not stripped")
+	}
+	if _, ok := values["fbclid"]; !ok {
+		t.Error("allowed fbclid was stripped")
+	}
+}
+
+func TestUnknownParamCounterMaxNames(t *testing.T) {
+	counter := &UnknownParamCounter{MaxNames: 2}
+	for _, param := range []string{"a", "b", "c", "a", "d"} {
+		counter.UnknownParam("/r", param)
+	}
+	want := map[string]int{"a": 2, "b": 1, OtherUnknownParams: 2}
+	if got := counter.Counts("/r"); !maps.Equal(got, want) {
+		t.Errorf("Counts = %v, want %v", got, want)
+	}
+	if got := counter.Counts("/other"); len(got) != 0 {
+		t.Errorf("Counts of another route = %v, want none", got)
+	}
+}
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Built-in parameter name patterns. PatternDefault applies unless a rule
+// selects another with a name_pattern term, a pattern is registered under
+// the parameter's own name, or a route selects one with SetNamePattern;
+// AddParamPattern can redefine any of them or add more.
+const (
+	// PatternDefault allows ASCII identifiers such as pageSize or page_size.
+	PatternDefault = "default"
+	// PatternUnicode allows identifiers in any script, such as größe or 名前.
+	PatternUnicode = "unicode"
+	// PatternKebabCase allows lowercase words joined by hyphens, as in page-size.
+	PatternKebabCase = "kebab-case"
+	// PatternSnakeCase allows lowercase words joined by underscores, as in
+	// page_size.
+	PatternSnakeCase = "snake_case"
+	// PatternDotted allows dot-separated ASCII identifiers, as in filter.owner.id.
+	PatternDotted = "dotted"
+)
+
+var builtinNamePatterns = map[string]string{
+	PatternDefault:   `^[a-zA-Z][a-zA-Z0-9_]*$`,
+	PatternUnicode:   `^\p{L}[\p{L}\p{M}\p{N}_]*$`,
+	PatternKebabCase: `^[a-z][a-z0-9]*(-[a-z0-9]+)*$`,
+	PatternSnakeCase: `^[a-z][a-z0-9]*(_[a-z0-9]+)*$`,
+	PatternDotted:    `^[a-zA-Z][a-zA-Z0-9_]*(\.[a-zA-Z][a-zA-Z0-9_]*)*$`,
+}
+
+// SetNamePattern selects the parameter name pattern route checks names
+// against, by the name given to AddParamPattern or a built-in. A route
+// ending in "/*" selects it for its whole subtree, as in RegisterRoute.
+func (qv *QueryValidator) SetNamePattern(route, pattern string) error {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	if _, ok := qv.paramPatterns[pattern]; !ok {
+		return fmt.Errorf("unknown name pattern %s", pattern)
+	}
+	qv.routePatterns[normalizeRoute(route)] = pattern
+	return nil
+}
+
+// namePatternTerm overrides the name pattern for one parameter, as in
+// "filter[status]": "name_pattern:none AND in:open,closed". Its argument is
+// a pattern name, or PatternNone to skip the name check.
+const namePatternTerm = "name_pattern"
+
+// PatternNone, as the argument of a name_pattern term, accepts any name.
+const PatternNone = "none"
+
+func (qv *QueryValidator) namePatternConstraint(arg string) (func(string) bool, error) {
+	if _, ok := qv.paramPatterns[arg]; !ok && arg != PatternNone {
+		return nil, fmt.Errorf("unknown name pattern %s", arg)
+	}
+	// The term constrains the name, not the value.
+	return func(string) bool { return true }, nil
+}
+
+// ruleNamePattern returns the pattern name a name_pattern term of rule
+// selects.
+func ruleNamePattern(rule string) (string, bool) {
+	if !strings.Contains(rule, namePatternTerm+":") {
+		return "", false
+	}
+	for _, term := range ruleTerms(rule) {
+		if arg, ok := strings.CutPrefix(term, namePatternTerm+":"); ok {
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// namePattern returns the name of the pattern route selected.
+func (qv *QueryValidator) namePattern(route string) string {
+	if route == "" || len(qv.routePatterns) == 0 {
+		return PatternDefault
+	}
+	matches := coveringRoutes(qv.routePatterns, route)
+	if len(matches) == 0 {
+		return PatternDefault
+	}
+	return qv.routePatterns[matches[len(matches)-1]]
+}
@@ -0,0 +1,143 @@
+package validator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBuiltinNamePatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{PatternDefault, "page_size", true},
+		{PatternDefault, "größe", false},
+		{PatternUnicode, "größe", true},
+		{PatternUnicode, "名前", true},
+		{PatternUnicode, "1st", false},
+		{PatternKebabCase, "page-size", true},
+		{PatternKebabCase, "page--size", false},
+		{PatternKebabCase, "Page-size", false},
+		{PatternSnakeCase, "page_size", true},
+		{PatternSnakeCase, "pageSize", false},
+		{PatternDotted, "filter.owner.id", true},
+		{PatternDotted, "filter..id", false},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		if err := qv.SetNamePattern("/items", tt.pattern); err != nil {
+			t.Fatal(err)
+		}
+		errors, _ := qv.run(map[string]string{tt.name: "1"}, map[string]string{tt.name: "string"}, validationRequest{route: "/items"})
+		if got := len(errors) == 0; got != tt.want {
+			t.Errorf("%s %q: valid %v, want %v (errors %v)", tt.pattern, tt.name, got, tt.want, errorCodes(errors))
+		}
+	}
+}
+
+func TestSetNamePattern(t *testing.T) {
+	qv := NewQueryValidator()
+	if err := qv.SetNamePattern("/de/*", PatternUnicode); err != nil {
+		t.Fatal(err)
+	}
+	if err := qv.SetNamePattern("/de/legacy", PatternDefault); err != nil {
+		t.Fatal(err)
+	}
+	if err := qv.SetNamePattern("/x", "camel"); err == nil {
+		t.Error("unknown pattern accepted")
+	}
+	rules := map[string]string{"größe": "string", "farbe": "name_pattern:kebab-case AND string", "x.y": "name_pattern:none"}
+	tests := []struct {
+		route string
+		want  []string
+	}{
+		{"/de/shop", nil},
+		{"/de/legacy", []string{"größe:INVALID_NAME"}},
+		{"/en/shop", []string{"größe:INVALID_NAME"}},
+	}
+	for _, tt := range tests {
+		errors, _ := qv.run(map[string]string{"größe": "42", "farbe": "rot", "x.y": "1"}, rules, validationRequest{route: tt.route})
+		if got := errorCodes(errors); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: errors %v, want %v", tt.route, got, tt.want)
+		}
+	}
+	checkCodes(t, qv, map[string]string{"p": "1"}, map[string]string{"p": "name_pattern:camel"}, "p:INVALID_RULE")
+}
+
+func TestSchemaNamePattern(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RegisterRoute("/de/shop", map[string]string{"größe": "string"})
+	qv.RegisterRoute("/en/shop", map[string]string{"size": "string"})
+	if err := qv.SetNamePattern("/de/*", PatternUnicode); err != nil {
+		t.Fatal(err)
+	}
+	if schema, _ := qv.Schema("/de/shop"); schema.NamePattern != PatternUnicode {
+		t.Errorf("/de/shop name pattern %q, want %s", schema.NamePattern, PatternUnicode)
+	}
+	if schema, _ := qv.Schema("/en/shop"); schema.NamePattern != "" {
+		t.Errorf("/en/shop name pattern %q, want the default left out", schema.NamePattern)
+	}
+}
+
+func TestNamePatternTerm(t *testing.T) {
+	qv := NewQueryValidator()
+	if err := qv.AddParamPattern("x-id", `^x-id$`); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		param string
+		rule  string
+		want  []string
+	}{
+		{"Page-Size", "integer", []string{"Page-Size:INVALID_NAME"}},
+		{"Page-Size", "name_pattern:none AND integer", nil},
+		{"page-size", "name_pattern:kebab-case|integer", nil},
+		{"page_size", "name_pattern:kebab-case AND integer", []string{"page_size:INVALID_NAME"}},
+		{"x-id", "integer", nil},
+		{"x-id", "name_pattern:snake_case AND integer", []string{"x-id:INVALID_NAME"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{tt.param: "1"}, map[string]string{tt.param: tt.rule}, tt.want...)
+	}
+
+	checkCodes(t, qv, map[string]string{"meta_Über": "1"}, map[string]string{"meta_*": "string"})
+	checkCodes(t, qv, map[string]string{"meta_Über": "1"}, map[string]string{"meta_*": "name_pattern:snake_case AND string"}, "meta_Über:INVALID_NAME")
+	checkCodes(t, qv, map[string]string{"meta_über": "1"}, map[string]string{"meta_*": "name_pattern:unicode AND string"})
+}
+
+func TestOwnNamePattern(t *testing.T) {
+	qv := NewQueryValidator()
+	if err := qv.AddParamPattern("ids", `^ids(\[[0-9]*\])?$`); err != nil {
+		t.Fatal(err)
+	}
+	if err := qv.SetNamePattern("/kebab", PatternKebabCase); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		param string
+		rule  string
+		route string
+		want  []string
+	}{
+		{"ids", "string", "", nil},
+		{"ids[]", "string", "", nil},
+		{"ids[0]", "string", "", nil},
+		{"ids[x]", "string", "", []string{"ids[x]:INVALID_NAME"}},
+		// A pattern of the parameter's own wins over the route's.
+		{"ids[]", "string", "/kebab", nil},
+		{"page_size", "string", "/kebab", []string{"page_size:INVALID_NAME"}},
+		{"filter[status]", "string", "", nil},
+		{"filter.status", "string", "", []string{"filter.status:INVALID_NAME"}},
+		{"filter.status", "name_pattern:none AND string", "", nil},
+		// A name_pattern term wins over the parameter's own pattern.
+		{"ids", "name_pattern:kebab-case AND string", "", nil},
+		{"ids[x]", "name_pattern:kebab-case AND string", "", nil},
+	}
+	for _, tt := range tests {
+		errs, _ := qv.run(map[string]string{tt.param: "1"}, map[string]string{tt.param: tt.rule}, validationRequest{route: tt.route})
+		if got := errorCodes(errs); !slices.Equal(got, tt.want) {
+			t.Errorf("%s against %q on %q: errors %v, want %v", tt.param, tt.rule, tt.route, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"net/http"
+	"sort"
+)
+
+// ValidateRequest is the net/http counterpart of ValidateQuery. Besides the
+// query it validates path wildcards of Go 1.22 ServeMux patterns, read with
+// r.PathValue: for "GET /users/{id}", pathRules {"id": "number"} checks the
+// id segment. Media type overlays and the Accept-Language header apply, but
+// role overlays do not, and scope-gated parameters are rejected, as with
+// ValidateMap.
+func (qv *QueryValidator) ValidateRequest(r *http.Request, rules map[string]string, pathRules map[string]string) []QueryValidationError {
+	var errors []QueryValidationError
+	qv.mu.RLock()
+
+	// Wildcards cannot be listed, so only the declared ones are checked.
+	names := make([]string, 0, len(pathRules))
+	for name := range pathRules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := r.PathValue(name)
+		if value == "" {
+			continue
+		}
+		if reason, ok := qv.checkParamValue(r.Context(), value, pathRules[name]); !ok {
+			errors = append(errors, newValidationError(name, value, reason))
+		}
+	}
+
+	qv.finishErrors(errors)
+	rules = qv.rulesForMediaType(r.Header.Get("Accept"), r.Header.Get("Content-Type"), rules)
+	qv.mu.RUnlock()
+
+	queryErrors, _ := qv.run(flattenValues(r.URL.Query(), rules), rules, validationRequest{route: r.Pattern, ctx: r.Context()})
+	errors = append(errors, queryErrors...)
+	if printer := acceptLanguagePrinter(r.Header.Get("Accept-Language")); printer != nil {
+		renderErrors(errors, printer, nil)
+	}
+	return errors
+}
@@ -0,0 +1,135 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+func TestValidateRequestPathValues(t *testing.T) {
+	qv := NewQueryValidator()
+	pathRules := map[string]string{"id": "integer AND min:1", "slug": "string AND maxlen:5"}
+	rules := map[string]string{"limit": "integer"}
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/users/42/posts/hello?limit=5", nil},
+		{"/users/0/posts/hello", []string{"id:TOO_SMALL"}},
+		{"/users/abc/posts/toolong?limit=x", []string{"id:INVALID_TYPE", "limit:INVALID_TYPE", "slug:TOO_LONG"}},
+		{"/users/7/posts/a?other=1", []string{"other:UNEXPECTED_PARAM"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /users/{id}/posts/{slug}", func(w http.ResponseWriter, r *http.Request) {
+			got = errorCodes(qv.ValidateRequest(r, rules, pathRules))
+		})
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tt.target, nil))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: errors %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRequestPassesRequestContext(t *testing.T) {
+	type key struct{}
+	qv := NewQueryValidator()
+	var seen any
+	qv.AddContextValidator("tenant", time.Second, func(ctx context.Context, v string) (bool, error) {
+		seen = ctx.Value(key{})
+		return v == "acme", nil
+	})
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/orders?tenant=acme", nil},
+		{"/orders?tenant=other", []string{"tenant:INVALID_TYPE"}},
+	}
+	for _, tt := range tests {
+		seen = nil
+		r := httptest.NewRequest("GET", tt.target, nil)
+		r = r.WithContext(context.WithValue(r.Context(), key{}, "request"))
+		got := errorCodes(qv.ValidateRequest(r, map[string]string{"tenant": "tenant"}, nil))
+		if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("%s: errors %v, want %v", tt.target, got, tt.want)
+		}
+		if seen != "request" {
+			t.Errorf("%s: validator saw context value %v, want the request's", tt.target, seen)
+		}
+	}
+}
+
+func TestValidateRequestCanceledContext(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddContextValidator("slow", time.Minute, func(ctx context.Context, v string) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := httptest.NewRequest("GET", "/?q=x", nil).WithContext(ctx)
+	got := errorCodes(qv.ValidateRequest(r, map[string]string{"q": "slow"}, nil))
+	if len(got) != 1 || got[0] != "q:VALIDATOR_TIMEOUT" {
+		t.Errorf("errors %v, want [q:VALIDATOR_TIMEOUT]", got)
+	}
+}
+
+func TestValidateRequestMediaTypeRules(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddMediaTypeRules("text/csv", map[string]string{"delimiter": "in:comma,tab"})
+	rules := map[string]string{"q": "string"}
+	tests := []struct {
+		accept string
+		want   []string
+	}{
+		{"text/csv", nil},
+		{"application/json", []string{"delimiter:UNEXPECTED_PARAM"}},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/export?q=x&delimiter=tab", nil)
+		r.Header.Set("Accept", tt.accept)
+		if got := errorCodes(qv.ValidateRequest(r, rules, nil)); !slices.Equal(got, tt.want) {
+			t.Errorf("Accept %s: errors %v, want %v", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRequestAcceptLanguage(t *testing.T) {
+	if err := RegisterLocale(language.Italian, map[string]string{
+		MsgMissingRequired: "parametro obbligatorio mancante",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	qv := NewQueryValidator()
+	rules := map[string]string{"q": "required", "n": "integer"}
+	tests := []struct {
+		header string
+		wantQ  string
+	}{
+		{"", "missing required parameter"},
+		{"it-IT", "parametro obbligatorio mancante"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/?n=x", nil)
+		if tt.header != "" {
+			r.Header.Set("Accept-Language", tt.header)
+		}
+		messages := make(map[string]string)
+		for _, err := range qv.ValidateRequest(r, rules, nil) {
+			messages[err.Parameter] = err.Message
+		}
+		// Keys the locale leaves out stay in English.
+		if messages["q"] != tt.wantQ || messages["n"] != "invalid value for type integer" {
+			t.Errorf("Accept-Language %q: messages %v, want q %q", tt.header, messages, tt.wantQ)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidNumber reports whether v is a plain decimal number such as -12 or
+// 3.50 that fits a float64. Signs other than a leading minus, exponents and
+// bare or trailing dots are rejected.
+func ValidNumber(v string) bool {
+	whole, fraction, hasDot := strings.Cut(strings.TrimPrefix(v, "-"), ".")
+	if !allDigits(whole) || hasDot && !allDigits(fraction) {
+		return false
+	}
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+// ValidInteger reports whether v is a base 10 integer that fits an int64.
+// As for number, a leading minus is the only sign accepted.
+func ValidInteger(v string) bool {
+	if !allDigits(strings.TrimPrefix(v, "-")) {
+		return false
+	}
+	_, err := strconv.ParseInt(v, 10, 64)
+	return err == nil
+}
+
+// ValidFloat reports whether v is a finite float64 in decimal notation,
+// exponents included. The mantissa may only be signed with a leading minus,
+// while the exponent takes either sign. Hex floats, underscores, Inf and NaN
+// are rejected.
+func ValidFloat(v string) bool {
+	if !decimalShape(v) {
+		return false
+	}
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+// decimalShape reports whether v is an optionally negative mantissa with at
+// least one digit and at most one dot, followed by an optional exponent.
+func decimalShape(v string) bool {
+	mantissa, exponent, hasExponent := strings.Cut(strings.TrimPrefix(v, "-"), "e")
+	if !hasExponent {
+		mantissa, exponent, hasExponent = strings.Cut(mantissa, "E")
+	}
+	whole, fraction, _ := strings.Cut(mantissa, ".")
+	if whole == "" && fraction == "" || whole != "" && !allDigits(whole) || fraction != "" && !allDigits(fraction) {
+		return false
+	}
+	if hasExponent {
+		exponent = strings.TrimPrefix(strings.TrimPrefix(exponent, "+"), "-")
+		return allDigits(exponent)
+	}
+	return true
+}
+
+// ValidDate reports whether v is a calendar date such as 2024-02-29.
+func ValidDate(v string) bool {
+	_, err := time.Parse(time.DateOnly, v)
+	return err == nil
+}
+
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNumberTypes(t *testing.T) {
+	tests := []struct {
+		value           string
+		number, integer bool
+		float           bool
+	}{
+		{"0", true, true, true},
+		{"-12", true, true, true},
+		{"3.50", true, false, true},
+		{"+5", false, false, false},
+		{"+5.0", false, false, false},
+		{"-+5", false, false, false},
+		{"+1e3", false, false, false},
+		{"-1e+3", false, false, true},
+		{"1e-3", false, false, true},
+		{"1e", false, false, false},
+		{"1e3e3", false, false, false},
+		{"1.2.3", false, false, false},
+		{"1e3", false, false, true},
+		{"1.5E-3", false, false, true},
+		{".5", false, false, true},
+		{"5.", false, false, true},
+		{"", false, false, false},
+		{"-", false, false, false},
+		{"12a", false, false, false},
+		{"0x1p3", false, false, false},
+		{"1_000", false, false, false},
+		{"NaN", false, false, false},
+		{"Inf", false, false, false},
+		{"1e400", false, false, false},
+		{"9223372036854775808", true, false, true},
+	}
+	for _, tt := range tests {
+		if got := ValidNumber(tt.value); got != tt.number {
+			t.Errorf("ValidNumber(%q) = %v, want %v", tt.value, got, tt.number)
+		}
+		if got := ValidInteger(tt.value); got != tt.integer {
+			t.Errorf("ValidInteger(%q) = %v, want %v", tt.value, got, tt.integer)
+		}
+		if got := ValidFloat(tt.value); got != tt.float {
+			t.Errorf("ValidFloat(%q) = %v, want %v", tt.value, got, tt.float)
+		}
+	}
+}
+
+func TestValidDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"2024-01-02", true},
+		{"2024-02-29", true},
+		{"2023-02-29", false},
+		{"2024-13-01", false},
+		{"2024-1-2", false},
+		{"2024-01-02T00:00:00Z", false},
+		{"yesterday", false},
+	}
+	for _, tt := range tests {
+		if got := ValidDate(tt.value); got != tt.want {
+			t.Errorf("ValidDate(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNumberRules(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"n": "number", "i": "integer", "f": "float", "d": "date"}
+	checkCodes(t, qv, map[string]string{"n": "-1.5", "i": "42", "f": "2.5e3", "d": "2024-02-29"}, rules)
+	checkCodes(t, qv, map[string]string{"n": "1e3", "i": "4.2", "f": "NaN", "d": "2024-02-30"}, rules,
+		"n:INVALID_TYPE", "i:INVALID_TYPE", "f:INVALID_TYPE", "d:INVALID_TYPE")
+}
+
+// The patterns the number and date types used before they parsed values.
+var (
+	numberPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+	datePattern   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+var (
+	benchNumbers = []string{"3", "-12.50", "1000000", "0.001", "12a"}
+	benchDates   = []string{"2024-01-02", "1999-12-31", "2024-02-30"}
+)
+
+// benchmarkEach runs check on every value once per iteration.
+func benchmarkEach(b *testing.B, values []string, check func(string) bool) {
+	b.ReportAllocs()
+	for range b.N {
+		for _, v := range values {
+			check(v)
+		}
+	}
+}
+
+func BenchmarkNumberRegexp(b *testing.B) { benchmarkEach(b, benchNumbers, numberPattern.MatchString) }
+func BenchmarkNumberParse(b *testing.B)  { benchmarkEach(b, benchNumbers, ValidNumber) }
+func BenchmarkDateRegexp(b *testing.B)   { benchmarkEach(b, benchDates, datePattern.MatchString) }
+func BenchmarkDateParse(b *testing.B)    { benchmarkEach(b, benchDates, ValidDate) }
@@ -0,0 +1,281 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// openAPIFormats are the types that map to a string with a format.
+var openAPIFormats = map[string]string{
+	"date":     "date",
+	"uuid":     "uuid",
+	"uuidv4":   "uuid",
+	"email":    "email",
+	"url":      "uri",
+	"safeurl":  "uri",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"regex":    "regex",
+}
+
+// ToOpenAPIParameters describes the rules as OpenAPI query parameters,
+// sorted by name, so a spec can be generated from the rules instead of
+// being kept in sync by hand. Types, required, in, not_in, min, max,
+// lengths, regex and arrays carry over; custom types and constraints
+// become unconstrained strings.
+func (r Rules) ToOpenAPIParameters() []openapi3.Parameter {
+	params := make([]openapi3.Parameter, 0, len(r))
+	for _, name := range r.params() {
+		schema, required := openAPISchema(r[name])
+		params = append(params, openapi3.Parameter{
+			Name:            name,
+			In:              openapi3.ParameterInQuery,
+			Required:        required,
+			AllowEmptyValue: slices.Contains(ruleTerms(r[name]), flagType),
+			Schema:          schema.NewRef(),
+		})
+	}
+	return params
+}
+
+// ToOpenAPIParameters is Rules.ToOpenAPIParameters with the parameter
+// descriptions of the schema.
+func (s RouteSchema) ToOpenAPIParameters() []openapi3.Parameter {
+	params := s.Rules().ToOpenAPIParameters()
+	descriptions := make(map[string]string, len(s.Params))
+	for _, p := range s.Params {
+		descriptions[p.Name] = p.Description
+	}
+	for i := range params {
+		params[i].Description = descriptions[params[i].Name]
+	}
+	return params
+}
+
+// openAPISchema converts rule, reporting whether it makes the parameter
+// required.
+func openAPISchema(rule string) (*openapi3.Schema, bool) {
+	tokens := openAPITokens(rule)
+	schema, required := schemaOfOr(tokens)
+	if ruleDecrypts(tokens) {
+		// Clients send opaque blobs; the rule describes their plaintext.
+		return openapi3.NewStringSchema(), required
+	}
+	if schema.Type == nil && schema.AnyOf == nil && schema.AllOf == nil && schema.Not == nil {
+		schema.Type = schemaType(openapi3.TypeString)
+	}
+	return schema, required
+}
+
+func ruleDecrypts(tokens []string) bool {
+	for _, tok := range tokens {
+		if strings.HasPrefix(tok, decryptTerm+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// builtins is a validator with only the built-in types and constraints,
+// which tell the terms of rules described without one apart.
+var builtins = sync.OnceValue(NewQueryValidator)
+
+// openAPITokens tokenizes rule the way the parser does, leaving out skip_if
+// conditions, which say when a rule applies rather than what a value is.
+func openAPITokens(rule string) []string {
+	var tokens []string
+	for _, tok := range tokenizeTypeExpr(rule) {
+		if rest, ok := strings.CutPrefix(tok, skipPrefix); ok && rest != "" {
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	return builtins().splitPipes(tokens)
+}
+
+// splitOperands splits tokens on op at depth zero.
+func splitOperands(tokens []string, op string) [][]string {
+	var operands [][]string
+	var current []string
+	depth := 0
+	for _, tok := range tokens {
+		switch tok {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case op:
+			if depth == 0 {
+				operands = append(operands, current)
+				current = nil
+				continue
+			}
+		}
+		current = append(current, tok)
+	}
+	return append(operands, current)
+}
+
+func schemaOfOr(tokens []string) (*openapi3.Schema, bool) {
+	branches := splitOperands(tokens, "OR")
+	if len(branches) == 1 {
+		return schemaOfAnd(branches[0])
+	}
+	schema := &openapi3.Schema{}
+	for _, branch := range branches {
+		sub, _ := schemaOfAnd(branch)
+		schema.AnyOf = append(schema.AnyOf, sub.NewRef())
+	}
+	return schema, false
+}
+
+func schemaOfAnd(tokens []string) (*openapi3.Schema, bool) {
+	schema := &openapi3.Schema{}
+	required := false
+	var elem []string
+	for _, term := range splitOperands(tokens, "AND") {
+		switch {
+		case len(term) == 0:
+		case term[0] == "NOT":
+			sub, _ := schemaOfAnd(term[1:])
+			schema.Not = sub.NewRef()
+		case term[0] == "(" && term[len(term)-1] == ")":
+			sub, _ := schemaOfOr(term[1 : len(term)-1])
+			schema.AllOf = append(schema.AllOf, sub.NewRef())
+		case len(term) == 1:
+			name, arg, _ := strings.Cut(term[0], ":")
+			switch name {
+			case requiredType:
+				required = true
+			case arrayTerm:
+				schema.Type = schemaType(openapi3.TypeArray)
+				elem = append([]string{arg}, elem...)
+			case minItemsTerm:
+				if n := uintArg(arg); n != nil {
+					schema.MinItems = *n
+				}
+			case maxItemsTerm:
+				schema.MaxItems = uintArg(arg)
+			default:
+				elem = append(elem, term[0])
+			}
+		}
+	}
+
+	target := schema
+	if schema.Type.Is(openapi3.TypeArray) {
+		target = &openapi3.Schema{}
+		schema.Items = target.NewRef()
+	}
+	for _, term := range elem {
+		applyOpenAPITerm(target, term)
+	}
+	if target.Type == nil {
+		target.Type = schemaType(openapi3.TypeString)
+	}
+	return schema, required
+}
+
+// applyOpenAPITerm adds what term says about a value to schema.
+func applyOpenAPITerm(schema *openapi3.Schema, term string) {
+	name, arg, _ := strings.Cut(term, ":")
+	if format, ok := openAPIFormats[name]; ok && arg == "" {
+		schema.Type, schema.Format = schemaType(openapi3.TypeString), format
+		return
+	}
+	switch name {
+	case "string":
+		schema.Type = schemaType(openapi3.TypeString)
+	case "number":
+		schema.Type = schemaType(openapi3.TypeNumber)
+	case "boolean", flagType:
+		schema.Type = schemaType(openapi3.TypeBoolean)
+	case "integer":
+		schema.Type, schema.Format = schemaType(openapi3.TypeInteger), "int64"
+	case "int", "uint":
+		schema.Type = schemaType(openapi3.TypeInteger)
+		bits, _ := strconv.Atoi(arg)
+		if bits == 32 || bits == 64 {
+			schema.Format = "int" + arg
+		}
+		if name == "uint" {
+			schema.Min = floatPtr(0)
+			if bits > 0 && bits < 64 {
+				schema.Max = floatPtr(math.Exp2(float64(bits)) - 1)
+			}
+		} else if bits > 0 && bits < 32 {
+			schema.Min = floatPtr(-math.Exp2(float64(bits - 1)))
+			schema.Max = floatPtr(math.Exp2(float64(bits-1)) - 1)
+		}
+	case "float":
+		schema.Type = schemaType(openapi3.TypeNumber)
+		switch arg {
+		case "32":
+			schema.Format = "float"
+		case "64":
+			schema.Format = "double"
+		}
+	case "min":
+		if n, err := strconv.ParseFloat(arg, 64); err == nil {
+			schema.Min = &n
+		}
+	case "max":
+		if n, err := strconv.ParseFloat(arg, 64); err == nil {
+			schema.Max = &n
+		}
+	case "in":
+		schema.Enum = enumValues(arg)
+	case "not_in", "not_eq":
+		schema.Not = (&openapi3.Schema{Enum: enumValues(arg)}).NewRef()
+	case "regex":
+		schema.Pattern = arg
+	case "minlen", "maxlen", "len":
+		// OpenAPI lengths count code points, so only rune lengths map.
+		bounds, unit, err := splitLengthArg(arg)
+		if err != nil || unit != LengthRunes {
+			return
+		}
+		lo, hi := bounds, bounds
+		if name == "len" {
+			if l, h, ok := strings.Cut(bounds, ","); ok {
+				lo, hi = l, h
+			}
+		}
+		if n := uintArg(lo); n != nil && name != "maxlen" {
+			schema.MinLength = *n
+		}
+		if name != "minlen" {
+			schema.MaxLength = uintArg(hi)
+		}
+	}
+}
+
+func schemaType(name string) *openapi3.Types {
+	return &openapi3.Types{name}
+}
+
+func enumValues(list string) []any {
+	var values []any
+	for _, v := range strings.Split(list, ",") {
+		values = append(values, v)
+	}
+	return values
+}
+
+func uintArg(arg string) *uint64 {
+	n, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+func floatPtr(f float64) *float64 { return &f }
@@ -0,0 +1,72 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestToOpenAPIParameters(t *testing.T) {
+	tests := []struct {
+		rule     string
+		required bool
+		schema   string
+	}{
+		{"string", false, `{"type":"string"}`},
+		{"required AND int:32 AND min:1 AND max:10", true, `{"format":"int32","maximum":10,"minimum":1,"type":"integer"}`},
+		{"uint:8", false, `{"maximum":255,"minimum":0,"type":"integer"}`},
+		{"float:64", false, `{"format":"double","type":"number"}`},
+		{"uuid", false, `{"format":"uuid","type":"string"}`},
+		{"in:asc,desc", false, `{"enum":["asc","desc"],"type":"string"}`},
+		{"string AND not_in:admin,root", false, `{"not":{"enum":["admin","root"]},"type":"string"}`},
+		{"string AND len:2,5", false, `{"maxLength":5,"minLength":2,"type":"string"}`},
+		{"string AND minlen:3", false, `{"minLength":3,"type":"string"}`},
+		{"string AND maxlen:8:bytes", false, `{"type":"string"}`},
+		{"string|minlen:3", false, `{"minLength":3,"type":"string"}`},
+		{"regex:^(a|b)$", false, `{"pattern":"^(a|b)$","type":"string"}`},
+		{"array:integer AND minitems:1 AND maxitems:3", false, `{"items":{"format":"int64","type":"integer"},"maxItems":3,"minItems":1,"type":"array"}`},
+		{"uuid OR email", false, `{"anyOf":[{"format":"uuid","type":"string"},{"format":"email","type":"string"}]}`},
+		{"skip_if:mode=all AND required AND boolean", true, `{"type":"boolean"}`},
+	}
+	for _, tt := range tests {
+		params := Rules{"p": tt.rule}.ToOpenAPIParameters()
+		if len(params) != 1 {
+			t.Fatalf("%q: got %d parameters, want 1", tt.rule, len(params))
+		}
+		p := params[0]
+		if p.Name != "p" || p.In != openapi3.ParameterInQuery || p.Required != tt.required {
+			t.Errorf("%q: got name %q, in %q, required %v", tt.rule, p.Name, p.In, p.Required)
+		}
+		got, err := json.Marshal(p.Schema.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.schema {
+			t.Errorf("%q: schema %s, want %s", tt.rule, got, tt.schema)
+		}
+	}
+}
+
+func TestToOpenAPIParametersOrderAndFlags(t *testing.T) {
+	params := Rules{"verbose": "flag", "id": "required AND uuid"}.ToOpenAPIParameters()
+	if len(params) != 2 || params[0].Name != "id" || params[1].Name != "verbose" {
+		t.Fatalf("parameters not sorted by name: %+v", params)
+	}
+	if params[0].AllowEmptyValue || !params[1].AllowEmptyValue {
+		t.Errorf("AllowEmptyValue = %v, %v, want false, true", params[0].AllowEmptyValue, params[1].AllowEmptyValue)
+	}
+}
+
+func TestRouteSchemaToOpenAPIParameters(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetDescription("created_after", "Filters users created after this date")
+	qv.RegisterRoute("/users", map[string]string{"created_after": "date", "q": "string"})
+	schema, _ := qv.Schema("/users")
+	params := schema.ToOpenAPIParameters()
+	if params[0].Name != "created_after" || params[0].Description != "Filters users created after this date" || params[1].Description != "" {
+		t.Errorf("OpenAPI parameters %+v", params)
+	}
+}
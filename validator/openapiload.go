@@ -0,0 +1,252 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// NewQueryValidatorFromOpenAPI builds a validator from the query parameters
+// of the operation operationID in the OpenAPI 3 document at specPath, so a
+// spec that already describes them need not be repeated as rules. The rules
+// are returned and registered for the operation's path, with {param}
+// segments written the Fiber way as :param. Documents may be JSON or YAML;
+// references must point within the document.
+func NewQueryValidatorFromOpenAPI(specPath, operationID string) (*QueryValidator, Rules, error) {
+	doc, err := openapi3.NewLoader().LoadFromFile(specPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: invalid OpenAPI document: %v", specPath, err)
+	}
+	route, rules, err := documentRules(doc, operationID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", specPath, err)
+	}
+	qv := NewQueryValidator()
+	qv.RegisterRoute(route, rules)
+	return qv, rules, nil
+}
+
+// RulesFromOpenAPI converts the query parameters of the operation
+// operationID in the OpenAPI 3 document spec, JSON or YAML, into rules,
+// along with the operation's route. Parameters declared on the path item
+// apply unless the operation redeclares them. Types, formats, enums,
+// bounds, lengths, patterns, arrays and required flags carry over; other
+// keywords are ignored.
+func RulesFromOpenAPI(spec []byte, operationID string) (string, Rules, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid OpenAPI document: %v", err)
+	}
+	return documentRules(doc, operationID)
+}
+
+// documentRules finds the operation operationID in the loaded doc and
+// converts its query parameters.
+func documentRules(doc *openapi3.T, operationID string) (string, Rules, error) {
+	if doc.Paths != nil {
+		for path, item := range doc.Paths.Map() {
+			for _, op := range item.Operations() {
+				if op.OperationID != operationID {
+					continue
+				}
+				params := append(slices.Clone(item.Parameters), op.Parameters...)
+				rules, err := queryRules(params)
+				if err != nil {
+					return "", nil, fmt.Errorf("operation %s: %v", operationID, err)
+				}
+				return fiberRoute(path), rules, nil
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("no operation %s", operationID)
+}
+
+// queryRules converts the query parameters of params, later declarations
+// replacing earlier ones.
+func queryRules(params openapi3.Parameters) (Rules, error) {
+	rules := make(Rules)
+	for _, ref := range params {
+		p := ref.Value
+		if p == nil {
+			return nil, fmt.Errorf("unresolved reference %s", ref.Ref)
+		}
+		if p.In != openapi3.ParameterInQuery {
+			continue
+		}
+		terms, err := schemaTerms(p.Schema, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", p.Name, err)
+		}
+		if p.Required {
+			terms = append([]string{requiredType}, terms...)
+		}
+		if len(terms) == 0 {
+			terms = []string{"string"}
+		}
+		rules[p.Name] = strings.Join(terms, " AND ")
+	}
+	return rules, nil
+}
+
+// schemaTerms converts the schema ref points to into AND terms. depth
+// guards against reference cycles, which the loader leaves in place.
+func schemaTerms(ref *openapi3.SchemaRef, depth int) ([]string, error) {
+	if ref == nil || ref.Value == nil {
+		return nil, nil
+	}
+	if depth > 16 {
+		return nil, fmt.Errorf("schema nested too deeply")
+	}
+	schema := ref.Value
+
+	if alternatives := append(slices.Clone(schema.AnyOf), schema.OneOf...); len(alternatives) > 0 {
+		branches := make([]string, 0, len(alternatives))
+		for _, alt := range alternatives {
+			terms, err := schemaTerms(alt, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if len(terms) == 0 {
+				return nil, nil // one branch accepts anything
+			}
+			branch := strings.Join(terms, " AND ")
+			if len(terms) > 1 {
+				branch = "(" + branch + ")"
+			}
+			branches = append(branches, branch)
+		}
+		return []string{"(" + strings.Join(branches, " OR ") + ")"}, nil
+	}
+
+	var terms []string
+	if schema.Type.Is(openapi3.TypeArray) {
+		elem, err := schemaTerms(schema.Items, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(elem) == 0 || strings.ContainsAny(elem[0], " ()") {
+			// Element rules that are not a plain type stay unchecked.
+			elem = []string{"string"}
+		}
+		terms = append(terms, arrayTerm+":"+elem[0])
+		terms = append(terms, elem[1:]...)
+		if schema.MinItems > 0 {
+			terms = append(terms, minItemsTerm+":"+strconv.FormatUint(schema.MinItems, 10))
+		}
+		if schema.MaxItems != nil {
+			terms = append(terms, maxItemsTerm+":"+strconv.FormatUint(*schema.MaxItems, 10))
+		}
+		return terms, nil
+	}
+
+	terms = append(terms, specTypeTerm(schema)...)
+	if schema.Min != nil {
+		terms = append(terms, "min:"+strconv.FormatFloat(*schema.Min, 'f', -1, 64))
+	}
+	if schema.Max != nil {
+		terms = append(terms, "max:"+strconv.FormatFloat(*schema.Max, 'f', -1, 64))
+	}
+	switch {
+	case schema.MaxLength != nil && schema.MinLength == *schema.MaxLength:
+		terms = append(terms, "len:"+strconv.FormatUint(schema.MinLength, 10))
+	default:
+		if schema.MinLength > 0 {
+			terms = append(terms, "minlen:"+strconv.FormatUint(schema.MinLength, 10))
+		}
+		if schema.MaxLength != nil {
+			terms = append(terms, "maxlen:"+strconv.FormatUint(*schema.MaxLength, 10))
+		}
+	}
+	if schema.Pattern != "" {
+		// Rules are split on whitespace, so such patterns cannot be kept.
+		if strings.ContainsAny(schema.Pattern, " \t") {
+			return nil, fmt.Errorf("pattern %q contains whitespace", schema.Pattern)
+		}
+		terms = append(terms, "regex:"+schema.Pattern)
+	}
+	if len(schema.Enum) > 0 {
+		list, err := enumList(schema.Enum)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, "in:"+list)
+	}
+	if schema.Not != nil && schema.Not.Value != nil && len(schema.Not.Value.Enum) > 0 {
+		list, err := enumList(schema.Not.Value.Enum)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, "not_in:"+list)
+	}
+	return terms, nil
+}
+
+// specTypeTerm is the type term for the type and format of schema.
+func specTypeTerm(schema *openapi3.Schema) []string {
+	switch {
+	case schema.Type.Is(openapi3.TypeInteger):
+		if schema.Format == "int32" {
+			return []string{"int:32"}
+		}
+		return []string{"int:64"}
+	case schema.Type.Is(openapi3.TypeNumber):
+		switch schema.Format {
+		case "float":
+			return []string{"float:32"}
+		case "double":
+			return []string{"float:64"}
+		}
+		return []string{"number"}
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return []string{"boolean"}
+	case schema.Type.Is(openapi3.TypeString):
+		if name, ok := specFormatTypes[schema.Format]; ok {
+			return []string{name}
+		}
+		return []string{"string"}
+	}
+	return nil
+}
+
+// specFormatTypes are the types of string formats. A format several types
+// describe maps to the one checking the least, so uri is url rather than
+// safeurl, which also resolves hosts.
+var specFormatTypes = map[string]string{
+	"date":     "date",
+	"uuid":     "uuid",
+	"email":    "email",
+	"uri":      "url",
+	"url":      "url",
+	"ipv4":     "ipv4",
+	"ipv6":     "ipv6",
+	"hostname": "hostname",
+	"regex":    "regex",
+}
+
+// enumList joins values into an in or not_in argument. Rules are split on
+// whitespace and lists on commas, so values containing either cannot be
+// kept.
+func enumList(values []any) (string, error) {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = fmt.Sprint(v)
+		if strings.ContainsFunc(items[i], func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			return "", fmt.Errorf("enum value %q contains a comma or whitespace", items[i])
+		}
+	}
+	return strings.Join(items, ","), nil
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// fiberRoute turns an OpenAPI path template into a Fiber route.
+func fiberRoute(path string) string {
+	return pathParamPattern.ReplaceAllString(path, ":$1")
+}
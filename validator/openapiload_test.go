@@ -0,0 +1,200 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const specWithSharedParams = `{
+  "paths": {
+    "/users/{id}/orders": {
+      "parameters": [
+        {"name": "tenant", "in": "query", "required": true, "schema": {"type": "string", "format": "uuid"}}
+      ],
+      "get": {
+        "operationId": "listOrders",
+        "parameters": [
+          {"$ref": "#/components/parameters/Limit"},
+          {"name": "id", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "callback", "in": "query", "schema": {"type": "string", "format": "uri"}},
+          {"name": "sort", "in": "query", "schema": {"type": "string", "enum": ["asc", "desc"]}},
+          {"name": "tags", "in": "query", "schema": {"type": "array", "items": {"type": "integer"}, "maxItems": 5}}
+        ]
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "Limit": {"name": "limit", "in": "query", "schema": {"type": "integer", "format": "int32", "minimum": 1, "maximum": 100}}
+    }
+  }
+}`
+
+func TestRulesFromOpenAPI(t *testing.T) {
+	route, rules, err := RulesFromOpenAPI([]byte(specWithSharedParams), "listOrders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route != "/users/:id/orders" {
+		t.Errorf("route = %q, want /users/:id/orders", route)
+	}
+	want := Rules{
+		"tenant":   "required AND uuid",
+		"limit":    "int:32 AND min:1 AND max:100",
+		"callback": "url",
+		"sort":     "string AND in:asc,desc",
+		"tags":     "array:int:64 AND maxitems:5",
+	}
+	if !maps.Equal(rules, want) {
+		t.Errorf("rules = %v, want %v", rules, want)
+	}
+}
+
+func TestRulesFromOpenAPIFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"uri", "url"},
+		{"url", "url"},
+		{"uuid", "uuid"},
+		{"date", "date"},
+		{"email", "email"},
+		{"ipv4", "ipv4"},
+		{"hostname", "hostname"},
+		{"binary", "string"},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			got := specTypeTerm(&openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}, Format: tt.format})
+			if len(got) != 1 || got[0] != tt.want {
+				t.Fatalf("format %s: got %v, want %s", tt.format, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestRulesFromOpenAPIErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{
+			"enum with whitespace",
+			`{"paths": {"/a": {"get": {"operationId": "op", "parameters": [
+				{"name": "q", "in": "query", "schema": {"type": "string", "enum": ["a b", "c"]}}]}}}}`,
+			"parameter q: enum value \"a b\" contains a comma or whitespace",
+		},
+		{
+			"not enum with a comma",
+			`{"paths": {"/a": {"get": {"operationId": "op", "parameters": [
+				{"name": "q", "in": "query", "schema": {"type": "string", "not": {"enum": ["a,b"]}}}]}}}}`,
+			"parameter q: enum value \"a,b\" contains a comma or whitespace",
+		},
+		{
+			"pattern with whitespace",
+			`{"paths": {"/a": {"get": {"operationId": "op", "parameters": [
+				{"name": "q", "in": "query", "schema": {"type": "string", "pattern": "^a b$"}}]}}}}`,
+			"contains whitespace",
+		},
+		{
+			"malformed path parameters",
+			`{"paths": {"/a": {"parameters": {"name": "q"}, "get": {"operationId": "op"}}}}`,
+			"invalid OpenAPI document",
+		},
+		{
+			"unresolved reference",
+			`{"paths": {"/a": {"get": {"operationId": "op", "parameters": [{"$ref": "#/components/parameters/Q"}]}}}}`,
+			`"#/components/parameters/Q"`,
+		},
+		{
+			"missing operation",
+			`{"paths": {}}`,
+			"no operation op",
+		},
+	}
+	for _, tt := range tests {
+		_, _, err := RulesFromOpenAPI([]byte(tt.spec), "op")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestRulesFromOpenAPIValidates(t *testing.T) {
+	_, rules, err := RulesFromOpenAPI([]byte(specWithSharedParams), "listOrders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qv := NewQueryValidator()
+	tenant := "3f2504e0-4f89-41d3-9a0c-0305e82c3301"
+	checkCodes(t, qv, map[string]string{"tenant": tenant, "sort": "asc", "limit": "10"}, rules)
+	checkCodes(t, qv, map[string]string{"tenant": tenant, "sort": "up"}, rules, "sort:NOT_ALLOWED")
+	checkCodes(t, qv, map[string]string{"sort": "asc"}, rules, "tenant:MISSING_REQUIRED")
+}
+
+const yamlSpecWithRefs = `
+openapi: 3.0.3
+info: {title: orders, version: "1"}
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      parameters:
+        - $ref: '#/components/parameters/Status'
+        - name: page
+          in: query
+          schema:
+            $ref: '#/components/schemas/Page'
+components:
+  parameters:
+    Status:
+      name: status
+      in: query
+      required: true
+      schema:
+        $ref: '#/components/schemas/Status'
+  schemas:
+    Status:
+      type: string
+      enum: [open, closed]
+    Page:
+      type: integer
+      minimum: 1
+`
+
+func TestRulesFromOpenAPIYAMLAndRefs(t *testing.T) {
+	want := Rules{
+		"status": "required AND string AND in:open,closed",
+		"page":   "int:64 AND min:1",
+	}
+	route, rules, err := RulesFromOpenAPI([]byte(yamlSpecWithRefs), "listOrders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route != "/orders" || !maps.Equal(rules, want) {
+		t.Errorf("got %q %v, want /orders %v", route, rules, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	if err := os.WriteFile(path, []byte(yamlSpecWithRefs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	qv, rules, err := NewQueryValidatorFromOpenAPI(path, "listOrders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(rules, want) {
+		t.Errorf("file rules = %v, want %v", rules, want)
+	}
+	checkCodes(t, qv, map[string]string{"status": "open", "page": "2"}, rules)
+	checkCodes(t, qv, map[string]string{"status": "lost", "page": "0"}, rules, "page:TOO_SMALL", "status:NOT_ALLOWED")
+}
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicHandler is told about panics recovered from user-supplied validators,
+// constraints, cross rules and defaults. name identifies what panicked.
+type PanicHandler func(name string, recovered any, stack []byte)
+
+// SetPanicHandler registers the handler notified of recovered panics, e.g.
+// to log them or page someone. The request itself gets an INTERNAL_ERROR
+// validation error instead of taking the server down.
+func (qv *QueryValidator) SetPanicHandler(handler PanicHandler) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.panicHandler = handler
+}
+
+func (qv *QueryValidator) notifyPanic(name string, recovered any) {
+	if qv.panicHandler != nil {
+		qv.panicHandler(name, recovered, debug.Stack())
+	}
+}
+
+// safeCheck runs check, turning a panic into a false result and a notification.
+func (qv *QueryValidator) safeCheck(name string, check func(string) bool, value string) (valid, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			qv.notifyPanic(name, r)
+			valid, panicked = false, true
+		}
+	}()
+	return check(value), false
+}
+
+// safeFactory builds a constraint, turning a panic into an error.
+func (qv *QueryValidator) safeFactory(name string, factory ConstraintFactory, arg string) (accept func(string) bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			qv.notifyPanic("constraint "+name, r)
+			accept, err = nil, fmt.Errorf("constraint panicked: %v", r)
+		}
+	}()
+	return factory(arg)
+}
+
+// safeCrossRule runs rule, turning a panic into a single internal error.
+func (qv *QueryValidator) safeCrossRule(index int, rule CrossRule, values map[string]string) (errors []QueryValidationError) {
+	defer func() {
+		if r := recover(); r != nil {
+			name := fmt.Sprintf("cross rule #%d", index)
+			qv.notifyPanic(name, r)
+			errors = []QueryValidationError{newValidationError("", "", fail(MsgInternalError, name))}
+		}
+	}()
+	return rule(values)
+}
+
+// safeDefault runs fn, yielding no default when it panics.
+func (qv *QueryValidator) safeDefault(param string, fn DefaultFunc, values map[string]string) (value string) {
+	defer func() {
+		if r := recover(); r != nil {
+			qv.notifyPanic("default for "+param, r)
+			value = ""
+		}
+	}()
+	return fn(values)
+}
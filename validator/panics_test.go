@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	qv := NewQueryValidator()
+	var panicked []string
+	qv.SetPanicHandler(func(name string, recovered any, stack []byte) {
+		if len(stack) == 0 {
+			t.Errorf("%s: no stack", name)
+		}
+		panicked = append(panicked, name)
+	})
+	qv.AddTypeValidator("buggy", func(string) bool { panic("type") })
+	qv.AddContextValidator("remote", time.Second, func(context.Context, string) (bool, error) { panic("remote") })
+	qv.AddConstraint("bad_factory", func(string) (func(string) bool, error) { panic("factory") })
+	qv.AddConstraint("bad_check", func(string) (func(string) bool, error) {
+		return func(string) bool { panic("check") }, nil
+	})
+	qv.SetDefault("d", func(map[string]string) string { panic("default") })
+	qv.AddCrossRule(func(map[string]string) []QueryValidationError { panic("cross") })
+
+	rules := map[string]string{
+		"a": "buggy",
+		"b": "remote",
+		"c": "bad_factory:1",
+		"e": "bad_check:1",
+		"d": "string",
+	}
+	values := map[string]string{"a": "1", "b": "1", "c": "1", "e": "1"}
+	errs := qv.ValidateMap(values, rules)
+	want := []string{":INTERNAL_ERROR", "a:INTERNAL_ERROR", "b:INTERNAL_ERROR", "c:INVALID_RULE", "e:INTERNAL_ERROR"}
+	if got := errorCodes(errs); !slices.Equal(got, want) {
+		t.Errorf("errors %v, want %v", got, want)
+	}
+	if _, ok := values["d"]; ok {
+		t.Error("panicking default filled a value")
+	}
+	slices.Sort(panicked)
+	wantPanics := []string{"bad_check:1", "buggy", "constraint bad_factory", "cross rule #0", "default for d", "remote"}
+	if !slices.Equal(panicked, wantPanics) {
+		t.Errorf("panics reported for %v, want %v", panicked, wantPanics)
+	}
+}
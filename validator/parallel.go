@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"slices"
+	"sync"
+)
+
+// SetParallelism lets validation run up to n expensive checks of a request
+// at once: the parameters whose rules use context validators, and the
+// repository lookups of exists terms. Their errors follow those of the other
+// parameters, ordered by parameter name, so a request gets the same errors
+// whichever check finishes first. n below 2 validates sequentially, the
+// default.
+func (qv *QueryValidator) SetParallelism(n int) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.parallelism = n
+}
+
+// expensive reports whether rule calls a context validator.
+func (qv *QueryValidator) expensive(rule string) bool {
+	if len(qv.contextValidators) == 0 {
+		return false
+	}
+	if spec, ok := parseArrayRule(rule); ok {
+		rule = spec.elem
+	}
+	for _, tok := range qv.splitPipes(tokenizeTypeExpr(rule)) {
+		if _, ok := qv.contextValidators[tok]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// validateParams validates each parameter of queries, running those with
+// expensive rules concurrently when parallelism allows.
+func (qv *QueryValidator) validateParams(queries, rules map[string]string, req validationRequest) []QueryValidationError {
+	var errors []QueryValidationError
+	var slow []string
+	for param, value := range queries {
+		if qv.parallelism > 1 && qv.expensive(qv.lookupRule(rules, req, param)) {
+			slow = append(slow, param)
+			continue
+		}
+		errors = append(errors, qv.validateParam(param, value, rules, req)...)
+	}
+	if len(slow) == 0 {
+		return errors
+	}
+
+	slices.Sort(slow)
+	results := make([][]QueryValidationError, len(slow))
+	qv.inParallel(len(slow), func(i int) {
+		results[i] = qv.validateParam(slow[i], queries[slow[i]], rules, req)
+	})
+	for _, result := range results {
+		errors = append(errors, result...)
+	}
+	return errors
+}
+
+// inParallel calls fn with 0 through n-1, at most qv.parallelism at a time,
+// and waits for the calls to return.
+func (qv *QueryValidator) inParallel(n int, fn func(i int)) {
+	if qv.parallelism < 2 || n < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	slots := make(chan struct{}, qv.parallelism)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,96 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// inFlight counts the checks running at once and the most seen.
+type inFlight struct {
+	mu        sync.Mutex
+	now, peak int
+}
+
+func (f *inFlight) run(d time.Duration) {
+	f.mu.Lock()
+	f.now++
+	f.peak = max(f.peak, f.now)
+	f.mu.Unlock()
+	time.Sleep(d)
+	f.mu.Lock()
+	f.now--
+	f.mu.Unlock()
+}
+
+func TestSetParallelism(t *testing.T) {
+	values := make(map[string]string)
+	rules := make(map[string]string)
+	for i := range 6 {
+		param := fmt.Sprintf("p%d", i)
+		values[param] = "ok"
+		rules[param] = "slow"
+	}
+	values["p1"], values["p4"] = "bad", "bad"
+	values["n"], rules["n"] = "x", "integer"
+
+	for _, n := range []int{0, 1, 2, 4} {
+		qv := NewQueryValidator()
+		var flight inFlight
+		qv.AddContextValidator("slow", time.Second, func(ctx context.Context, v string) (bool, error) {
+			flight.run(5 * time.Millisecond)
+			return v == "ok", nil
+		})
+		qv.SetParallelism(n)
+		errors := qv.ValidateMap(maps.Clone(values), rules)
+		var params []string
+		for _, err := range errors {
+			params = append(params, err.Parameter)
+		}
+		// In parallel, expensive parameters come last, in name order.
+		if n < 2 {
+			slices.Sort(params)
+		}
+		if want := []string{"n", "p1", "p4"}; !slices.Equal(params, want) {
+			t.Errorf("parallelism %d: errors on %v, want %v", n, params, want)
+		}
+		want := max(n, 1)
+		if flight.peak != want {
+			t.Errorf("parallelism %d: %d checks ran at once, want %d", n, flight.peak, want)
+		}
+	}
+}
+
+// slowRepository is a fakeRepository whose lookups take a while.
+type slowRepository struct {
+	fakeRepository
+	flight inFlight
+}
+
+func (r *slowRepository) Exists(ctx context.Context, table, column string, values []string) (map[string]bool, error) {
+	r.flight.run(5 * time.Millisecond)
+	return r.fakeRepository.Exists(ctx, table, column, values)
+}
+
+func TestParallelExistsLookups(t *testing.T) {
+	for _, n := range []int{1, 3} {
+		qv := NewQueryValidator()
+		repo := &slowRepository{fakeRepository: fakeRepository{rows: map[string][]string{
+			"users.id": {"1"}, "teams.slug": {"core"}, "tags.name": {"go"},
+		}}}
+		qv.SetRepository(repo, RepositoryOptions{})
+		qv.SetParallelism(n)
+		rules := map[string]string{"user": "exists:users.id", "team": "exists:teams.slug", "tag": "exists:tags.name"}
+		checkCodes(t, qv, map[string]string{"user": "1", "team": "ops", "tag": "go"}, rules, "team:NOT_FOUND")
+		if got := repo.takeLookups(); len(got) != 3 {
+			t.Errorf("parallelism %d: lookups %v, want one per table", n, got)
+		}
+		if repo.flight.peak != n {
+			t.Errorf("parallelism %d: %d lookups ran at once, want %d", n, repo.flight.peak, n)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// Evaluation priorities. Lower runs first.
+const (
+	PriorityDefault = 0
+	// PriorityRemote is the default for types added with
+	// AddContextValidator, which typically make remote calls.
+	PriorityRemote = 100
+)
+
+// SetPriority sets when the named type or constraint is evaluated among the
+// operands of AND and OR: lower priorities run first and equal ones keep
+// their written order. Evaluation stops at the first failing AND operand and
+// the first matching OR operand, so putting cheap checks first bounds the
+// cost of bad values: "number AND remoteCheck" never calls remoteCheck for
+// "abc".
+func (qv *QueryValidator) SetPriority(name string, priority int) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.priorities[name] = priority
+}
+
+// priority of an expression is that of its most expensive part.
+func (qv *QueryValidator) priority(expr typeExpr) int {
+	switch e := expr.(type) {
+	case typeRef:
+		if p, ok := qv.priorities[string(e)]; ok {
+			return p
+		}
+		if _, remote := qv.contextValidators[string(e)]; remote {
+			return PriorityRemote
+		}
+	case constraintRef:
+		name, _, _ := strings.Cut(e.term, ":")
+		if p, ok := qv.priorities[name]; ok {
+			return p
+		}
+	case notExpr:
+		return qv.priority(e.operand)
+	case andExpr:
+		return qv.maxPriority(e)
+	case orExpr:
+		return qv.maxPriority(e)
+	}
+	return PriorityDefault
+}
+
+func (qv *QueryValidator) maxPriority(exprs []typeExpr) int {
+	p := PriorityDefault
+	for _, e := range exprs {
+		p = max(p, qv.priority(e))
+	}
+	return p
+}
+
+func (qv *QueryValidator) sortByPriority(exprs []typeExpr) {
+	slices.SortStableFunc(exprs, func(a, b typeExpr) int {
+		return cmp.Compare(qv.priority(a), qv.priority(b))
+	})
+}
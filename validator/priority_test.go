@@ -0,0 +1,86 @@
+package validator
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// callLog records the order validators ran in.
+type callLog struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (l *callLog) validator(name string, pass bool) func(string) bool {
+	return func(string) bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.names = append(l.names, name)
+		return pass
+	}
+}
+
+func (l *callLog) take() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	names := l.names
+	l.names = nil
+	return names
+}
+
+func TestSetPriority(t *testing.T) {
+	tests := []struct {
+		rule       string
+		priorities map[string]int
+		want       []string
+	}{
+		{"slow AND fast", nil, []string{"slow", "fast"}},
+		{"slow AND fast", map[string]int{"slow": 10}, []string{"fast", "slow"}},
+		{"slow AND failing AND fast", map[string]int{"slow": 10}, []string{"failing"}},
+		{"slow AND failing AND other", map[string]int{"slow": 10, "failing": 5}, []string{"other", "failing"}},
+		{"fast OR slow", map[string]int{"fast": 10}, []string{"slow"}},
+		{"(slow AND fast) OR other", map[string]int{"slow": 10}, []string{"other"}},
+		{"NOT slow AND fast", map[string]int{"slow": 10}, []string{"fast", "slow"}},
+		{"slow AND fast AND min:5", map[string]int{"min": -1, "slow": 10}, []string{"fast", "slow"}},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		var log callLog
+		qv.AddTypeValidator("slow", log.validator("slow", true))
+		qv.AddTypeValidator("fast", log.validator("fast", true))
+		qv.AddTypeValidator("other", log.validator("other", true))
+		qv.AddTypeValidator("failing", log.validator("failing", false))
+		for name, p := range tt.priorities {
+			qv.SetPriority(name, p)
+		}
+		// Digits have no suggestion candidates to validate.
+		qv.ValidateMap(map[string]string{"p": "7"}, map[string]string{"p": tt.rule})
+		if got := log.take(); !slices.Equal(got, tt.want) {
+			t.Errorf("%q with %v: ran %v, want %v", tt.rule, tt.priorities, got, tt.want)
+		}
+	}
+}
+
+func TestContextValidatorsRunLast(t *testing.T) {
+	qv := NewQueryValidator()
+	var log callLog
+	qv.AddContextValidator("remote", time.Second, func(_ context.Context, v string) (bool, error) {
+		return log.validator("remote", true)(v), nil
+	})
+	qv.AddTypeValidator("local", log.validator("local", false))
+	rules := map[string]string{"p": "remote AND local"}
+
+	checkCodes(t, qv, map[string]string{"p": "7"}, rules, "p:INVALID_TYPE")
+	if got := log.take(); !slices.Equal(got, []string{"local"}) {
+		t.Errorf("ran %v, want the local check only", got)
+	}
+
+	qv.SetPriority("remote", PriorityDefault-1)
+	checkCodes(t, qv, map[string]string{"p": "7"}, rules, "p:INVALID_TYPE")
+	if got := log.take(); !slices.Equal(got, []string{"remote", "local"}) {
+		t.Errorf("ran %v after lowering the remote priority, want remote first", got)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package validator
+
+// raceEnabled is set when tests run under the race detector, which makes
+// sync.Pool drop items at random and allocation counts unreliable.
+const raceEnabled = true
@@ -0,0 +1,111 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// EncodingPolicy controls how ValidateRawQuery decodes components. The zero
+// value is strict RFC 3986.
+type EncodingPolicy struct {
+	// PlusAsSpace decodes '+' as a space, as HTML forms encode it, instead
+	// of keeping it literally.
+	PlusAsSpace bool
+	// PassMalformed keeps bad %XX sequences and characters that should have
+	// been escaped as they are instead of rejecting the pair with an
+	// INVALID_ENCODING error.
+	PassMalformed bool
+}
+
+// SetEncodingPolicy sets how ValidateRawQuery decodes queries.
+func (qv *QueryValidator) SetEncodingPolicy(policy EncodingPolicy) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.encoding = policy
+}
+
+// ValidateRawQuery validates a raw query string such as "a=1&b=x" without
+// relying on a framework's parser, which may mangle or silently drop
+// malformed pairs. Components are decoded per the EncodingPolicy, strict RFC
+// 3986 by default. Otherwise it behaves like ValidateValues: repeated keys
+// of array parameters are joined and other parameters keep the last value.
+func (qv *QueryValidator) ValidateRawQuery(raw string, rules map[string]string) []QueryValidationError {
+	var errors []QueryValidationError
+	values := make(url.Values)
+	qv.mu.RLock()
+	for _, pair := range strings.Split(strings.TrimPrefix(raw, "?"), "&") {
+		if pair == "" {
+			continue
+		}
+		rawKey, rawValue, _ := strings.Cut(pair, "=")
+		key, err := decodeQueryComponent(rawKey, qv.encoding)
+		if err != nil {
+			errors = append(errors, newValidationError(rawKey, rawValue, fail(MsgInvalidEncoding, err)))
+			continue
+		}
+		value, err := decodeQueryComponent(rawValue, qv.encoding)
+		if err != nil {
+			errors = append(errors, newValidationError(key, rawValue, fail(MsgInvalidEncoding, err)))
+			continue
+		}
+		values[key] = append(values[key], value)
+	}
+
+	qv.finishErrors(errors)
+	qv.mu.RUnlock()
+
+	valueErrors, _ := qv.run(flattenValues(values, rules), rules, validationRequest{})
+	return append(errors, valueErrors...)
+}
+
+// decodeQueryComponent percent-decodes s under policy.
+func decodeQueryComponent(s string, policy EncodingPolicy) (string, error) {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1:i+3]) {
+				if policy.PassMalformed {
+					b.WriteByte(c)
+					continue
+				}
+				end := min(i+3, len(s))
+				return "", fmt.Errorf("invalid escape %q at offset %d", s[i:end], i)
+			}
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		case c == '+' && policy.PlusAsSpace:
+			b.WriteByte(' ')
+		case !isQueryChar(rune(c)) && !policy.PassMalformed:
+			return "", fmt.Errorf("unescaped %q at offset %d", c, i)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// isQueryChar reports whether r may appear unescaped in a query component:
+// unreserved, sub-delims other than '&', ':', '@', '/' and '?'. Pairs are
+// split at their first '=', so later ones, as in base64 padding, belong to
+// the value.
+func isQueryChar(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("-._~!$'()*+,;=:@/?", r)
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c <= '9':
+		return c - '0'
+	case c <= 'F':
+		return c - 'A' + 10
+	}
+	return c - 'a' + 10
+}
@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestValidateRawQuery(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"n": "int:32 AND min:1", "q": "string AND maxlen:3"}
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"n=1&q=a%20b", nil},
+		{"n=1&q=a%20bc", []string{"q:TOO_LONG"}},
+		{"?n=1&q=a+b", nil},
+		{"n=1&&q=a%2Bb&", nil},
+		{"n=0&n=5", nil},
+		{"n=0", []string{"n:TOO_SMALL"}},
+		{"n=%zz", []string{"n:INVALID_ENCODING"}},
+		{"n=1%2", []string{"n:INVALID_ENCODING"}},
+		{"n=1&q=a b", []string{"q:INVALID_ENCODING"}},
+		{"n%=1", []string{"n%:INVALID_ENCODING"}},
+		{"n=1&x=2", []string{"x:UNEXPECTED_PARAM"}},
+		{"n=1&q=a==", nil},
+		{"n=1&q=YQ%3D%3D&q=a==", nil},
+		{"n=1&q=a=bc", []string{"q:TOO_LONG"}},
+	}
+	for _, tt := range tests {
+		if got := errorCodes(qv.ValidateRawQuery(tt.raw, rules)); !slices.Equal(got, tt.want) {
+			t.Errorf("ValidateRawQuery(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestValidateRawQueryMatchesValidateValues(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"ids": "array:integer AND maxitems:2", "n": "integer"}
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"ids=1&ids=2", nil},
+		{"ids=1&ids=x", []string{"ids:INVALID_TYPE"}},
+		{"ids=1&ids=2&ids=3", []string{"ids:TOO_MANY_ITEMS"}},
+		{"ids=1,2&n=5&n=x", []string{"n:INVALID_TYPE"}},
+	}
+	for _, tt := range tests {
+		values, err := url.ParseQuery(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw := errorCodes(qv.ValidateRawQuery(tt.raw, rules))
+		parsed := errorCodes(qv.ValidateValues(values, rules))
+		if !slices.Equal(raw, tt.want) || !slices.Equal(parsed, tt.want) {
+			t.Errorf("%q: ValidateRawQuery %v, ValidateValues %v, want %v", tt.raw, raw, parsed, tt.want)
+		}
+	}
+}
+
+func TestValidateRawQueryEncodingMessage(t *testing.T) {
+	errs := NewQueryValidator().ValidateRawQuery("n=%G1", map[string]string{"n": "string"})
+	if len(errs) != 1 || errs[0].Value != "%G1" || errs[0].Message != `invalid percent-encoding: invalid escape "%G1" at offset 0` {
+		t.Errorf("errors = %+v", errs)
+	}
+}
+
+func TestDecodeQueryComponent(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"abc", "abc", false},
+		{"a%20b", "a b", false},
+		{"a+b", "a+b", false},
+		{"J%C3%B6rg", "Jörg", false},
+		{"%2b%2B", "++", false},
+		{"-._~!$'()*,;:@/?", "-._~!$'()*,;:@/?", false},
+		{"dG9rZW4==", "dG9rZW4==", false},
+		{"%", "", true},
+		{"%4", "", true},
+		{"%4g", "", true},
+		{"a b", "", true},
+		{"a#b", "", true},
+		{"é", "", true},
+	}
+	for _, tt := range tests {
+		got, err := decodeQueryComponent(tt.in, EncodingPolicy{})
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("decodeQueryComponent(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestEncodingPolicy(t *testing.T) {
+	tests := []struct {
+		policy  EncodingPolicy
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{EncodingPolicy{PlusAsSpace: true}, "a+b%2B", "a b+", false},
+		{EncodingPolicy{PlusAsSpace: true}, "a b", "", true},
+		{EncodingPolicy{PassMalformed: true}, "100%", "100%", false},
+		{EncodingPolicy{PassMalformed: true}, "%zz%41", "%zzA", false},
+		{EncodingPolicy{PassMalformed: true}, "a b#", "a b#", false},
+		{EncodingPolicy{PassMalformed: true}, "a+b", "a+b", false},
+		{EncodingPolicy{PlusAsSpace: true, PassMalformed: true}, "a+%", "a %", false},
+	}
+	for _, tt := range tests {
+		got, err := decodeQueryComponent(tt.in, tt.policy)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("%+v: decodeQueryComponent(%q) = %q, %v, want %q", tt.policy, tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestSetEncodingPolicy(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"q": "in:a_b,50%"}
+	if got := errorCodes(qv.ValidateRawQuery("q=50%", rules)); !slices.Equal(got, []string{"q:INVALID_ENCODING"}) {
+		t.Errorf("strict: got %v, want q:INVALID_ENCODING", got)
+	}
+	qv.SetEncodingPolicy(EncodingPolicy{PassMalformed: true})
+	if got := errorCodes(qv.ValidateRawQuery("q=50%", rules)); len(got) != 0 {
+		t.Errorf("PassMalformed: got %v, want no errors", got)
+	}
+}
@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ValidRelativeURL reports whether v is a same-origin relative URL, as a
+// redirect or next parameter should be: a path starting with a single '/'.
+// Protocol-relative URLs such as //evil.example, backslashes, which browsers
+// read as slashes, and control characters, which they drop, are rejected.
+func ValidRelativeURL(v string) bool {
+	if !strings.HasPrefix(v, "/") || strings.HasPrefix(v, "//") || strings.ContainsRune(v, '\\') {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if v[i] < 0x20 || v[i] == 0x7f {
+			return false
+		}
+	}
+	u, err := url.Parse(v)
+	return err == nil && u.Scheme == "" && u.Host == "" && u.User == nil
+}
+
+// NewRedirectValidator returns a type validator for redirect targets. It
+// accepts relative URLs, as ValidRelativeURL does, and absolute http and
+// https URLs whose host is one of hosts. A host like "*.example.com" allows
+// the subdomains of example.com.
+func NewRedirectValidator(hosts ...string) func(string) bool {
+	allowed := make([]string, len(hosts))
+	for i, host := range hosts {
+		allowed[i] = strings.ToLower(host)
+	}
+
+	return func(v string) bool {
+		if ValidRelativeURL(v) {
+			return true
+		}
+		if strings.ContainsRune(v, '\\') {
+			return false
+		}
+		u, err := url.Parse(v)
+		if err != nil || u.User != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return false
+		}
+		host := strings.ToLower(u.Hostname())
+		for _, pattern := range allowed {
+			if host == pattern || strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AddRedirectHosts registers a type named name accepting relative URLs and
+// absolute URLs to hosts, as NewRedirectValidator does. Rules can also list
+// the hosts inline with allowed_hosts:example.com,*.example.com.
+func (qv *QueryValidator) AddRedirectHosts(name string, hosts ...string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.typeValidators[name] = NewRedirectValidator(hosts...)
+}
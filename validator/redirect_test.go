@@ -0,0 +1,77 @@
+package validator
+
+import "testing"
+
+func TestValidRelativeURL(t *testing.T) {
+	tests := []struct {
+		v    string
+		want bool
+	}{
+		{"/", true},
+		{"/account/settings?tab=2#top", true},
+		{"", false},
+		{"account", false},
+		{"//evil.example", false},
+		{"/\\evil.example", false},
+		{"/\tevil", false},
+		{"/a\x7f", false},
+		{"https://evil.example/", false},
+		{"javascript:alert(1)", false},
+	}
+	for _, tt := range tests {
+		if got := ValidRelativeURL(tt.v); got != tt.want {
+			t.Errorf("ValidRelativeURL(%q) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestNewRedirectValidator(t *testing.T) {
+	valid := NewRedirectValidator("example.com", "*.Example.org")
+	tests := []struct {
+		v    string
+		want bool
+	}{
+		{"/home", true},
+		{"https://example.com/home", true},
+		{"http://EXAMPLE.com:8080/", true},
+		{"https://shop.example.org/cart", true},
+		{"https://a.b.example.org/", true},
+		{"https://example.org/", false},
+		{"https://example.com.evil.example/", false},
+		{"https://evilexample.org/", false},
+		{"https://user@example.com/", false},
+		{"ftp://example.com/", false},
+		{"https:\\\\example.com", false},
+		{"//example.com/", false},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.v); got != tt.want {
+			t.Errorf("redirect validator(%q) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRedirectRules(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddRedirectHosts("redirect", "example.com")
+	tests := []struct {
+		rule  string
+		value string
+		want  []string
+	}{
+		{"relativeurl", "/next", nil},
+		{"relativeurl", "https://example.com/", []string{"next:INVALID_TYPE"}},
+		{"redirect", "https://example.com/", nil},
+		{"redirect", "https://evil.example/", []string{"next:INVALID_TYPE"}},
+		{"string AND allowed_hosts:example.com,*.example.com", "https://a.example.com/", nil},
+		{"string AND allowed_hosts:example.com", "//evil.example", []string{"next:" + MsgForeignRedirect}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{"next": tt.value}, map[string]string{"next": tt.rule}, tt.want...)
+	}
+
+	want := "value must be a relative URL or point to one of example.com"
+	if got := errorMessage(t, qv, "next", "https://evil.example/", "string AND allowed_hosts:example.com"); got != want {
+		t.Errorf("message %q, want %q", got, want)
+	}
+}
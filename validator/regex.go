@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"regexp/syntax"
+)
+
+// RegexOptions bounds the user-supplied patterns accepted by a regex validator.
+type RegexOptions struct {
+	// MaxLength caps the pattern length in bytes. Zero means no limit.
+	MaxLength int
+	// MaxRepeat caps explicit repetition counts such as a{1,50}. Zero means no limit.
+	MaxRepeat int
+	// MaxDepth caps the nesting depth of the parsed expression. Zero means no limit.
+	MaxDepth int
+	// MaxProgramSize caps the number of compiled RE2 instructions. Zero means no limit.
+	MaxProgramSize int
+}
+
+// DefaultRegexOptions is used for the built-in "regex" type.
+var DefaultRegexOptions = RegexOptions{
+	MaxLength:      256,
+	MaxRepeat:      100,
+	MaxDepth:       16,
+	MaxProgramSize: 2000,
+}
+
+// NewRegexValidator returns a type validator accepting values that compile
+// under RE2 and stay within the limits in opts.
+func NewRegexValidator(opts RegexOptions) func(string) bool {
+	return func(v string) bool {
+		if opts.MaxLength > 0 && len(v) > opts.MaxLength {
+			return false
+		}
+
+		re, err := syntax.Parse(v, syntax.Perl)
+		if err != nil {
+			return false
+		}
+		if !regexWithinLimits(re, opts, 1) {
+			return false
+		}
+
+		prog, err := syntax.Compile(re.Simplify())
+		if err != nil {
+			return false
+		}
+		return opts.MaxProgramSize <= 0 || len(prog.Inst) <= opts.MaxProgramSize
+	}
+}
+
+func regexWithinLimits(re *syntax.Regexp, opts RegexOptions, depth int) bool {
+	if opts.MaxDepth > 0 && depth > opts.MaxDepth {
+		return false
+	}
+	if re.Op == syntax.OpRepeat && opts.MaxRepeat > 0 {
+		if re.Min > opts.MaxRepeat || re.Max > opts.MaxRepeat {
+			return false
+		}
+	}
+	for _, sub := range re.Sub {
+		if !regexWithinLimits(sub, opts, depth+1) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexValidator(t *testing.T) {
+	valid := NewRegexValidator(DefaultRegexOptions)
+	tests := []struct {
+		pattern string
+		want    bool
+	}{
+		{`^[a-z]+$`, true},
+		{`(foo|bar)\d{2,4}`, true},
+		{`a{1,100}`, true},
+		{`a{1,101}`, false},
+		{`a{1000}`, false},
+		{`(a`, false},
+		{`(?<name>x)`, true},
+		{`\p{Greek}+`, true},
+		{`a++`, false},
+		{`(a)\1`, false},
+		{strings.Repeat("(", 17) + "a" + strings.Repeat(")", 17), false},
+		{strings.Repeat("(", 10) + "a" + strings.Repeat(")", 10), true},
+		{strings.Repeat("a", 257), false},
+		{`(?:[a-z]{1,100}){1,100}`, false},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.pattern); got != tt.want {
+			t.Errorf("regex %q = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestRegexValidatorNoLimits(t *testing.T) {
+	valid := NewRegexValidator(RegexOptions{})
+	if !valid(`a{1000}`) || !valid(strings.Repeat("a", 1000)) {
+		t.Error("zero options imposed limits")
+	}
+	if valid(`(a`) {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestRegexType(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"filter": "regex"}
+	checkCodes(t, qv, map[string]string{"filter": "^ab+c$"}, rules)
+	checkCodes(t, qv, map[string]string{"filter": "[unclosed"}, rules, "filter:INVALID_TYPE")
+}
@@ -0,0 +1,30 @@
+package validator
+
+import "regexp"
+
+// Regexp is a compiled pattern.
+type Regexp interface {
+	MatchString(s string) bool
+}
+
+// RegexpEngine compiles the patterns of rule definitions: parameter name
+// patterns and regex constraints such as not_regex. The default engine is
+// Go's RE2-based regexp package; an engine such as regexp2 adds lookarounds
+// and backreferences, at the cost of RE2's linear-time guarantee.
+type RegexpEngine interface {
+	Compile(pattern string) (Regexp, error)
+}
+
+type stdRegexpEngine struct{}
+
+func (stdRegexpEngine) Compile(pattern string) (Regexp, error) {
+	return regexp.Compile(pattern)
+}
+
+// SetRegexpEngine replaces the engine patterns are compiled with. Patterns
+// registered earlier keep their compiled form, so set the engine first.
+func (qv *QueryValidator) SetRegexpEngine(engine RegexpEngine) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.regexpEngine = engine
+}
@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"path"
+	"slices"
+	"testing"
+)
+
+// globEngine compiles patterns as path.Match globs, standing in for an
+// alternative regexp engine.
+type globEngine struct{ compiled *[]string }
+
+type globRegexp string
+
+func (g globRegexp) MatchString(s string) bool {
+	ok, _ := path.Match(string(g), s)
+	return ok
+}
+
+func (e globEngine) Compile(pattern string) (Regexp, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	*e.compiled = append(*e.compiled, pattern)
+	return globRegexp(pattern), nil
+}
+
+func TestSetRegexpEngine(t *testing.T) {
+	var compiled []string
+	qv := NewQueryValidator()
+	qv.SetRegexpEngine(globEngine{&compiled})
+
+	tests := []struct {
+		rule  string
+		value string
+		want  []string
+	}{
+		{"regex:img-*.png", "img-1.png", nil},
+		{"regex:img-*.png", "img-1.jpg", []string{"p:PATTERN_MISMATCH"}},
+		{"not_regex:/internal/*", "/internal/a", []string{"p:MUST_NOT_MATCH"}},
+		{"not_regex:/internal/*", "/public/a", nil},
+		{"regex:[", "a", []string{"p:INVALID_RULE"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, map[string]string{"p": tt.value}, map[string]string{"p": tt.rule}, tt.want...)
+	}
+
+	if err := qv.AddParamPattern("globbed", "f_*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := qv.AddParamPattern("broken", "["); err == nil {
+		t.Error("pattern the engine rejects was registered")
+	}
+	for _, pattern := range []string{"img-*.png", "/internal/*", "f_*"} {
+		if !slices.Contains(compiled, pattern) {
+			t.Errorf("pattern %q was not compiled by the engine; compiled %v", pattern, compiled)
+		}
+	}
+}
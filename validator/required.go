@@ -0,0 +1,48 @@
+package validator
+
+import "sort"
+
+// requiredType marks a parameter as required when it is an operand of the
+// rule's top-level AND chain, as in "required AND number". On its own it
+// accepts any value.
+const requiredType = "required"
+
+// isRequired reports whether rule makes its parameter required.
+func isRequired(expr typeExpr) bool {
+	switch e := expr.(type) {
+	case typeRef:
+		return e == requiredType
+	case skipExpr:
+		return isRequired(e.expr)
+	case andExpr:
+		for _, operand := range e {
+			if isRequired(operand) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// missingRequired returns an error for every required parameter absent from
+// values, unless a skip_if condition of its rule holds.
+func (qv *QueryValidator) missingRequired(values map[string]string, rules map[string]string) []QueryValidationError {
+	var missing []string
+	for param, rule := range rules {
+		if _, present := values[param]; present {
+			continue
+		}
+		expr, err := qv.parseTypeExpr(rule)
+		if err != nil || !isRequired(expr) || qv.skipped(rule, values) {
+			continue
+		}
+		missing = append(missing, param)
+	}
+	sort.Strings(missing)
+
+	errors := make([]QueryValidationError, len(missing))
+	for i, param := range missing {
+		errors[i] = newValidationError(param, "", failTerm(requiredType, MsgMissingRequired))
+	}
+	return errors
+}
@@ -0,0 +1,35 @@
+package validator
+
+import "testing"
+
+func TestRequired(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule   string
+		values map[string]string
+		want   []string
+	}{
+		{"required AND number", map[string]string{}, []string{"age:MISSING_REQUIRED"}},
+		{"required|number", map[string]string{}, []string{"age:MISSING_REQUIRED"}},
+		{"number AND required", map[string]string{}, []string{"age:MISSING_REQUIRED"}},
+		{"required AND number", map[string]string{"age": "x"}, []string{"age:INVALID_TYPE"}},
+		{"required AND number", map[string]string{"age": "18"}, nil},
+		{"required", map[string]string{"age": ""}, nil},
+		{"number", map[string]string{}, nil},
+		{"required OR number", map[string]string{}, nil},
+		{"NOT required", map[string]string{}, nil},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, tt.values, map[string]string{"age": tt.rule}, tt.want...)
+	}
+}
+
+func TestMissingRequiredError(t *testing.T) {
+	errs := NewQueryValidator().ValidateMap(nil, map[string]string{"b": "required", "a": "required AND uuid"})
+	if len(errs) != 2 || errs[0].Parameter != "a" || errs[1].Parameter != "b" {
+		t.Fatalf("errors = %+v, want a then b", errs)
+	}
+	if errs[0].Value != "" || errs[0].Message != "missing required parameter" {
+		t.Errorf("error = %+v", errs[0])
+	}
+}
@@ -0,0 +1,24 @@
+package validator
+
+// ReserveNamespace reserves the parameters matching pattern, a name or a
+// prefix ending in '*' such as "x_internal_*", for trusted sources. Other
+// callers sending them get a RESERVED_PARAM error with ErrorStatus 403,
+// whether or not a rule declares them. With the Fiber adapter the source is
+// trusted when the predicate given to SetTrustedSource passes; elsewhere
+// reserved parameters are always rejected.
+func (qv *QueryValidator) ReserveNamespace(pattern string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.reserved = append(qv.reserved, pattern)
+}
+
+// checkReserved returns a forbidden error when param is reserved and the
+// request does not come from a trusted source.
+func (qv *QueryValidator) checkReserved(req validationRequest, param, value string) (QueryValidationError, bool) {
+	if !matchesParam(qv.reserved, param) || (req.trusted != nil && req.trusted()) {
+		return QueryValidationError{}, true
+	}
+	err := newValidationError(param, value, fail(MsgReservedParam))
+	err.status = statusForbidden
+	return err, false
+}
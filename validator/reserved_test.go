@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReserveNamespace(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.ReserveNamespace("x_internal_*")
+	qv.ReserveNamespace("debug")
+	rules := map[string]string{"q": "string", "debug": "boolean", "x_internal_*": "string"}
+	trusted := func() bool { return true }
+	untrusted := func() bool { return false }
+	tests := []struct {
+		name    string
+		values  map[string]string
+		trusted func() bool
+		want    []string
+	}{
+		{"ordinary", map[string]string{"q": "go"}, nil, nil},
+		{"declared reserved", map[string]string{"debug": "true"}, nil, []string{"debug:RESERVED_PARAM"}},
+		{"reserved prefix", map[string]string{"x_internal_trace": "1"}, untrusted, []string{"x_internal_trace:RESERVED_PARAM"}},
+		{"undeclared reserved", map[string]string{"x_internal_other": "1"}, nil, []string{"x_internal_other:RESERVED_PARAM"}},
+		{"trusted", map[string]string{"debug": "true", "x_internal_trace": "1"}, trusted, nil},
+		{"trusted still validated", map[string]string{"debug": "maybe"}, trusted, []string{"debug:INVALID_TYPE"}},
+	}
+	for _, tt := range tests {
+		errors, _ := qv.run(tt.values, rules, validationRequest{trusted: tt.trusted})
+		if got := errorCodes(errors); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: errors %v, want %v", tt.name, got, tt.want)
+		}
+		if len(errors) > 0 && errors[0].Code == MsgReservedParam && ErrorStatus(errors) != 403 {
+			t.Errorf("%s: status %d, want 403", tt.name, ErrorStatus(errors))
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package validator
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValidationResult is the outcome of a validation along with the accepted
+// values, converted once to the Go type their rule implies: int:N and
+// uint:N rules yield integers, float:N and number floats, boolean and flag
+// bools and date times. Values of rejected parameters are left out.
+type ValidationResult struct {
+	Errors []QueryValidationError
+	values map[string]string
+	typed  map[string]any
+}
+
+// ValidateValuesResult is ValidateValues returning a ValidationResult.
+func (qv *QueryValidator) ValidateValuesResult(values url.Values, rules map[string]string) ValidationResult {
+	flat := flattenValues(values, rules)
+	errors, _ := qv.run(flat, rules, validationRequest{})
+	return newValidationResult(flat, rules, errors)
+}
+
+func newValidationResult(values, rules map[string]string, errors []QueryValidationError) ValidationResult {
+	rules = expandWildcardRules(values, rules)
+	failed := make(map[string]bool, len(errors))
+	for _, err := range Rejections(errors) {
+		failed[err.Parameter] = true
+	}
+	result := ValidationResult{
+		Errors: errors,
+		values: make(map[string]string, len(values)),
+		typed:  make(map[string]any, len(values)),
+	}
+	for param, value := range values {
+		rule, declared := rules[param]
+		if !declared || failed[param] {
+			continue
+		}
+		result.values[param] = value
+		if typed, ok := coerce(rule, value); ok {
+			result.typed[param] = typed
+		}
+	}
+	return result
+}
+
+// ruleTerms returns the top-level AND terms of rule, pipes included. It is
+// empty for rules joined by OR.
+func ruleTerms(rule string) []string {
+	var terms []string
+	for _, term := range andTerms(rule) {
+		terms = append(terms, strings.Split(term, "|")...)
+	}
+	return terms
+}
+
+// coerce converts value by the first type term of rule that parses it.
+func coerce(rule, value string) (any, bool) {
+	for _, term := range ruleTerms(rule) {
+		name, arg, _ := strings.Cut(term, ":")
+		bits, _ := strconv.Atoi(arg)
+		if bits == 0 {
+			bits = 64
+		}
+		switch name {
+		case "int", "integer":
+			if n, err := strconv.ParseInt(value, 10, bits); err == nil {
+				return n, true
+			}
+		case "uint":
+			if n, err := strconv.ParseUint(value, 10, bits); err == nil {
+				return n, true
+			}
+		case "float", "number":
+			if f, err := strconv.ParseFloat(value, bits); err == nil {
+				return f, true
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(strings.ToLower(value)); err == nil {
+				return b, true
+			}
+		case flagType:
+			if b, err := parseFlag(value); err == nil {
+				return b, true
+			}
+		case "date":
+			if t, err := time.Parse(time.DateOnly, value); err == nil {
+				return t, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Valid reports whether nothing rejected the request; warnings do not count.
+func (r ValidationResult) Valid() bool {
+	return len(Rejections(r.Errors)) == 0
+}
+
+// String returns the accepted value of param as sent.
+func (r ValidationResult) String(param string) (string, bool) {
+	value, ok := r.values[param]
+	return value, ok
+}
+
+// Int returns the value of param as an integer. Unsigned and float values
+// that are whole numbers within range convert too.
+func (r ValidationResult) Int(param string) (int64, bool) {
+	switch v := r.typed[param].(type) {
+	case int64:
+		return v, true
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+	return 0, false
+}
+
+// Uint returns the value of param as an unsigned integer.
+func (r ValidationResult) Uint(param string) (uint64, bool) {
+	switch v := r.typed[param].(type) {
+	case uint64:
+		return v, true
+	case int64:
+		if v >= 0 {
+			return uint64(v), true
+		}
+	}
+	return 0, false
+}
+
+// Float returns the value of param as a float. Integer values convert too.
+func (r ValidationResult) Float(param string) (float64, bool) {
+	switch v := r.typed[param].(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// Bool returns the value of param as a bool.
+func (r ValidationResult) Bool(param string) (bool, bool) {
+	v, ok := r.typed[param].(bool)
+	return v, ok
+}
+
+// Flag reports whether the flag param was sent and not turned off, so an
+// absent flag is false.
+func (r ValidationResult) Flag(param string) bool {
+	on, _ := r.Bool(param)
+	return on
+}
+
+// Time returns the value of param as a time, for date rules.
+func (r ValidationResult) Time(param string) (time.Time, bool) {
+	v, ok := r.typed[param].(time.Time)
+	return v, ok
+}
+
+// StringSlice splits the value of param on commas. It is nil when param
+// is absent.
+func (r ValidationResult) StringSlice(param string) []string {
+	value, ok := r.values[param]
+	if !ok {
+		return nil
+	}
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+// Nested returns the accepted values with bracketed names expanded, as
+// NestedValues does.
+func (r ValidationResult) Nested() map[string]any {
+	return NestedValues(r.values)
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestValidationResult(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"age":    "int:8 AND min:0",
+		"big":    "uint:64",
+		"ratio":  "float:64",
+		"whole":  "number",
+		"active": "boolean",
+		"debug":  "flag",
+		"from":   "date",
+		"ids":    "array:integer",
+		"q":      "string",
+		"bad":    "integer",
+	}
+	result := qv.ValidateValuesResult(url.Values{
+		"age":    {"42"},
+		"big":    {"18446744073709551615"},
+		"ratio":  {"0.5"},
+		"whole":  {"3"},
+		"active": {"TRUE"},
+		"debug":  {""},
+		"from":   {"2026-10-14"},
+		"ids":    {"1", "2"},
+		"q":      {"go"},
+		"bad":    {"x"},
+	}, rules)
+
+	if result.Valid() || !slices.Equal(errorCodes(result.Errors), []string{"bad:INVALID_TYPE"}) {
+		t.Errorf("errors %v, want bad:INVALID_TYPE", errorCodes(result.Errors))
+	}
+	if v, ok := result.Int("age"); !ok || v != 42 {
+		t.Errorf("Int(age) = %d, %v", v, ok)
+	}
+	if v, ok := result.Uint("big"); !ok || v != 1<<64-1 {
+		t.Errorf("Uint(big) = %d, %v", v, ok)
+	}
+	if _, ok := result.Int("big"); ok {
+		t.Error("Int(big) converted a value out of range")
+	}
+	if v, ok := result.Float("ratio"); !ok || v != 0.5 {
+		t.Errorf("Float(ratio) = %v, %v", v, ok)
+	}
+	if v, ok := result.Int("whole"); !ok || v != 3 {
+		t.Errorf("Int(whole) = %d, %v", v, ok)
+	}
+	if _, ok := result.Int("ratio"); ok {
+		t.Error("Int(ratio) converted a fraction")
+	}
+	if v, ok := result.Float("age"); !ok || v != 42 {
+		t.Errorf("Float(age) = %v, %v", v, ok)
+	}
+	if v, ok := result.Bool("active"); !ok || !v {
+		t.Errorf("Bool(active) = %v, %v", v, ok)
+	}
+	if !result.Flag("debug") || result.Flag("absent") {
+		t.Error("Flag: sent flag off or absent flag on")
+	}
+	if v, ok := result.Time("from"); !ok || !v.Equal(time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time(from) = %v, %v", v, ok)
+	}
+	if got := result.StringSlice("ids"); !slices.Equal(got, []string{"1", "2"}) {
+		t.Errorf("StringSlice(ids) = %q", got)
+	}
+	if got := result.StringSlice("absent"); got != nil {
+		t.Errorf("StringSlice(absent) = %q, want nil", got)
+	}
+	if v, ok := result.String("q"); !ok || v != "go" {
+		t.Errorf("String(q) = %q, %v", v, ok)
+	}
+	if _, ok := result.String("bad"); ok {
+		t.Error("rejected value returned")
+	}
+	if _, ok := result.Int("q"); ok {
+		t.Error("Int(q) converted a string rule")
+	}
+}
+
+func TestValidationResultWarnings(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetSeverityPolicy(SeverityPolicy{MsgTooLarge: SeverityWarning})
+	result := qv.ValidateValuesResult(url.Values{"n": {"11"}}, map[string]string{"n": "integer AND max:10"})
+	if !result.Valid() {
+		t.Errorf("warnings rejected the result: %v", result.Errors)
+	}
+	if v, ok := result.Int("n"); !ok || v != 11 {
+		t.Errorf("Int(n) = %d, %v, want the warned value", v, ok)
+	}
+}
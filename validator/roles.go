@@ -0,0 +1,51 @@
+package validator
+
+import (
+	"maps"
+)
+
+// Principal describes the caller a request is made on behalf of.
+type Principal struct {
+	Role   string
+	Scopes []string
+}
+
+// AddRoleRules overlays rules for callers with role. Entries add or replace
+// the handler's rules; an empty rule removes the parameter, so it becomes
+// unexpected for that role.
+//
+//	qv.AddRoleRules("admin", map[string]string{"include_deleted": "boolean"})
+func (qv *QueryValidator) AddRoleRules(role string, rules map[string]string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	if qv.roleRules[role] == nil {
+		qv.roleRules[role] = make(map[string]string)
+	}
+	maps.Copy(qv.roleRules[role], rules)
+}
+
+// rulesForRole returns rules with the overlay for role applied.
+func (qv *QueryValidator) rulesForRole(role string, rules map[string]string) map[string]string {
+	overlay, ok := qv.roleRules[role]
+	if !ok {
+		return rules
+	}
+	return applyOverlay(rules, overlay)
+}
+
+// applyOverlay returns a copy of rules with the entries of overlay added or
+// replaced, and those with an empty rule removed.
+func applyOverlay(rules, overlay map[string]string) map[string]string {
+	merged := maps.Clone(rules)
+	if merged == nil {
+		merged = make(map[string]string, len(overlay))
+	}
+	for param, rule := range overlay {
+		if rule == "" {
+			delete(merged, param)
+			continue
+		}
+		merged[param] = rule
+	}
+	return merged
+}
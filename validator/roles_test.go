@@ -0,0 +1,69 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestApplyOverlay(t *testing.T) {
+	rules := map[string]string{"q": "string", "limit": "integer AND max:50"}
+	got := applyOverlay(rules, map[string]string{"limit": "integer AND max:500", "include_deleted": "boolean", "q": ""})
+	want := map[string]string{"limit": "integer AND max:500", "include_deleted": "boolean"}
+	if !maps.Equal(got, want) {
+		t.Errorf("overlay = %v, want %v", got, want)
+	}
+	if rules["q"] != "string" || rules["limit"] != "integer AND max:50" {
+		t.Errorf("overlay modified the handler's rules: %v", rules)
+	}
+	if got := applyOverlay(nil, map[string]string{"a": "string"}); got["a"] != "string" {
+		t.Errorf("overlay of nil rules = %v", got)
+	}
+}
+
+func TestRoleRules(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetPrincipalFunc(func(c fiber.Ctx) Principal {
+		role, _ := c.Locals("role").(string)
+		return Principal{Role: role}
+	})
+	qv.AddRoleRules("admin", map[string]string{"include_deleted": "boolean"})
+	qv.AddRoleRules("guest", map[string]string{"limit": "integer AND max:10"})
+	qv.AddRoleRules("guest", map[string]string{"q": ""})
+	rules := map[string]string{"q": "string", "limit": "integer AND max:100"}
+
+	tests := []struct {
+		role  string
+		query string
+		want  []string
+	}{
+		{"admin", "include_deleted=true", nil},
+		{"user", "include_deleted=true", []string{"include_deleted:UNEXPECTED_PARAM"}},
+		{"", "include_deleted=true", []string{"include_deleted:UNEXPECTED_PARAM"}},
+		{"user", "limit=50", nil},
+		{"guest", "limit=50", []string{"limit:TOO_LARGE"}},
+		{"guest", "q=shoes", []string{"q:UNEXPECTED_PARAM"}},
+	}
+	for _, tt := range tests {
+		got := serveQueryAs(t, tt.role, "/?"+tt.query, func(c fiber.Ctx) []QueryValidationError {
+			return qv.ValidateQuery(c, rules)
+		})
+		if codes := errorCodes(got.Errors); !slices.Equal(codes, tt.want) {
+			t.Errorf("role %q, %s: errors %v, want %v", tt.role, tt.query, codes, tt.want)
+		}
+	}
+}
+
+// serveQueryAs is serveQuery behind an auth middleware storing role in
+// Locals.
+func serveQueryAs(t *testing.T, role, target string, validate func(c fiber.Ctx) []QueryValidationError) fiberResponse {
+	t.Helper()
+	return serveQuery(t, "/", target, validate, func(c fiber.Ctx) error {
+		c.Locals("role", role)
+		return c.Next()
+	})
+}
@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Rules maps parameter names to rule expressions. Any map[string]string
+// converts to it, so it can be passed wherever rules are taken.
+type Rules map[string]string
+
+// String renders the rules on one line, sorted by parameter and with rule
+// whitespace collapsed, e.g. "age: number AND min:18; q: string". Equal rule
+// sets render equally, so the output suits logs and drift checks.
+func (r Rules) String() string {
+	params := r.params()
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = param + ": " + collapseSpace(r[param])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// MarshalJSON encodes the rules as an object sorted by parameter, with rule
+// whitespace collapsed.
+func (r Rules) MarshalJSON() ([]byte, error) {
+	normalized := make(map[string]string, len(r))
+	for param, rule := range r {
+		normalized[param] = collapseSpace(rule)
+	}
+	// encoding/json sorts map keys.
+	return json.Marshal(normalized)
+}
+
+func (r Rules) params() []string {
+	params := make([]string, 0, len(r))
+	for param := range r {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	return params
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRulesString(t *testing.T) {
+	tests := []struct {
+		rules Rules
+		want  string
+	}{
+		{nil, ""},
+		{Rules{"q": "string"}, "q: string"},
+		{Rules{"q": " string ", "age": "number\tAND  min:18"}, "age: number AND min:18; q: string"},
+	}
+	for _, tt := range tests {
+		if got := tt.rules.String(); got != tt.want {
+			t.Errorf("%#v.String() = %q, want %q", tt.rules, got, tt.want)
+		}
+	}
+}
+
+func TestRulesMarshalJSON(t *testing.T) {
+	got, err := json.Marshal(Rules{"q": "string", "age": "number   AND min:18"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"age":"number AND min:18","q":"string"}`; string(got) != want {
+		t.Errorf("MarshalJSON = %s, want %s", got, want)
+	}
+}
+
+func TestSchemaDocumentString(t *testing.T) {
+	doc := SchemaDocument{Routes: []RouteSchema{
+		{Route: "/a", Params: []ParamSchema{{Name: "q", Rule: "string"}}},
+		{Route: "/b"},
+	}}
+	if got, want := doc.String(), "/a {q: string}\n/b {}"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
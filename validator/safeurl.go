@@ -0,0 +1,115 @@
+package validator
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// HostResolver resolves hostnames for safe URL validators. *net.Resolver
+// implements it.
+type HostResolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// SafeURLOptions configures a safe URL validator.
+type SafeURLOptions struct {
+	// Schemes lists the allowed schemes. Empty means http and https.
+	Schemes []string
+	// Resolver looks up hostnames. Nil means net.DefaultResolver.
+	Resolver HostResolver
+	// Timeout bounds the lookup of the built-in "safeurl" type. Zero means
+	// two seconds.
+	Timeout time.Duration
+}
+
+// DefaultSafeURLOptions is used for the built-in "safeurl" type.
+var DefaultSafeURLOptions = SafeURLOptions{}
+
+// nonPublicPrefixes are the special-purpose ranges netip has no predicate for.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// Translation prefixes embed an IPv4 address, which must be public too: NAT64
+// at the end of the address and 6to4 right after its prefix.
+var (
+	nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFour   = netip.MustParsePrefix("2002::/16")
+)
+
+// NewSafeURLValidator returns a validator for URLs a server may fetch, such
+// as callback_url or webhook targets. Besides the syntax it requires an
+// allowed scheme and no userinfo, and it resolves the host, rejecting the
+// URL when any of its addresses is loopback, private, link-local,
+// multicast, unspecified or otherwise not publicly routable. The check
+// cannot stop DNS rebinding, so the client fetching the URL should still
+// refuse such addresses when it connects.
+func NewSafeURLValidator(opts SafeURLOptions) ContextValidator {
+	schemes := opts.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return func(ctx context.Context, v string) (bool, error) {
+		u, err := url.Parse(v)
+		if err != nil || u.Host == "" || u.User != nil || !slices.Contains(schemes, strings.ToLower(u.Scheme)) {
+			return false, nil
+		}
+		host := u.Hostname()
+		if addr, err := netip.ParseAddr(host); err == nil {
+			return isPublicAddr(addr), nil
+		}
+		addrs, err := resolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return false, err
+		}
+		for _, addr := range addrs {
+			if !isPublicAddr(addr) {
+				return false, nil
+			}
+		}
+		return len(addrs) > 0, nil
+	}
+}
+
+// isPublicAddr reports whether addr is publicly routable.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if v4, ok := embeddedIPv4(addr); ok {
+		return isPublicAddr(v4)
+	}
+	return true
+}
+
+// embeddedIPv4 returns the IPv4 address a NAT64 or 6to4 address embeds.
+func embeddedIPv4(addr netip.Addr) (netip.Addr, bool) {
+	b := addr.As16()
+	switch {
+	case nat64Prefix.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[12:])), true
+	case sixToFour.Contains(addr):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+// fakeResolver answers lookups from a fixed table and records the context
+// of the last one.
+type fakeResolver struct {
+	hosts map[string][]string
+	ctx   context.Context
+}
+
+func (r *fakeResolver) LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error) {
+	r.ctx = ctx
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	var out []netip.Addr
+	for _, a := range addrs {
+		out = append(out, netip.MustParseAddr(a))
+	}
+	return out, nil
+}
+
+func TestSafeURLValidator(t *testing.T) {
+	resolver := &fakeResolver{hosts: map[string][]string{
+		"public.example":   {"93.184.216.34"},
+		"internal.example": {"93.184.216.34", "10.0.0.5"},
+		"nat64.example":    {"64:ff9b::a00:5"},
+	}}
+	valid := NewSafeURLValidator(SafeURLOptions{Resolver: resolver})
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://public.example/hook", true},
+		{"https://internal.example/hook", false},
+		{"https://nat64.example/hook", false},
+		{"ftp://public.example/hook", false},
+		{"https://user:pw@public.example/hook", false},
+		{"https://93.184.216.34/", true},
+		{"http://127.0.0.1/", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://100.64.0.1/", false},
+		{"http://[::1]/", false},
+		{"http://[::ffff:10.0.0.1]/", false},
+		{"http://[64:ff9b::a9fe:a9fe]/", false},
+		{"http://[64:ff9b::5db8:d822]/", true},
+		{"http://[2002:c0a8:0101::1]/", false},
+		{"http://[2002:5db8:d822::1]/", true},
+		{"http://[2606:4700::1111]/", true},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		got, _ := valid(context.Background(), tt.url)
+		if got != tt.want {
+			t.Errorf("safe URL %s = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	if _, err := valid(context.Background(), "https://unknown.example/"); err == nil {
+		t.Error("failed lookup returned no error")
+	}
+}
+
+func TestSafeURLValidatorUsesContext(t *testing.T) {
+	type key struct{}
+	resolver := &fakeResolver{hosts: map[string][]string{"public.example": {"93.184.216.34"}}}
+	valid := NewSafeURLValidator(SafeURLOptions{Resolver: resolver})
+	ctx := context.WithValue(context.Background(), key{}, "request")
+	valid(ctx, "https://public.example/")
+	if resolver.ctx == nil || resolver.ctx.Value(key{}) != "request" {
+		t.Error("resolver did not get the caller's context")
+	}
+}
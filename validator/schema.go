@@ -0,0 +1,240 @@
+package validator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// WellKnownSchemaPath is where SchemaDocumentHandler is conventionally mounted.
+const WellKnownSchemaPath = "/.well-known/query-schema"
+
+// SchemaDocumentVersion identifies the SchemaDocument format. It only
+// changes when a field is removed or changes meaning; new fields may be
+// added within a version.
+const SchemaDocumentVersion = "1"
+
+// SchemaDocument is the machine-readable contract of every registered route.
+type SchemaDocument struct {
+	Version string        `json:"version"`
+	Routes  []RouteSchema `json:"routes"`
+}
+
+// ParamSchema describes one parameter a route accepts.
+type ParamSchema struct {
+	Name        string `json:"name"`
+	Rule        string `json:"rule"`
+	Description string `json:"description,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// RouteSchema lists the parameters a route accepts.
+type RouteSchema struct {
+	Route  string        `json:"route"`
+	Params []ParamSchema `json:"params"`
+	// NamePattern is the parameter name pattern the route selected, when it
+	// is not the default.
+	NamePattern string `json:"namePattern,omitempty"`
+}
+
+// Rules returns the rules of the schema in the form ValidateQuery takes.
+func (s RouteSchema) Rules() Rules {
+	rules := make(Rules, len(s.Params))
+	for _, p := range s.Params {
+		rules[p.Name] = p.Rule
+	}
+	return rules
+}
+
+// String renders the schema as its route followed by its rules, e.g.
+// "/users {age: number; q: string}".
+func (s RouteSchema) String() string {
+	return s.Route + " {" + s.Rules().String() + "}"
+}
+
+// String renders every route schema on its own line, ordered as in Routes.
+func (d SchemaDocument) String() string {
+	lines := make([]string, len(d.Routes))
+	for i, route := range d.Routes {
+		lines[i] = route.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RegisterRoute records the rules route validates against so clients can
+// discover them through SchemaHandler. A route ending in "/*" covers its
+// whole subtree; see RouteRules.
+func (qv *QueryValidator) RegisterRoute(route string, rules map[string]string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.routes[normalizeRoute(route)] = rules
+}
+
+// Schema returns the compiled rules registered for route.
+func (qv *QueryValidator) Schema(route string) (RouteSchema, bool) {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	return qv.schema(route)
+}
+
+func (qv *QueryValidator) schema(route string) (RouteSchema, bool) {
+	route = normalizeRoute(route)
+	rules, ok := qv.routes[route]
+	if !ok {
+		return RouteSchema{}, false
+	}
+
+	schema := RouteSchema{Route: route, Params: make([]ParamSchema, 0, len(rules))}
+	if pattern := qv.namePattern(route); pattern != PatternDefault {
+		schema.NamePattern = pattern
+	}
+	for name, rule := range rules {
+		// Show the parsed form so equivalent spellings read the same.
+		if expr, err := qv.parseTypeExpr(rule); err == nil {
+			rule = expr.String()
+		}
+		schema.Params = append(schema.Params, ParamSchema{
+			Name:        name,
+			Rule:        rule,
+			Description: qv.descriptions[name],
+			Scope:       qv.paramScopes[name],
+		})
+	}
+	sort.Slice(schema.Params, func(i, j int) bool {
+		return schema.Params[i].Name < schema.Params[j].Name
+	})
+	return schema, true
+}
+
+// Schemas returns the schemas of all registered routes, ordered by route.
+func (qv *QueryValidator) Schemas() SchemaDocument {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	routes := make([]string, 0, len(qv.routes))
+	for route := range qv.routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	doc := SchemaDocument{Version: SchemaDocumentVersion, Routes: make([]RouteSchema, 0, len(routes))}
+	for _, route := range routes {
+		schema, _ := qv.schema(route)
+		doc.Routes = append(doc.Routes, schema)
+	}
+	return doc
+}
+
+// RouteRules returns the rules that apply to route: those of every
+// registered wildcard whose prefix covers it, from the broadest to the most
+// specific, overlaid with those registered for route itself. Later, more
+// specific entries replace a parameter's rule.
+func (qv *QueryValidator) RouteRules(route string) (map[string]string, bool) {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	return qv.routeRules(route)
+}
+
+func (qv *QueryValidator) routeRules(route string) (map[string]string, bool) {
+	matches := coveringRoutes(qv.routes, route)
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	rules := make(map[string]string)
+	for _, pattern := range matches {
+		maps.Copy(rules, qv.routes[pattern])
+	}
+	return rules, true
+}
+
+// RuleConflictPolicy decides which rules Middleware applies when it was
+// given rules for a route that also has registered ones.
+type RuleConflictPolicy int
+
+const (
+	// HandlerRulesWin applies the handler's rules and ignores the registry.
+	// It is the default.
+	HandlerRulesWin RuleConflictPolicy = iota
+	// RegistryRulesWin applies the registered rules and ignores the
+	// handler's.
+	RegistryRulesWin
+	// MergeRules applies both, the handler's replacing the registered rule
+	// of a parameter both declare.
+	MergeRules
+	// RejectRuleConflicts applies both like MergeRules, but fails the
+	// request with an error when they declare a parameter with different
+	// rules, so drift between the two surfaces instead of being resolved
+	// silently.
+	RejectRuleConflicts
+)
+
+// SetRuleConflictPolicy sets how Middleware reconciles its rules with those
+// registered for the route.
+func (qv *QueryValidator) SetRuleConflictPolicy(policy RuleConflictPolicy) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.ruleConflicts = policy
+}
+
+// resolveRouteRules returns the rules for route given those of its handler,
+// which may be nil. ok is false when neither has any.
+func (qv *QueryValidator) resolveRouteRules(route string, handler map[string]string) (rules map[string]string, ok bool, err error) {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	if handler != nil && qv.ruleConflicts == HandlerRulesWin {
+		return handler, true, nil
+	}
+	registered, found := qv.routeRules(route)
+	switch {
+	case !found:
+		return handler, handler != nil, nil
+	case handler == nil || qv.ruleConflicts == RegistryRulesWin:
+		return registered, true, nil
+	}
+	for param, rule := range handler {
+		if prev, declared := registered[param]; declared && prev != rule && qv.ruleConflicts == RejectRuleConflicts {
+			return nil, false, fmt.Errorf("rule %q for %s on %s conflicts with registered rule %q", rule, param, route, prev)
+		}
+		registered[param] = rule
+	}
+	return registered, true, nil
+}
+
+// coveringRoutes returns the keys of registered that apply to route: the
+// wildcards covering it from the broadest to the most specific, then route
+// itself.
+func coveringRoutes[V any](registered map[string]V, route string) []string {
+	route = normalizeRoute(route)
+	segments := routeSegments(route)
+
+	var matches []string
+	for pattern := range registered {
+		prefix, wildcard := strings.CutSuffix(pattern, "/*")
+		if !wildcard {
+			continue
+		}
+		prefixSegments := routeSegments(prefix)
+		if len(prefixSegments) <= len(segments) && slices.Equal(prefixSegments, segments[:len(prefixSegments)]) {
+			matches = append(matches, pattern)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return len(matches[i]) < len(matches[j]) })
+	if _, exact := registered[route]; exact && !slices.Contains(matches, route) {
+		matches = append(matches, route)
+	}
+	return matches
+}
+
+func routeSegments(route string) []string {
+	route = strings.Trim(route, "/")
+	if route == "" {
+		return nil
+	}
+	return strings.Split(route, "/")
+}
+
+func normalizeRoute(route string) string {
+	return "/" + strings.Trim(route, "/")
+}
@@ -0,0 +1,197 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// getJSON requests target from app and decodes its JSON body into v.
+func getJSON(t *testing.T, app *fiber.App, target string, v any) int {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("%s: %v", target, err)
+	}
+	return resp.StatusCode
+}
+
+func TestSchema(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RequireScope("include_deleted", "records:admin")
+	qv.RegisterRoute("users/", map[string]string{
+		"age":             "number|min:18",
+		"include_deleted": "boolean",
+		"q":               "string",
+	})
+	want := RouteSchema{Route: "/users", Params: []ParamSchema{
+		{Name: "age", Rule: "number AND min:18"},
+		{Name: "include_deleted", Rule: "boolean", Scope: "records:admin"},
+		{Name: "q", Rule: "string"},
+	}}
+	for _, route := range []string{"/users", "users", "/users/"} {
+		got, ok := qv.Schema(route)
+		if !ok || !reflect.DeepEqual(got, want) {
+			t.Errorf("Schema(%q) = %+v, %v, want %+v", route, got, ok, want)
+		}
+	}
+	if _, ok := qv.Schema("/orders"); ok {
+		t.Error("schema found for an unregistered route")
+	}
+	if got := want.String(); got != "/users {age: number AND min:18; include_deleted: boolean; q: string}" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestSchemaHandler(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RegisterRoute("/users", map[string]string{"q": "string"})
+	qv.RegisterRoute("/users/:id/orders", map[string]string{"limit": "integer"})
+	app := fiber.New()
+	app.Get("/_schema/*", qv.SchemaHandler())
+
+	tests := []struct {
+		target string
+		status int
+		route  string
+	}{
+		{"/_schema/users", 200, "/users"},
+		{"/_schema/users/:id/orders", 200, "/users/:id/orders"},
+		{"/_schema/orders", 404, ""},
+	}
+	for _, tt := range tests {
+		var schema RouteSchema
+		if status := getJSON(t, app, tt.target, &schema); status != tt.status || schema.Route != tt.route {
+			t.Errorf("%s: status %d, route %q, want %d, %q", tt.target, status, schema.Route, tt.status, tt.route)
+		}
+	}
+}
+
+func TestSchemaDocumentHandler(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RegisterRoute("/users", map[string]string{"q": "string"})
+	qv.RegisterRoute("/orders", map[string]string{"limit": "integer"})
+	app := fiber.New()
+	app.Get(WellKnownSchemaPath, qv.SchemaDocumentHandler())
+
+	var doc SchemaDocument
+	if status := getJSON(t, app, WellKnownSchemaPath, &doc); status != 200 {
+		t.Fatalf("status %d", status)
+	}
+	want := SchemaDocument{Version: SchemaDocumentVersion, Routes: []RouteSchema{
+		{Route: "/orders", Params: []ParamSchema{{Name: "limit", Rule: "integer"}}},
+		{Route: "/users", Params: []ParamSchema{{Name: "q", Rule: "string"}}},
+	}}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("document %+v, want %+v", doc, want)
+	}
+	if !reflect.DeepEqual(qv.Schemas(), want) {
+		t.Errorf("Schemas() = %+v, want %+v", qv.Schemas(), want)
+	}
+	if got := doc.String(); got != "/orders {limit: integer}\n/users {q: string}" {
+		t.Errorf("String() = %q", got)
+	}
+
+	empty := NewQueryValidator().Schemas()
+	if body, _ := json.Marshal(empty); string(body) != `{"version":"1","routes":[]}` {
+		t.Errorf("empty document %s", body)
+	}
+}
+
+func TestRouteRules(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RegisterRoute("/api/*", map[string]string{"trace": "boolean", "limit": "integer"})
+	qv.RegisterRoute("/api/v1/users/*", map[string]string{"limit": "integer AND max:50", "q": "string"})
+	qv.RegisterRoute("/api/v1/users/:id", map[string]string{"q": "uuid"})
+	qv.RegisterRoute("/api/v1/usersx", map[string]string{"other": "string"})
+
+	tests := []struct {
+		route string
+		want  map[string]string
+	}{
+		{"/api", map[string]string{"trace": "boolean", "limit": "integer"}},
+		{"/api/v2/orders", map[string]string{"trace": "boolean", "limit": "integer"}},
+		{"/api/v1/users", map[string]string{"trace": "boolean", "limit": "integer AND max:50", "q": "string"}},
+		{"api/v1/users/42/", map[string]string{"trace": "boolean", "limit": "integer AND max:50", "q": "string"}},
+		{"/api/v1/users/:id", map[string]string{"trace": "boolean", "limit": "integer AND max:50", "q": "uuid"}},
+		{"/api/v1/usersx", map[string]string{"trace": "boolean", "limit": "integer", "other": "string"}},
+		{"/apix", nil},
+		{"/", nil},
+	}
+	for _, tt := range tests {
+		got, ok := qv.RouteRules(tt.route)
+		if ok != (tt.want != nil) || !maps.Equal(got, tt.want) {
+			t.Errorf("RouteRules(%q) = %v, %v, want %v", tt.route, got, ok, tt.want)
+		}
+	}
+}
+
+func TestRuleConflictPolicy(t *testing.T) {
+	registered := map[string]string{"limit": "integer", "sort": "in:asc,desc"}
+	handler := map[string]string{"limit": "integer AND max:50", "q": "string"}
+	merged := map[string]string{"limit": "integer AND max:50", "sort": "in:asc,desc", "q": "string"}
+	tests := []struct {
+		name    string
+		policy  RuleConflictPolicy
+		route   string
+		handler map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"handler wins", HandlerRulesWin, "/users", handler, handler, false},
+		{"registry wins", RegistryRulesWin, "/users", handler, registered, false},
+		{"merge", MergeRules, "/users", handler, merged, false},
+		{"conflict rejected", RejectRuleConflicts, "/users", handler, nil, true},
+		{"agreeing rules", RejectRuleConflicts, "/users", map[string]string{"limit": "integer", "q": "string"},
+			map[string]string{"limit": "integer", "sort": "in:asc,desc", "q": "string"}, false},
+		{"registry only", RejectRuleConflicts, "/users", nil, registered, false},
+		{"handler only", MergeRules, "/orders", handler, handler, false},
+		{"neither", MergeRules, "/orders", nil, nil, false},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		qv.RegisterRoute("/users", registered)
+		qv.SetRuleConflictPolicy(tt.policy)
+		got, ok, err := qv.resolveRouteRules(tt.route, tt.handler)
+		if (err != nil) != tt.wantErr || ok != (tt.want != nil) || !maps.Equal(got, tt.want) {
+			t.Errorf("%s: rules %v, %v, %v, want %v", tt.name, got, ok, err, tt.want)
+		}
+	}
+	// Merging must not change what is registered.
+	qv := NewQueryValidator()
+	qv.RegisterRoute("/users", registered)
+	qv.SetRuleConflictPolicy(MergeRules)
+	qv.resolveRouteRules("/users", handler)
+	if got, _ := qv.RouteRules("/users"); !maps.Equal(got, registered) {
+		t.Errorf("registered rules changed to %v", got)
+	}
+}
+
+func TestMiddlewareRuleConflicts(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RegisterRoute("/users", map[string]string{"limit": "integer"})
+	qv.SetRuleConflictPolicy(RejectRuleConflicts)
+	tests := []struct {
+		rules  map[string]string
+		status int
+	}{
+		{map[string]string{"limit": "integer"}, 200},
+		{map[string]string{"limit": "integer AND max:5"}, 500},
+	}
+	for _, tt := range tests {
+		got := serveQuery(t, "/users", "/users?limit=10", nil, qv.Middleware(tt.rules))
+		if got.Status != tt.status {
+			t.Errorf("rules %v: status %d, want %d", tt.rules, got.Status, tt.status)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package validator
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ChangeKind classifies a SchemaChange.
+type ChangeKind string
+
+const (
+	RouteAdded    ChangeKind = "route added"
+	RouteRemoved  ChangeKind = "route removed"
+	ParamAdded    ChangeKind = "param added"
+	ParamRemoved  ChangeKind = "param removed"
+	RuleTightened ChangeKind = "rule tightened"
+	RuleLoosened  ChangeKind = "rule loosened"
+	RuleChanged   ChangeKind = "rule changed"
+)
+
+// SchemaChange is one entry of a SchemaDiff. Rule changes list the terms of
+// the top-level AND chain that were added and removed; a scope counts as a
+// "scope:name" term.
+type SchemaChange struct {
+	Kind    ChangeKind `json:"kind"`
+	Route   string     `json:"route"`
+	Param   string     `json:"param,omitempty"`
+	Old     string     `json:"old,omitempty"`
+	New     string     `json:"new,omitempty"`
+	Added   []string   `json:"added,omitempty"`
+	Removed []string   `json:"removed,omitempty"`
+	// Breaking is set when requests valid before may now be rejected; see
+	// isBreaking.
+	Breaking bool `json:"breaking"`
+}
+
+// SchemaDiff is the changelog between two schema documents, ordered by
+// route and parameter.
+type SchemaDiff struct {
+	Changes []SchemaChange `json:"changes"`
+}
+
+// Diff returns the changes that turn d into other.
+func (d SchemaDocument) Diff(other SchemaDocument) SchemaDiff {
+	diff := d.diff(other)
+	for i := range diff.Changes {
+		diff.Changes[i].Breaking = diff.Changes[i].isBreaking()
+	}
+	return diff
+}
+
+func (d SchemaDocument) diff(other SchemaDocument) SchemaDiff {
+	old := make(map[string]RouteSchema, len(d.Routes))
+	for _, route := range d.Routes {
+		old[route.Route] = route
+	}
+	updated := make(map[string]RouteSchema, len(other.Routes))
+	for _, route := range other.Routes {
+		updated[route.Route] = route
+	}
+
+	diff := SchemaDiff{Changes: []SchemaChange{}}
+	for _, route := range sortedKeys(old, updated) {
+		before, hadRoute := old[route]
+		after, hasRoute := updated[route]
+		switch {
+		case !hadRoute:
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: RouteAdded, Route: route, New: after.Rules().String()})
+		case !hasRoute:
+			diff.Changes = append(diff.Changes, SchemaChange{Kind: RouteRemoved, Route: route, Old: before.Rules().String()})
+		default:
+			diff.Changes = append(diff.Changes, before.Diff(after)...)
+		}
+	}
+	return diff
+}
+
+// Diff returns the parameter changes that turn s into other.
+func (s RouteSchema) Diff(other RouteSchema) []SchemaChange {
+	old, updated := s.paramsByName(), other.paramsByName()
+	var changes []SchemaChange
+	for _, name := range sortedKeys(old, updated) {
+		before, hadParam := old[name]
+		after, hasParam := updated[name]
+		change := SchemaChange{Route: s.Route, Param: name, Old: before.Rule, New: after.Rule}
+		switch {
+		case !hadParam:
+			change.Kind = ParamAdded
+		case !hasParam:
+			change.Kind = ParamRemoved
+		default:
+			oldTerms, newTerms := before.terms(), after.terms()
+			change.Added = termsMissing(newTerms, oldTerms)
+			change.Removed = termsMissing(oldTerms, newTerms)
+			switch {
+			case len(change.Added) == 0 && len(change.Removed) == 0:
+				continue
+			case len(change.Removed) == 0:
+				change.Kind = RuleTightened
+			case len(change.Added) == 0:
+				change.Kind = RuleLoosened
+			default:
+				change.Kind = RuleChanged
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Breaking reports whether any change may reject requests that were valid
+// before, for release checks.
+func (d SchemaDiff) Breaking() bool {
+	return len(d.BreakingChanges()) > 0
+}
+
+// BreakingChanges returns the breaking changes.
+func (d SchemaDiff) BreakingChanges() []SchemaChange {
+	var breaking []SchemaChange
+	for _, c := range d.Changes {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// isBreaking classifies the change for existing clients. Removals break
+// clients still sending the route or parameter, new terms may reject values
+// that passed, and a new parameter only breaks them when it is required.
+// Changes that both add and remove terms are assumed to break.
+func (c SchemaChange) isBreaking() bool {
+	switch c.Kind {
+	case RouteRemoved, ParamRemoved, RuleTightened, RuleChanged:
+		return true
+	case ParamAdded:
+		return slices.Contains(andTerms(c.New), requiredType)
+	}
+	return false
+}
+
+// String renders the changelog one change per line.
+func (d SchemaDiff) String() string {
+	lines := make([]string, len(d.Changes))
+	for i, c := range d.Changes {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c SchemaChange) String() string {
+	subject := c.Route
+	if c.Param != "" {
+		subject += " " + c.Param
+	}
+	line := c.Kind.String() + ": " + subject
+	if c.Breaking {
+		line = "BREAKING " + line
+	}
+	switch c.Kind {
+	case RouteAdded, ParamAdded:
+		return withDetail(line, c.New)
+	case RouteRemoved, ParamRemoved:
+		return withDetail(line, c.Old)
+	}
+	if len(c.Added) > 0 {
+		line += " +[" + strings.Join(c.Added, ", ") + "]"
+	}
+	if len(c.Removed) > 0 {
+		line += " -[" + strings.Join(c.Removed, ", ") + "]"
+	}
+	return line
+}
+
+func withDetail(line, detail string) string {
+	if detail == "" {
+		return line
+	}
+	return line + " (" + detail + ")"
+}
+
+func (k ChangeKind) String() string { return string(k) }
+
+func (s RouteSchema) paramsByName() map[string]ParamSchema {
+	params := make(map[string]ParamSchema, len(s.Params))
+	for _, p := range s.Params {
+		params[p.Name] = p
+	}
+	return params
+}
+
+func (p ParamSchema) terms() []string {
+	terms := andTerms(p.Rule)
+	if p.Scope != "" {
+		terms = append(terms, "scope:"+p.Scope)
+	}
+	return terms
+}
+
+// andTerms splits rule at the AND operators outside parentheses. A rule
+// with a top-level OR is a single term.
+func andTerms(rule string) []string {
+	var terms, current []string
+	depth := 0
+	for _, tok := range tokenizeTypeExpr(rule) {
+		switch tok {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		case "OR":
+			if depth == 0 {
+				return []string{collapseSpace(rule)}
+			}
+		case "AND":
+			if depth == 0 {
+				terms = append(terms, joinTokens(current))
+				current = nil
+				continue
+			}
+		}
+		current = append(current, tok)
+	}
+	if len(current) > 0 {
+		terms = append(terms, joinTokens(current))
+	}
+	return terms
+}
+
+var groupSpacing = strings.NewReplacer("( ", "(", " )", ")")
+
+func joinTokens(tokens []string) string {
+	return groupSpacing.Replace(strings.Join(tokens, " "))
+}
+
+// termsMissing returns the terms of a that b lacks.
+func termsMissing(a, b []string) []string {
+	var missing []string
+	for _, term := range a {
+		if !slices.Contains(b, term) {
+			missing = append(missing, term)
+		}
+	}
+	return missing
+}
+
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, dup := a[k]; !dup {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
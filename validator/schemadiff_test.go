@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSchemaDocumentDiff(t *testing.T) {
+	old := SchemaDocument{Routes: []RouteSchema{
+		{Route: "/gone", Params: []ParamSchema{{Name: "q", Rule: "string"}}},
+		{Route: "/users", Params: []ParamSchema{
+			{Name: "age", Rule: "number AND min:18"},
+			{Name: "limit", Rule: "integer"},
+			{Name: "q", Rule: "string"},
+			{Name: "sort", Rule: "in:asc,desc AND string"},
+			{Name: "state", Rule: "string AND minlen:2"},
+			{Name: "trash", Rule: "boolean"},
+			{Name: "tags", Rule: "(uuid OR email)"},
+		}},
+	}}
+	updated := SchemaDocument{Routes: []RouteSchema{
+		{Route: "/users", Params: []ParamSchema{
+			{Name: "age", Rule: "number"},
+			{Name: "limit", Rule: "integer AND max:100"},
+			{Name: "page", Rule: "integer"},
+			{Name: "q", Rule: "string", Scope: "search"},
+			{Name: "sort", Rule: "string   AND in:asc,desc"},
+			{Name: "state", Rule: "string AND minlen:3"},
+			{Name: "tags", Rule: "(uuid OR email)"},
+		}},
+		{Route: "/orders", Params: []ParamSchema{{Name: "id", Rule: "uuid"}}},
+	}}
+	diff := old.Diff(updated)
+	want := []string{
+		"route removed: /gone (q: string)",
+		"route added: /orders (id: uuid)",
+		"rule loosened: /users age -[min:18]",
+		"rule tightened: /users limit +[max:100]",
+		"param added: /users page (integer)",
+		"rule tightened: /users q +[scope:search]",
+		"rule changed: /users state +[minlen:3] -[minlen:2]",
+		"param removed: /users trash (boolean)",
+	}
+	var got []string
+	for _, c := range diff.Changes {
+		c.Breaking = false
+		got = append(got, c.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("changes:\n%q\nwant:\n%q", got, want)
+	}
+
+	if same := old.Diff(old); len(same.Changes) != 0 || same.String() != "" {
+		t.Errorf("diff with itself = %+v", same)
+	}
+}
+
+func TestAndTerms(t *testing.T) {
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{"string", []string{"string"}},
+		{"required AND number AND min:1", []string{"required", "number", "min:1"}},
+		{"(uuid OR email) AND maxlen:10", []string{"(uuid OR email)", "maxlen:10"}},
+		{"uuid OR email  AND maxlen:10", []string{"uuid OR email AND maxlen:10"}},
+	}
+	for _, tt := range tests {
+		if got := andTerms(tt.rule); !slices.Equal(got, tt.want) {
+			t.Errorf("andTerms(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestSchemaDiffBreaking(t *testing.T) {
+	route := func(params ...ParamSchema) SchemaDocument {
+		return SchemaDocument{Routes: []RouteSchema{{Route: "/users", Params: params}}}
+	}
+	tests := []struct {
+		name     string
+		old, new SchemaDocument
+		breaking bool
+	}{
+		{"optional param added", route(), route(ParamSchema{Name: "q", Rule: "string"}), false},
+		{"required param added", route(), route(ParamSchema{Name: "q", Rule: "required AND string"}), true},
+		{"param removed", route(ParamSchema{Name: "q", Rule: "string"}), route(), true},
+		{"rule loosened", route(ParamSchema{Name: "n", Rule: "integer AND max:10"}), route(ParamSchema{Name: "n", Rule: "integer"}), false},
+		{"rule tightened", route(ParamSchema{Name: "n", Rule: "integer"}), route(ParamSchema{Name: "n", Rule: "integer AND max:10"}), true},
+		{"scope added", route(ParamSchema{Name: "n", Rule: "integer"}), route(ParamSchema{Name: "n", Rule: "integer", Scope: "admin"}), true},
+		{"route added", SchemaDocument{}, route(ParamSchema{Name: "q", Rule: "required"}), false},
+		{"route removed", route(), SchemaDocument{}, true},
+		{"unchanged", route(ParamSchema{Name: "q", Rule: "string"}), route(ParamSchema{Name: "q", Rule: "string"}), false},
+	}
+	for _, tt := range tests {
+		diff := tt.old.Diff(tt.new)
+		if diff.Breaking() != tt.breaking {
+			t.Errorf("%s: Breaking() = %v, changes %v", tt.name, diff.Breaking(), diff)
+		}
+		if got := len(diff.BreakingChanges()) > 0; got != tt.breaking {
+			t.Errorf("%s: BreakingChanges() = %v", tt.name, diff.BreakingChanges())
+		}
+		if tt.breaking && !strings.HasPrefix(diff.String(), "BREAKING ") {
+			t.Errorf("%s: String() = %q, want it marked", tt.name, diff.String())
+		}
+	}
+}
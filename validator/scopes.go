@@ -0,0 +1,39 @@
+package validator
+
+// RequireScope makes param acceptable only to callers holding scope. Using
+// it without the scope yields an error with ErrorStatus 403.
+func (qv *QueryValidator) RequireScope(param, scope string) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.paramScopes[param] = scope
+}
+
+// HTTP statuses of validation failures, spelled out so core builds need not
+// import net/http.
+const (
+	statusBadRequest = 400
+	statusForbidden  = 403
+)
+
+// ErrorStatus returns the HTTP status a response carrying errors should use:
+// 403 when a parameter needed a scope the caller lacks, 400 otherwise.
+func ErrorStatus(errors []QueryValidationError) int {
+	for _, err := range errors {
+		if err.status == statusForbidden {
+			return statusForbidden
+		}
+	}
+	return statusBadRequest
+}
+
+// checkScope returns a forbidden error when param is scope-gated and the
+// caller does not hold the scope.
+func (qv *QueryValidator) checkScope(req validationRequest, param, value string) (QueryValidationError, bool) {
+	scope, gated := qv.paramScopes[param]
+	if !gated || (req.hasScope != nil && req.hasScope(scope)) {
+		return QueryValidationError{}, true
+	}
+	err := newValidationError(param, value, fail(MsgForbiddenParam, scope))
+	err.status = statusForbidden
+	return err, false
+}
@@ -0,0 +1,71 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestRequireScope(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.RequireScope("include_deleted", "records:admin")
+	rules := map[string]string{"q": "string", "include_deleted": "boolean"}
+	tests := []struct {
+		scopes []string
+		values map[string]string
+		want   []string
+		status int
+	}{
+		{[]string{"records:admin"}, map[string]string{"include_deleted": "true"}, nil, 0},
+		{[]string{"records:read"}, map[string]string{"include_deleted": "true"}, []string{"include_deleted:FORBIDDEN_PARAM"}, 403},
+		{nil, map[string]string{"q": "a"}, nil, 0},
+		{nil, map[string]string{"q": "a", "include_deleted": "true"}, []string{"include_deleted:FORBIDDEN_PARAM"}, 403},
+		{nil, map[string]string{"nope": "1"}, []string{"nope:UNEXPECTED_PARAM"}, 400},
+	}
+	for _, tt := range tests {
+		req := validationRequest{hasScope: func(scope string) bool { return slices.Contains(tt.scopes, scope) }}
+		errs, _ := qv.run(tt.values, rules, req)
+		if got := errorCodes(errs); !slices.Equal(got, tt.want) {
+			t.Errorf("scopes %v, %v: errors %v, want %v", tt.scopes, tt.values, got, tt.want)
+		}
+		if tt.status != 0 && ErrorStatus(errs) != tt.status {
+			t.Errorf("scopes %v, %v: status %d, want %d", tt.scopes, tt.values, ErrorStatus(errs), tt.status)
+		}
+	}
+
+	checkCodes(t, qv, map[string]string{"include_deleted": "true"}, rules, "include_deleted:FORBIDDEN_PARAM")
+	if msg := errorMessage(t, qv, "include_deleted", "true", "boolean"); msg != "parameter requires scope records:admin" {
+		t.Errorf("message %q", msg)
+	}
+}
+
+func TestRequireScopeFiber(t *testing.T) {
+	rules := map[string]string{"include_deleted": "boolean"}
+	tests := []struct {
+		name   string
+		setup  func(qv *QueryValidator)
+		scopes []string
+		status int
+	}{
+		{"principal scope", func(qv *QueryValidator) {}, []string{"records:admin"}, 200},
+		{"principal lacks scope", func(qv *QueryValidator) {}, []string{"records:read"}, 403},
+		{"scope checker overrides principal", func(qv *QueryValidator) {
+			qv.SetScopeChecker(func(c fiber.Ctx, scope string) bool { return scope == "records:admin" })
+		}, nil, 200},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		qv.RequireScope("include_deleted", "records:admin")
+		qv.SetPrincipalFunc(func(fiber.Ctx) Principal { return Principal{Scopes: tt.scopes} })
+		tt.setup(qv)
+		got := serveQuery(t, "/", "/?include_deleted=true", func(c fiber.Ctx) []QueryValidationError {
+			return qv.ValidateQuery(c, rules)
+		})
+		if got.Status != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, got.Status, tt.status)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package validator
+
+// Severity says whether an error rejects the request. The empty severity is
+// SeverityError.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// SeverityPolicy maps error codes (the Msg constants) to severities. Codes
+// it does not list reject. It decodes from JSON, so environments can ship
+// their own, e.g. {"UNEXPECTED_PARAM": "warning"} in production and an empty
+// policy in staging.
+type SeverityPolicy map[string]Severity
+
+// SetSeverityPolicy sets which error codes reject requests and which are
+// downgraded to warnings. Warnings are still returned, with Severity set; use
+// Rejections to decide whether to reject.
+func (qv *QueryValidator) SetSeverityPolicy(policy SeverityPolicy) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.severities = policy
+}
+
+// Rejections returns the errors that should reject the request, leaving out
+// warnings.
+func Rejections(errors []QueryValidationError) []QueryValidationError {
+	var rejections []QueryValidationError
+	for _, err := range errors {
+		if err.Severity != SeverityWarning {
+			rejections = append(rejections, err)
+		}
+	}
+	return rejections
+}
+
+// Warnings returns the errors the severity policy downgraded.
+func Warnings(errors []QueryValidationError) []QueryValidationError {
+	var warnings []QueryValidationError
+	for _, err := range errors {
+		if err.Severity == SeverityWarning {
+			warnings = append(warnings, err)
+		}
+	}
+	return warnings
+}
+
+func (qv *QueryValidator) applySeverities(errors []QueryValidationError) {
+	if len(qv.severities) == 0 {
+		return
+	}
+	for i := range errors {
+		if severity := qv.severities[errors[i].reason.key]; severity == SeverityWarning {
+			errors[i].Severity = SeverityWarning
+		}
+	}
+}
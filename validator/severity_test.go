@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestSeverityPolicy(t *testing.T) {
+	var policy SeverityPolicy
+	if err := json.Unmarshal([]byte(`{"UNEXPECTED_PARAM": "warning", "TOO_LARGE": "error"}`), &policy); err != nil {
+		t.Fatal(err)
+	}
+	qv := NewQueryValidator()
+	qv.SetSeverityPolicy(policy)
+	rules := map[string]string{"n": "integer AND max:10"}
+	tests := []struct {
+		values     map[string]string
+		rejections []string
+		warnings   []string
+	}{
+		{map[string]string{"n": "1"}, nil, nil},
+		{map[string]string{"n": "1", "x": "2"}, nil, []string{"x:UNEXPECTED_PARAM"}},
+		{map[string]string{"n": "11", "x": "2"}, []string{"n:TOO_LARGE"}, []string{"x:UNEXPECTED_PARAM"}},
+		{map[string]string{"n": "y"}, []string{"n:INVALID_TYPE"}, nil},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(tt.values, rules)
+		if got := errorCodes(Rejections(errs)); !slices.Equal(got, tt.rejections) {
+			t.Errorf("%v: rejections %v, want %v", tt.values, got, tt.rejections)
+		}
+		if got := errorCodes(Warnings(errs)); !slices.Equal(got, tt.warnings) {
+			t.Errorf("%v: warnings %v, want %v", tt.values, got, tt.warnings)
+		}
+		for _, err := range errs {
+			if (err.Severity == SeverityWarning) != (err.Code == MsgUnexpected) {
+				t.Errorf("%s: severity %q", err.Code, err.Severity)
+			}
+		}
+	}
+
+	qv.SetSeverityPolicy(nil)
+	if got := errorCodes(Rejections(qv.ValidateMap(map[string]string{"x": "2"}, rules))); !slices.Equal(got, []string{"x:UNEXPECTED_PARAM"}) {
+		t.Errorf("without a policy: rejections %v, want x:UNEXPECTED_PARAM", got)
+	}
+}
+
+func TestSeverityJSON(t *testing.T) {
+	got, err := json.Marshal([]QueryValidationError{
+		{Parameter: "x", Code: MsgUnexpected, Severity: SeverityWarning},
+		{Parameter: "n", Code: MsgTooLarge},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]any
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded[0]["severity"] != "warning" {
+		t.Errorf("warning encoded as %s", got)
+	}
+	if _, ok := decoded[1]["severity"]; ok {
+		t.Errorf("error severity not omitted: %s", got)
+	}
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"context"
+	"strings"
+)
+
+// Rules may start with skip_if terms naming sibling conditions under which
+// the rest of the rule is not evaluated at all, e.g.
+// "skip_if:!near AND number AND max:50" leaves radius unchecked when near
+// is absent. A condition is "name" (present), "!name" (absent) or
+// "name=value" (equal); any holding condition skips.
+const skipPrefix = "skip_if:"
+
+type skipCondition struct {
+	param  string
+	value  string
+	absent bool
+	equals bool
+}
+
+type skipExpr struct {
+	conditions []skipCondition
+	expr       typeExpr
+}
+
+func (s skipExpr) check(ctx context.Context, qv *QueryValidator, value string) (failure, bool) {
+	return s.expr.check(ctx, qv, value)
+}
+
+func (s skipExpr) String() string {
+	terms := make([]string, 0, len(s.conditions)+1)
+	for _, c := range s.conditions {
+		terms = append(terms, skipPrefix+c.String())
+	}
+	if _, ok := s.expr.(andExpr); ok {
+		return strings.Join(append(terms, s.expr.String()), " AND ")
+	}
+	return strings.Join(append(terms, groupExpr(s.expr)), " AND ")
+}
+
+func (c skipCondition) String() string {
+	switch {
+	case c.absent:
+		return "!" + c.param
+	case c.equals:
+		return c.param + "=" + c.value
+	}
+	return c.param
+}
+
+func parseSkipCondition(term string) skipCondition {
+	cond := strings.TrimPrefix(term, skipPrefix)
+	if name, ok := strings.CutPrefix(cond, "!"); ok {
+		return skipCondition{param: name, absent: true}
+	}
+	if name, value, ok := strings.Cut(cond, "="); ok {
+		return skipCondition{param: name, value: value, equals: true}
+	}
+	return skipCondition{param: cond}
+}
+
+func (c skipCondition) holds(values map[string]string) bool {
+	value, present := values[c.param]
+	switch {
+	case c.absent:
+		return !present
+	case c.equals:
+		return present && value == c.value
+	}
+	return present
+}
+
+func hasSkipConditions(rule string) bool {
+	return strings.HasPrefix(strings.TrimSpace(rule), skipPrefix)
+}
+
+// skipped reports whether a skip_if condition of rule holds for values.
+func (qv *QueryValidator) skipped(rule string, values map[string]string) bool {
+	if !hasSkipConditions(rule) {
+		return false
+	}
+	expr, err := qv.parseTypeExpr(rule)
+	if err != nil {
+		return false
+	}
+	s, ok := expr.(skipExpr)
+	if !ok {
+		return false
+	}
+	for _, c := range s.conditions {
+		if c.holds(values) {
+			return true
+		}
+	}
+	return false
+}
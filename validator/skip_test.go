@@ -0,0 +1,56 @@
+package validator
+
+import "testing"
+
+func TestSkipIf(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"near":   "string",
+		"mode":   "in:all,some",
+		"radius": "skip_if:!near AND number AND max:50",
+		"ids":    "skip_if:mode=all AND required AND integer",
+		"debug":  "skip_if:trace AND boolean",
+		"trace":  "boolean",
+	}
+	tests := []struct {
+		values map[string]string
+		want   []string
+	}{
+		{map[string]string{"ids": "1", "radius": "x"}, nil},
+		{map[string]string{"ids": "1", "near": "here", "radius": "x"}, []string{"radius:INVALID_TYPE"}},
+		{map[string]string{"ids": "1", "near": "here", "radius": "51"}, []string{"radius:TOO_LARGE"}},
+		{map[string]string{"ids": "1", "near": "here", "radius": "5"}, nil},
+		{map[string]string{"mode": "all"}, nil},
+		{map[string]string{"mode": "all", "ids": "x"}, nil},
+		{map[string]string{"mode": "some", "ids": "x"}, []string{"ids:INVALID_TYPE"}},
+		{map[string]string{"mode": "some"}, []string{"ids:MISSING_REQUIRED"}},
+		{map[string]string{"ids": "1", "debug": "maybe", "trace": "true"}, nil},
+		{map[string]string{"ids": "1", "debug": "maybe"}, []string{"debug:INVALID_TYPE"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, tt.values, rules, tt.want...)
+	}
+}
+
+func TestSkipIfRules(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule string
+		want string
+	}{
+		{"skip_if:!near AND number AND max:50", "skip_if:!near AND number AND max:50"},
+		{"skip_if:a AND skip_if:b=1 AND uuid OR email", "skip_if:a AND skip_if:b=1 AND (uuid OR email)"},
+	}
+	for _, tt := range tests {
+		expr, err := qv.parseTypeExpr(tt.rule)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.rule, err)
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("%q: String() = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+	for _, rule := range []string{"skip_if:near", "skip_if:near OR number"} {
+		checkCodes(t, qv, map[string]string{"p": "1"}, map[string]string{"p": rule}, "p:INVALID_RULE")
+	}
+}
@@ -0,0 +1,148 @@
+package validator
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Cloud storage providers recognised by ParseStorageURI.
+const (
+	StorageS3    = "s3"
+	StorageGCS   = "gs"
+	StorageAzure = "azure"
+)
+
+// StorageURI is a parsed object storage location.
+type StorageURI struct {
+	Provider string
+	// Account is the Azure storage account; empty for S3 and GCS.
+	Account string
+	// Bucket is the S3/GCS bucket or the Azure container.
+	Bucket string
+	Key    string
+}
+
+// StorageURIOptions configures a storage URI validator.
+type StorageURIOptions struct {
+	// Providers lists the accepted providers. Empty means all of them.
+	Providers []string
+	// AllowedBuckets restricts the bucket (or container) names. Empty means any.
+	AllowedBuckets []string
+	// RequireKey rejects URIs that point at a bucket rather than an object.
+	RequireKey bool
+}
+
+const (
+	azureBlobHostSuffix     = ".blob.core.windows.net"
+	azureDataLakeHostSuffix = ".dfs.core.windows.net"
+)
+
+var (
+	s3BucketPattern       = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+	gcsBucketPattern      = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,220}[a-z0-9]$`)
+	azureAccountPattern   = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+	azureContainerPattern = regexp.MustCompile(`^[a-z0-9](-?[a-z0-9])*$`)
+)
+
+// NewStorageURIValidator returns a type validator accepting s3://, gs:// and
+// Azure blob URIs that satisfy opts.
+func NewStorageURIValidator(opts StorageURIOptions) func(string) bool {
+	return func(v string) bool {
+		loc, err := ParseStorageURI(v)
+		if err != nil {
+			return false
+		}
+		if len(opts.Providers) > 0 && !slices.Contains(opts.Providers, loc.Provider) {
+			return false
+		}
+		if len(opts.AllowedBuckets) > 0 && !slices.Contains(opts.AllowedBuckets, loc.Bucket) {
+			return false
+		}
+		return !opts.RequireKey || loc.Key != ""
+	}
+}
+
+// ParseStorageURI parses s3://bucket/key, gs://bucket/object,
+// https://account.blob.core.windows.net/container/blob and
+// abfss://container@account.dfs.core.windows.net/path, checking bucket names
+// against each provider's naming rules.
+func ParseStorageURI(raw string) (StorageURI, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return StorageURI{}, err
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+
+	var loc StorageURI
+	switch strings.ToLower(u.Scheme) {
+	case "s3":
+		loc = StorageURI{Provider: StorageS3, Bucket: u.Host, Key: key}
+		if !validS3Bucket(loc.Bucket) {
+			return StorageURI{}, fmt.Errorf("invalid s3 bucket name %q", loc.Bucket)
+		}
+	case "gs":
+		loc = StorageURI{Provider: StorageGCS, Bucket: u.Host, Key: key}
+		if !validGCSBucket(loc.Bucket) {
+			return StorageURI{}, fmt.Errorf("invalid gcs bucket name %q", loc.Bucket)
+		}
+	case "https":
+		account, ok := strings.CutSuffix(u.Host, azureBlobHostSuffix)
+		if !ok {
+			return StorageURI{}, fmt.Errorf("unsupported storage host %q", u.Host)
+		}
+		container, blob, _ := strings.Cut(key, "/")
+		loc = StorageURI{Provider: StorageAzure, Account: account, Bucket: container, Key: blob}
+	case "abfs", "abfss":
+		account, ok := strings.CutSuffix(u.Host, azureDataLakeHostSuffix)
+		if !ok || u.User == nil {
+			return StorageURI{}, fmt.Errorf("unsupported storage host %q", u.Host)
+		}
+		loc = StorageURI{Provider: StorageAzure, Account: account, Bucket: u.User.Username(), Key: key}
+	default:
+		return StorageURI{}, fmt.Errorf("unsupported storage scheme %q", u.Scheme)
+	}
+
+	if loc.Provider == StorageAzure {
+		if !azureAccountPattern.MatchString(loc.Account) {
+			return StorageURI{}, fmt.Errorf("invalid azure storage account %q", loc.Account)
+		}
+		if len(loc.Bucket) < 3 || len(loc.Bucket) > 63 || !azureContainerPattern.MatchString(loc.Bucket) {
+			return StorageURI{}, fmt.Errorf("invalid azure container name %q", loc.Bucket)
+		}
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return StorageURI{}, fmt.Errorf("storage uri must not carry a query or fragment")
+	}
+	return loc, nil
+}
+
+func validS3Bucket(name string) bool {
+	if !s3BucketPattern.MatchString(name) || strings.Contains(name, "..") {
+		return false
+	}
+	if strings.HasPrefix(name, "xn--") || strings.HasSuffix(name, "-s3alias") {
+		return false
+	}
+	_, err := netip.ParseAddr(name)
+	return err != nil
+}
+
+func validGCSBucket(name string) bool {
+	if !gcsBucketPattern.MatchString(name) || strings.Contains(name, "..") {
+		return false
+	}
+	if strings.HasPrefix(name, "goog") || strings.Contains(name, "google") {
+		return false
+	}
+	for _, part := range strings.Split(name, ".") {
+		if len(part) > 63 {
+			return false
+		}
+	}
+	_, err := netip.ParseAddr(name)
+	return err != nil
+}
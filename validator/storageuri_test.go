@@ -0,0 +1,63 @@
+package validator
+
+import "testing"
+
+func TestParseStorageURI(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want StorageURI
+		ok   bool
+	}{
+		{"s3://my-bucket/path/to/key.csv", StorageURI{Provider: StorageS3, Bucket: "my-bucket", Key: "path/to/key.csv"}, true},
+		{"S3://my-bucket", StorageURI{Provider: StorageS3, Bucket: "my-bucket"}, true},
+		{"gs://data_lake.example/obj", StorageURI{Provider: StorageGCS, Bucket: "data_lake.example", Key: "obj"}, true},
+		{"https://acct01.blob.core.windows.net/logs/2024/a.json", StorageURI{Provider: StorageAzure, Account: "acct01", Bucket: "logs", Key: "2024/a.json"}, true},
+		{"abfss://raw@acct01.dfs.core.windows.net/events", StorageURI{Provider: StorageAzure, Account: "acct01", Bucket: "raw", Key: "events"}, true},
+		{"s3://My_Bucket/key", StorageURI{}, false},
+		{"s3://a..b/key", StorageURI{}, false},
+		{"s3://192.168.1.1/key", StorageURI{}, false},
+		{"s3://xn--bucket/key", StorageURI{}, false},
+		{"s3://bucket-s3alias/key", StorageURI{}, false},
+		{"gs://google-data/key", StorageURI{}, false},
+		{"gs://goog-bucket/key", StorageURI{}, false},
+		{"https://example.com/container/blob", StorageURI{}, false},
+		{"https://acct01.blob.core.windows.net/a--b/blob", StorageURI{}, false},
+		{"https://AC.blob.core.windows.net/logs/blob", StorageURI{}, false},
+		{"abfss://acct01.dfs.core.windows.net/events", StorageURI{}, false},
+		{"s3://my-bucket/key?versionId=1", StorageURI{}, false},
+		{"ftp://my-bucket/key", StorageURI{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseStorageURI(tt.raw)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseStorageURI(%q) = %+v, %v, want %+v, ok %v", tt.raw, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStorageURIValidator(t *testing.T) {
+	valid := NewStorageURIValidator(StorageURIOptions{
+		Providers:      []string{StorageS3},
+		AllowedBuckets: []string{"exports"},
+		RequireKey:     true,
+	})
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"s3://exports/2024/report.csv", true},
+		{"s3://exports", false},
+		{"s3://imports/report.csv", false},
+		{"gs://exports/report.csv", false},
+	}
+	for _, tt := range tests {
+		if got := valid(tt.raw); got != tt.want {
+			t.Errorf("storage URI %s = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+
+	qv := NewQueryValidator()
+	rules := map[string]string{"src": "storageuri"}
+	checkCodes(t, qv, map[string]string{"src": "gs://bucket-1/x"}, rules)
+	checkCodes(t, qv, map[string]string{"src": "file:///etc/passwd"}, rules, "src:INVALID_TYPE")
+}
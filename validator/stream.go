@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"bytes"
+	"net/url"
+)
+
+// StreamLimits are hard limits checked while a raw query is scanned. Zero
+// disables a limit.
+type StreamLimits struct {
+	MaxQueryBytes int
+	MaxParams     int
+	MaxKeyBytes   int
+	MaxValueBytes int
+	// MaxErrors caps the number of errors reported.
+	MaxErrors int
+}
+
+// DefaultStreamLimits suit typical public endpoints.
+var DefaultStreamLimits = StreamLimits{
+	MaxQueryBytes: 8 << 10,
+	MaxParams:     64,
+	MaxKeyBytes:   128,
+	MaxValueBytes: 2 << 10,
+	MaxErrors:     20,
+}
+
+// scanQuery decodes raw pair by pair, checking the limits as it goes, so a
+// breach stops the scan before the rest of an adversarially long query is
+// decoded. breach is the error of the limit that stopped it, if any.
+func scanQuery(raw []byte, limits StreamLimits) (values url.Values, breach *QueryValidationError) {
+	exceeded := func(param, limit string) *QueryValidationError {
+		err := newValidationError(param, "", fail(MsgLimitExceeded, limit))
+		return &err
+	}
+	if limits.MaxQueryBytes > 0 && len(raw) > limits.MaxQueryBytes {
+		return nil, exceeded("", "query length")
+	}
+
+	values = make(url.Values)
+	params := 0
+	for len(raw) > 0 {
+		var pair []byte
+		if i := bytes.IndexByte(raw, '&'); i >= 0 {
+			pair, raw = raw[:i], raw[i+1:]
+		} else {
+			pair, raw = raw, nil
+		}
+		if len(pair) == 0 {
+			continue
+		}
+
+		params++
+		if limits.MaxParams > 0 && params > limits.MaxParams {
+			return nil, exceeded("", "parameter count")
+		}
+
+		rawKey, rawValue, _ := bytes.Cut(pair, []byte("="))
+		if limits.MaxKeyBytes > 0 && len(rawKey) > limits.MaxKeyBytes {
+			return nil, exceeded("", "parameter name length")
+		}
+		param := unescapeQueryComponent(rawKey)
+		if limits.MaxValueBytes > 0 && len(rawValue) > limits.MaxValueBytes {
+			return nil, exceeded(param, "value length")
+		}
+		values[param] = append(values[param], unescapeQueryComponent(rawValue))
+	}
+	return values, nil
+}
+
+// unescapeQueryComponent decodes like fasthttp: '+' is a space and malformed
+// escapes are kept literally.
+func unescapeQueryComponent(b []byte) string {
+	s, err := url.QueryUnescape(string(b))
+	if err != nil {
+		return string(b)
+	}
+	return s
+}
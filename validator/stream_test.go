@@ -0,0 +1,92 @@
+//go:build !tinygo && !wasm
+
+package validator
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestValidateQueryStream(t *testing.T) {
+	rules := map[string]string{
+		"q":         "string AND maxlen:10",
+		"limit":     "int:64 AND min:1 AND max:100 AND clamp",
+		"filter[*]": "in:open,closed",
+		"ids":       "array:integer",
+		"near":      "string",
+		"radius":    "skip_if:!near AND number",
+	}
+	tests := []struct {
+		name   string
+		query  string
+		limits StreamLimits
+		want   []string
+		// args are query values the handler must see afterwards.
+		args url.Values
+		// codesOnly compares codes alone, for errors whose parameters are
+		// unspecified.
+		codesOnly bool
+	}{
+		{name: "valid", query: "q=go&limit=10&ids=1&ids=2", limits: DefaultStreamLimits,
+			args: url.Values{"limit": {"10"}}},
+		{name: "clamped", query: "limit=500", limits: DefaultStreamLimits,
+			want: []string{"limit:CLAMPED"}, args: url.Values{"limit": {"100"}}},
+		{name: "wildcard rule", query: "filter[state]=gone", limits: DefaultStreamLimits,
+			want: []string{"filter[state]:NOT_ALLOWED"}},
+		{name: "array items", query: "ids=1&ids=x", limits: DefaultStreamLimits,
+			want: []string{"ids:INVALID_TYPE"}},
+		{name: "skipped", query: "radius=x", limits: DefaultStreamLimits},
+		{name: "not skipped", query: "radius=x&near=here", limits: DefaultStreamLimits,
+			want: []string{"radius:INVALID_TYPE"}},
+		{name: "unexpected", query: "nope=1", limits: DefaultStreamLimits,
+			want: []string{"nope:UNEXPECTED_PARAM"}},
+		{name: "query length", query: "q=" + strings.Repeat("x", 100), limits: StreamLimits{MaxQueryBytes: 50},
+			want: []string{":LIMIT_EXCEEDED"}},
+		{name: "parameter count", query: "q=a&q=b&q=c", limits: StreamLimits{MaxParams: 2},
+			want: []string{":LIMIT_EXCEEDED"}},
+		{name: "name length", query: strings.Repeat("k", 20) + "=1", limits: StreamLimits{MaxKeyBytes: 10},
+			want: []string{":LIMIT_EXCEEDED"}},
+		{name: "value length", query: "q=" + strings.Repeat("x", 20), limits: StreamLimits{MaxValueBytes: 10},
+			want: []string{"q:LIMIT_EXCEEDED"}},
+		{name: "max errors", query: "a=1&b=2&c=3", limits: StreamLimits{MaxErrors: 2},
+			want: []string{"UNEXPECTED_PARAM", "UNEXPECTED_PARAM"}, codesOnly: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qv := NewQueryValidator()
+			got := serveQuery(t, "/", "/?"+tt.query, func(c fiber.Ctx) []QueryValidationError {
+				errors := qv.ValidateQueryStream(c, rules, tt.limits)
+				for param, want := range tt.args {
+					if value := c.Query(param); value != want[0] {
+						t.Errorf("c.Query(%q) = %q, want %q", param, value, want[0])
+					}
+				}
+				return errors
+			})
+			codes := errorCodes(got.Errors)
+			if tt.codesOnly {
+				for i, code := range codes {
+					_, codes[i], _ = strings.Cut(code, ":")
+				}
+			}
+			if !slices.Equal(codes, tt.want) {
+				t.Errorf("errors = %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQueryStreamStrips(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetUnknownParamPolicy(UnknownParamPolicy{Mode: UnknownStrip})
+	got := serveQuery(t, "/", "/?q=go&utm_source=x", func(c fiber.Ctx) []QueryValidationError {
+		return qv.ValidateQueryStream(c, map[string]string{"q": "string"}, DefaultStreamLimits)
+	})
+	if len(got.Errors) != 0 || got.Query != "q=go" {
+		t.Errorf("errors %v and query %q, want none and q=go", got.Errors, got.Query)
+	}
+}
@@ -0,0 +1,137 @@
+package validator
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/message"
+)
+
+// unsuggestable are the failures a corrected value cannot fix, or that
+// would need a remote lookup per candidate.
+var unsuggestable = map[string]bool{
+	MsgInvalidRule:      true,
+	MsgLimitExceeded:    true,
+	MsgValidatorTimeout: true,
+	MsgInternalError:    true,
+	MsgNotFound:         true,
+	MsgAlreadyTaken:     true,
+	MsgClamped:          true,
+	MsgFellBack:         true,
+	MsgTooFewItems:      true,
+	MsgTooManyItems:     true,
+}
+
+// suggest fills in the Suggestion of errors whose value is close to one
+// rule accepts: surrounding whitespace, another date separator, a decimal
+// comma, or an allowed value within a small edit distance. Failed enums
+// also say "did you mean" in their message.
+func (qv *QueryValidator) suggest(ctx context.Context, errors []QueryValidationError, rule string) {
+	for i := range errors {
+		err := &errors[i]
+		if err.Value == "" || unsuggestable[err.reason.key] {
+			continue
+		}
+		elemRule := rule
+		if err.Index != nil {
+			spec, _ := parseArrayRule(rule)
+			elemRule = spec.elem
+		}
+		for _, candidate := range qv.suggestionCandidates(err.Value, elemRule) {
+			if candidate == err.Value {
+				continue
+			}
+			if _, ok := qv.checkParamValue(ctx, candidate, elemRule); ok {
+				err.Suggestion = candidate
+				err.Message += didYouMean(messages, *err)
+				break
+			}
+		}
+	}
+}
+
+// didYouMean is the hint appended to the message of an enum failure with a
+// suggestion, or nothing.
+func didYouMean(printer *message.Printer, err QueryValidationError) string {
+	if err.Suggestion == "" || err.reason.key != MsgNotAllowed {
+		return ""
+	}
+	return " (" + printer.Sprintf(MsgDidYouMean, err.Suggestion) + ")"
+}
+
+// hyphenated spells separators as hyphens, as in 2024/01/02 or in_progress.
+var hyphenated = strings.NewReplacer("/", "-", ".", "-", "_", "-", " ", "-")
+
+// suggestionCandidates lists likely corrections of value, best first.
+func (qv *QueryValidator) suggestionCandidates(value, rule string) []string {
+	trimmed := strings.TrimSpace(value)
+	candidates := []string{trimmed,
+		hyphenated.Replace(trimmed),
+		strings.Replace(trimmed, ",", ".", 1),
+		strings.ToLower(trimmed),
+		strings.ToUpper(trimmed),
+	}
+	if nearest, ok := nearestValue(trimmed, qv.allowedValues(rule)); ok {
+		candidates = append(candidates, nearest)
+	}
+	return candidates
+}
+
+// allowedValues collects the values the in and enum terms of rule allow.
+func (qv *QueryValidator) allowedValues(rule string) []string {
+	var allowed []string
+	for _, term := range ruleTerms(rule) {
+		name, arg, _ := strings.Cut(term, ":")
+		switch name {
+		case "in":
+			allowed = append(allowed, strings.Split(arg, ",")...)
+		case "enum":
+			if enum, ok := qv.enums[arg]; ok {
+				allowed = append(allowed, enum.list()...)
+			}
+		}
+	}
+	return allowed
+}
+
+// nearestValue returns the allowed value closest to value by edit distance,
+// ignoring case, when it is close enough to be a likely typo: at most one
+// edit per three characters, and at least one.
+func nearestValue(value string, allowed []string) (string, bool) {
+	slices.Sort(allowed)
+	best, bestDistance := "", -1
+	for _, candidate := range allowed {
+		d := editDistance(strings.ToLower(value), strings.ToLower(candidate))
+		if d > max(1, utf8.RuneCountInString(candidate)/3) {
+			continue
+		}
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best, bestDistance >= 0
+}
+
+// editDistance is the Levenshtein distance between a and b in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
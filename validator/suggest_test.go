@@ -0,0 +1,114 @@
+package validator
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	qv := NewQueryValidator()
+	tests := []struct {
+		rule  string
+		value string
+		want  string
+	}{
+		{"integer", " 42 ", "42"},
+		{"date", "2024/01/02", "2024-01-02"},
+		{"number", "3,5", "3.5"},
+		{"boolean", "TRUE!", ""},
+		{"in:active,inactive", "Active", "active"},
+		{"in:active,inactive", "actve", "active"},
+		{"in:in_progress,done", "in-progress", "in_progress"},
+		{"in:red,green", "blue", ""},
+		{"uuid", "nope", ""},
+		{"integer AND max:10", "11", ""},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(map[string]string{"p": tt.value}, map[string]string{"p": tt.rule})
+		if len(errs) != 1 || errs[0].Suggestion != tt.want {
+			t.Errorf("%q against %q: errors %+v, want suggestion %q", tt.value, tt.rule, errs, tt.want)
+		}
+	}
+}
+
+func TestSuggestionMessages(t *testing.T) {
+	qv := NewQueryValidator()
+	if got, want := errorMessage(t, qv, "status", "actve", "in:active,inactive"), "value must be one of active,inactive (did you mean \"active\"?)"; got != want {
+		t.Errorf("enum message %q, want %q", got, want)
+	}
+	if got, want := errorMessage(t, qv, "n", " 4", "integer"), "invalid value for type integer"; got != want {
+		t.Errorf("type message %q, want %q", got, want)
+	}
+
+	errs := qv.ValidateValues(url.Values{"ids": {"1", "2x"}}, map[string]string{"ids": "array:integer"})
+	if len(errs) != 1 || errs[0].Suggestion != "" {
+		t.Errorf("array errors %+v", errs)
+	}
+	errs = qv.ValidateValues(url.Values{"ids": {"1", " 2"}}, map[string]string{"ids": "array:integer"})
+	if len(errs) != 1 || errs[0].Suggestion != "2" {
+		t.Errorf("array element suggestion: errors %+v, want 2", errs)
+	}
+}
+
+func TestNearestValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{"actve", "active", true},
+		{"ACTIVE", "active", true},
+		{"pendng", "pending", true},
+		{"act", "", false},
+		{"zz", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := nearestValue(tt.value, []string{"pending", "active", "inactive"})
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("nearestValue(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEnumSuggestions(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.AddEnum("plans", EnumProviderFunc(func(context.Context) ([]string, error) {
+		return []string{"free", "pro", "enterprise"}, nil
+	}), EnumOptions{})
+	rules := map[string]string{"plan": "enum:plans"}
+	tests := []struct {
+		value      string
+		suggestion string
+		message    string
+	}{
+		{"enterprize", "enterprise", `value must be one of plans (did you mean "enterprise"?)`},
+		{"Pro", "pro", `value must be one of plans (did you mean "pro"?)`},
+		{"gold", "", "value must be one of plans"},
+	}
+	for _, tt := range tests {
+		errs := qv.ValidateMap(map[string]string{"plan": tt.value}, rules)
+		if len(errs) != 1 || errs[0].Suggestion != tt.suggestion || errs[0].Message != tt.message {
+			t.Errorf("%q: errors %+v, want suggestion %q and message %q", tt.value, errs, tt.suggestion, tt.message)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"inactive", "inactive", 0},
+		{"inactve", "inactive", 1},
+		{"kitten", "sitting", 3},
+		{"größe", "grösse", 2},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
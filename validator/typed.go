@@ -0,0 +1,196 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// IntegerType is the set of types Int rules produce.
+type IntegerType interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// FloatType is the set of types Float rules produce.
+type FloatType interface {
+	~float32 | ~float64
+}
+
+// TypedRule is a rule whose accepted values parse into T. Its String form
+// goes into a rules map, and Parse or Value turn validated values into T:
+//
+//	age := validator.Int[int64](validator.Min(1), validator.Max(100))
+//	rules := map[string]string{"age": age.String()}
+type TypedRule[T any] struct {
+	rule  string
+	parse func(string) (T, error)
+}
+
+func (r TypedRule[T]) String() string { return r.rule }
+
+// MarshalJSON encodes the rule as its String form.
+func (r TypedRule[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.rule)
+}
+
+// Parse converts value, which should have passed the rule, into T.
+func (r TypedRule[T]) Parse(value string) (T, error) {
+	return r.parse(value)
+}
+
+// Value parses the value of param in values. ok is false when param is
+// absent or does not parse.
+func (r TypedRule[T]) Value(values map[string]string, param string) (value T, ok bool) {
+	raw, present := values[param]
+	if !present {
+		return value, false
+	}
+	parsed, err := r.parse(raw)
+	if err != nil {
+		return value, false
+	}
+	return parsed, true
+}
+
+// RuleOption refines the rule a constructor builds.
+type RuleOption func(*ruleOptions)
+
+type ruleOptions struct {
+	terms []string
+}
+
+// Min requires numbers of at least n.
+func Min(n float64) RuleOption {
+	return func(o *ruleOptions) {
+		o.terms = append(o.terms, "min:"+strconv.FormatFloat(n, 'f', -1, 64))
+	}
+}
+
+// Max requires numbers of at most n.
+func Max(n float64) RuleOption {
+	return func(o *ruleOptions) {
+		o.terms = append(o.terms, "max:"+strconv.FormatFloat(n, 'f', -1, 64))
+	}
+}
+
+// NotIn rejects the listed values.
+func NotIn(values ...string) RuleOption {
+	return func(o *ruleOptions) {
+		o.terms = append(o.terms, "not_in:"+strings.Join(values, ","))
+	}
+}
+
+// Scale allows at most digits fractional digits.
+func Scale(digits int) RuleOption {
+	return func(o *ruleOptions) {
+		o.terms = append(o.terms, "scale:"+strconv.Itoa(digits))
+	}
+}
+
+// Clamp moves numbers beyond Min or Max onto the bound instead of rejecting
+// them, with a CLAMPED warning.
+func Clamp() RuleOption {
+	return func(o *ruleOptions) {
+		o.terms = append(o.terms, clampTerm)
+	}
+}
+
+// Fallback replaces values that fail the rule with the parameter's default
+// instead of rejecting them, with a FELL_BACK warning.
+func Fallback() RuleOption {
+	return func(o *ruleOptions) {
+		o.terms = append(o.terms, fallbackTerm)
+	}
+}
+
+func buildRule(base string, opts []RuleOption) string {
+	o := ruleOptions{terms: []string{base}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return strings.Join(o.terms, " AND ")
+}
+
+// Int builds a rule accepting integers that fit T.
+func Int[T IntegerType](opts ...RuleOption) TypedRule[T] {
+	t := reflect.TypeFor[T]()
+	bits := t.Bits()
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypedRule[T]{
+			rule: buildRule(fmt.Sprintf("uint:%d", bits), opts),
+			parse: func(v string) (T, error) {
+				n, err := strconv.ParseUint(v, 10, bits)
+				return T(n), err
+			},
+		}
+	}
+	return TypedRule[T]{
+		rule: buildRule(fmt.Sprintf("int:%d", bits), opts),
+		parse: func(v string) (T, error) {
+			n, err := strconv.ParseInt(v, 10, bits)
+			return T(n), err
+		},
+	}
+}
+
+// Float builds a rule accepting numbers that fit T.
+func Float[T FloatType](opts ...RuleOption) TypedRule[T] {
+	bits := reflect.TypeFor[T]().Bits()
+	return TypedRule[T]{
+		rule: buildRule(fmt.Sprintf("float:%d", bits), opts),
+		parse: func(v string) (T, error) {
+			n, err := strconv.ParseFloat(v, bits)
+			return T(n), err
+		},
+	}
+}
+
+// Bool builds a rule accepting the boolean type's spellings.
+func Bool(opts ...RuleOption) TypedRule[bool] {
+	return TypedRule[bool]{
+		rule: buildRule("boolean", opts),
+		parse: func(v string) (bool, error) {
+			return strconv.ParseBool(strings.ToLower(v))
+		},
+	}
+}
+
+// Flag builds a rule for presence flags, where ?verbose alone means true.
+// Value reports false for absent flags.
+func Flag(opts ...RuleOption) TypedRule[bool] {
+	return TypedRule[bool]{
+		rule:  buildRule(flagType, opts),
+		parse: parseFlag,
+	}
+}
+
+// String builds a rule accepting any value, refined by opts.
+func String(opts ...RuleOption) TypedRule[string] {
+	return TypedRule[string]{
+		rule:  buildRule("string", opts),
+		parse: func(v string) (string, error) { return v, nil },
+	}
+}
+
+// Enum builds a rule accepting exactly the given values.
+func Enum[T ~string](values ...T) TypedRule[T] {
+	allowed := make([]string, len(values))
+	for i, v := range values {
+		allowed[i] = string(v)
+	}
+	return TypedRule[T]{
+		rule: "in:" + strings.Join(allowed, ","),
+		parse: func(v string) (T, error) {
+			for _, allowed := range values {
+				if string(allowed) == v {
+					return allowed, nil
+				}
+			}
+			return "", fmt.Errorf("%q is not one of %s", v, strings.Join(allowed, ", "))
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+type status string
+
+func TestTypedRuleStrings(t *testing.T) {
+	tests := []struct {
+		rule fmt.Stringer
+		want string
+	}{
+		{Int[int64](Min(1), Max(100)), "int:64 AND min:1 AND max:100"},
+		{Int[int8](), "int:8"},
+		{Int[uint16](), "uint:16"},
+		{Float[float32](Scale(2)), "float:32 AND scale:2"},
+		{Float[float64](Min(-0.5)), "float:64 AND min:-0.5"},
+		{Bool(), "boolean"},
+		{String(NotIn("admin", "root")), "string AND not_in:admin,root"},
+		{Enum[status]("active", "inactive"), "in:active,inactive"},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.String(); got != tt.want {
+			t.Errorf("rule %q, want %q", got, tt.want)
+		}
+	}
+	body, err := json.Marshal(map[string]any{"age": Int[int32](Min(0))})
+	if err != nil || string(body) != `{"age":"int:32 AND min:0"}` {
+		t.Errorf("MarshalJSON = %s, %v", body, err)
+	}
+}
+
+func TestTypedRuleValues(t *testing.T) {
+	qv := NewQueryValidator()
+	age := Int[int8](Min(0))
+	ratio := Float[float32]()
+	active := Bool()
+	state := Enum[status]("active", "inactive")
+	rules := map[string]string{
+		"age":    age.String(),
+		"ratio":  ratio.String(),
+		"active": active.String(),
+		"state":  state.String(),
+	}
+	values := map[string]string{"age": "42", "ratio": "0.25", "active": "TRUE", "state": "inactive"}
+	checkCodes(t, qv, values, rules)
+
+	if v, ok := age.Value(values, "age"); !ok || v != 42 {
+		t.Errorf("age = %v, %v", v, ok)
+	}
+	if v, ok := ratio.Value(values, "ratio"); !ok || v != 0.25 {
+		t.Errorf("ratio = %v, %v", v, ok)
+	}
+	if v, ok := active.Value(values, "active"); !ok || !v {
+		t.Errorf("active = %v, %v", v, ok)
+	}
+	if v, ok := state.Value(values, "state"); !ok || v != status("inactive") {
+		t.Errorf("state = %v, %v", v, ok)
+	}
+	if _, ok := age.Value(values, "missing"); ok {
+		t.Error("Value of an absent parameter is ok")
+	}
+
+	checkCodes(t, qv, map[string]string{"age": "200"}, rules, "age:CONSTRAINT_FAILED")
+	checkCodes(t, qv, map[string]string{"state": "gone"}, rules, "state:NOT_ALLOWED")
+	if _, err := age.Parse("200"); err == nil {
+		t.Error("Parse accepted a value that overflows int8")
+	}
+	if _, err := state.Parse("gone"); err == nil {
+		t.Error("Parse accepted a value outside the enum")
+	}
+}
@@ -0,0 +1,78 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UniqueChecker reports whether value is already taken, e.g. whether a slug
+// is reserved. It should give up when ctx is done.
+type UniqueChecker func(ctx context.Context, value string) (taken bool, err error)
+
+// UniqueOptions controls a uniqueness check.
+type UniqueOptions struct {
+	// Timeout bounds each check. Zero means 2 seconds.
+	Timeout time.Duration
+	// FailOpen accepts the value when the checker errors or times out,
+	// for checks that are advisory, as in a preflight. By default such
+	// values are rejected.
+	FailOpen bool
+}
+
+// AddUniqueChecker registers a checker usable as "unique:name" in rules,
+// which rejects values the checker reports as taken.
+func (qv *QueryValidator) AddUniqueChecker(name string, checker UniqueChecker, opts UniqueOptions) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Second
+	}
+	qv.uniqueCheckers[name] = uniqueCheck{fn: checker, opts: opts}
+}
+
+type uniqueCheck struct {
+	fn   UniqueChecker
+	opts UniqueOptions
+}
+
+func (qv *QueryValidator) available(name string, u uniqueCheck, value string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), u.opts.Timeout)
+	defer cancel()
+
+	type result struct {
+		taken bool
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				qv.notifyPanic("unique checker "+name, r)
+				done <- result{err: fmt.Errorf("checker panicked: %v", r)}
+			}
+		}()
+		taken, err := u.fn(ctx, value)
+		done <- result{taken: taken, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return u.opts.FailOpen
+		}
+		return !r.taken
+	case <-ctx.Done():
+		return u.opts.FailOpen
+	}
+}
+
+func (qv *QueryValidator) uniqueConstraint(arg string) (func(string) bool, error) {
+	check, ok := qv.uniqueCheckers[arg]
+	if !ok {
+		return nil, fmt.Errorf("unknown unique checker %s", arg)
+	}
+	return func(value string) bool {
+		return qv.available(arg, check, value)
+	}, nil
+}
@@ -0,0 +1,52 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestUniqueChecker(t *testing.T) {
+	taken := func(_ context.Context, v string) (bool, error) { return v == "admin", nil }
+	failing := func(context.Context, string) (bool, error) { return false, errors.New("unavailable") }
+	slow := func(ctx context.Context, _ string) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}
+	panicking := func(context.Context, string) (bool, error) { panic("boom") }
+	tests := []struct {
+		name    string
+		checker UniqueChecker
+		opts    UniqueOptions
+		value   string
+		want    []string
+	}{
+		{"free", taken, UniqueOptions{}, "alice", nil},
+		{"taken", taken, UniqueOptions{}, "admin", []string{"slug:ALREADY_TAKEN"}},
+		{"error", failing, UniqueOptions{}, "alice", []string{"slug:ALREADY_TAKEN"}},
+		{"error fails open", failing, UniqueOptions{FailOpen: true}, "alice", nil},
+		{"timeout", slow, UniqueOptions{Timeout: 10 * time.Millisecond}, "alice", []string{"slug:ALREADY_TAKEN"}},
+		{"timeout fails open", slow, UniqueOptions{Timeout: 10 * time.Millisecond, FailOpen: true}, "alice", nil},
+		{"panic", panicking, UniqueOptions{}, "alice", []string{"slug:ALREADY_TAKEN"}},
+		{"taken fails open", taken, UniqueOptions{FailOpen: true}, "admin", []string{"slug:ALREADY_TAKEN"}},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		qv.AddUniqueChecker("slugs", tt.checker, tt.opts)
+		got := errorCodes(qv.ValidateMap(map[string]string{"slug": tt.value}, map[string]string{"slug": "string AND unique:slugs"}))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: errors %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueCheckerRules(t *testing.T) {
+	qv := NewQueryValidator()
+	checkCodes(t, qv, map[string]string{"slug": "a"}, map[string]string{"slug": "unique:slugs"}, "slug:INVALID_RULE")
+	qv.AddUniqueChecker("slugs", func(context.Context, string) (bool, error) { return true, nil }, UniqueOptions{})
+	if got := errorMessage(t, qv, "slug", "a", "unique:slugs"); got != "value is already taken in slugs" {
+		t.Errorf("message %q", got)
+	}
+}
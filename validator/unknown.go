@@ -0,0 +1,111 @@
+package validator
+
+import "strings"
+
+// UnknownParamMode is how parameters a rule set does not declare are treated.
+type UnknownParamMode int
+
+const (
+	// UnknownStrict rejects them with UNEXPECTED_PARAM. It is the default.
+	UnknownStrict UnknownParamMode = iota
+	// UnknownWarn reports them as UNEXPECTED_PARAM warnings.
+	UnknownWarn
+	// UnknownIgnore accepts them silently.
+	UnknownIgnore
+	// UnknownStrip accepts them silently and removes them from the query, so
+	// handlers and cross rules never see them.
+	UnknownStrip
+)
+
+// UnknownParamPolicy configures the treatment of undeclared parameters.
+// Entries of Allow and Deny are parameter names, or prefixes when they end
+// in '*', as in "utm_*".
+type UnknownParamPolicy struct {
+	Mode UnknownParamMode
+	// Allow lists parameters that are always ignored, whatever the mode.
+	Allow []string
+	// Deny lists parameters that are always rejected, whatever the mode.
+	// It wins over Allow.
+	Deny []string
+}
+
+// SetUnknownParamPolicy sets how undeclared parameters are treated, e.g. to
+// let clients send analytics parameters:
+//
+//	qv.SetUnknownParamPolicy(validator.UnknownParamPolicy{
+//		Mode:  validator.UnknownStrict,
+//		Allow: []string{"utm_*", "fbclid"},
+//	})
+func (qv *QueryValidator) SetUnknownParamPolicy(policy UnknownParamPolicy) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.unknownPolicy = policy
+}
+
+// unknownMode resolves the mode for the undeclared param.
+func (p UnknownParamPolicy) unknownMode(param string) UnknownParamMode {
+	switch {
+	case matchesParam(p.Deny, param):
+		return UnknownStrict
+	case matchesParam(p.Allow, param):
+		return UnknownIgnore
+	}
+	return p.Mode
+}
+
+// ignores reports whether the undeclared param is accepted without checks,
+// including the name format check.
+func (p UnknownParamPolicy) ignores(param string) bool {
+	mode := p.unknownMode(param)
+	return mode == UnknownIgnore || mode == UnknownStrip
+}
+
+func matchesParam(patterns []string, param string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(param, prefix) {
+				return true
+			}
+		} else if pattern == param {
+			return true
+		}
+	}
+	return false
+}
+
+// unexpectedParam reports the undeclared param according to the policy.
+func (qv *QueryValidator) unexpectedParam(param, value string, req validationRequest) []QueryValidationError {
+	mode := qv.unknownPolicy.unknownMode(param)
+	qv.observeUnknown(req.route, param)
+	err := newValidationError(param, value, fail(MsgUnexpected))
+	if mode == UnknownWarn {
+		err.Severity = SeverityWarning
+	}
+	return []QueryValidationError{err}
+}
+
+// observeUnknown passes the undeclared param to the metrics hook, whatever
+// the mode does with it.
+func (qv *QueryValidator) observeUnknown(route, param string) {
+	if qv.metrics != nil {
+		qv.metrics.UnknownParam(route, param)
+	}
+}
+
+// stripUnknown removes the undeclared parameters the policy strips from
+// values and returns their names.
+func (qv *QueryValidator) stripUnknown(values, rules map[string]string, route string) []string {
+	if qv.unknownPolicy.Mode != UnknownStrip {
+		return nil
+	}
+	var stripped []string
+	for param := range values {
+		if _, declared := rules[param]; declared || qv.unknownPolicy.unknownMode(param) != UnknownStrip {
+			continue
+		}
+		qv.observeUnknown(route, param)
+		delete(values, param)
+		stripped = append(stripped, param)
+	}
+	return stripped
+}
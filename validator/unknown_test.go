@@ -0,0 +1,66 @@
+package validator
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestUnknownParamPolicy(t *testing.T) {
+	rules := map[string]string{"q": "string"}
+	query := map[string]string{"q": "go", "utm_source": "news", "debug": "1", "utm_secret": "x"}
+	tests := []struct {
+		name       string
+		policy     UnknownParamPolicy
+		rejections []string
+		warnings   []string
+		kept       []string
+	}{
+		{"strict", UnknownParamPolicy{}, []string{"debug:UNEXPECTED_PARAM", "utm_secret:UNEXPECTED_PARAM", "utm_source:UNEXPECTED_PARAM"}, nil,
+			[]string{"debug", "q", "utm_secret", "utm_source"}},
+		{"warn", UnknownParamPolicy{Mode: UnknownWarn}, nil, []string{"debug:UNEXPECTED_PARAM", "utm_secret:UNEXPECTED_PARAM", "utm_source:UNEXPECTED_PARAM"},
+			[]string{"debug", "q", "utm_secret", "utm_source"}},
+		{"ignore", UnknownParamPolicy{Mode: UnknownIgnore}, nil, nil,
+			[]string{"debug", "q", "utm_secret", "utm_source"}},
+		{"strip", UnknownParamPolicy{Mode: UnknownStrip}, nil, nil, []string{"q"}},
+		{"strict with allow list", UnknownParamPolicy{Allow: []string{"utm_*"}}, []string{"debug:UNEXPECTED_PARAM"}, nil,
+			[]string{"debug", "q", "utm_secret", "utm_source"}},
+		{"deny wins over allow", UnknownParamPolicy{Mode: UnknownWarn, Allow: []string{"utm_*"}, Deny: []string{"utm_secret"}},
+			[]string{"utm_secret:UNEXPECTED_PARAM"}, []string{"debug:UNEXPECTED_PARAM"}, []string{"debug", "q", "utm_secret", "utm_source"}},
+		{"strip keeps allowed", UnknownParamPolicy{Mode: UnknownStrip, Allow: []string{"debug"}, Deny: []string{"utm_secret"}},
+			[]string{"utm_secret:UNEXPECTED_PARAM"}, nil, []string{"debug", "q", "utm_secret"}},
+	}
+	for _, tt := range tests {
+		qv := NewQueryValidator()
+		qv.SetUnknownParamPolicy(tt.policy)
+		values := maps.Clone(query)
+		errs := qv.ValidateMap(values, rules)
+		if got := errorCodes(Rejections(errs)); !slices.Equal(got, tt.rejections) {
+			t.Errorf("%s: rejections %v, want %v", tt.name, got, tt.rejections)
+		}
+		if got := errorCodes(Warnings(errs)); !slices.Equal(got, tt.warnings) {
+			t.Errorf("%s: warnings %v, want %v", tt.name, got, tt.warnings)
+		}
+		if got := slices.Sorted(maps.Keys(values)); !slices.Equal(got, tt.kept) {
+			t.Errorf("%s: values left %v, want %v", tt.name, got, tt.kept)
+		}
+	}
+}
+
+func TestMatchesParam(t *testing.T) {
+	tests := []struct {
+		param string
+		want  bool
+	}{
+		{"fbclid", true},
+		{"utm_source", true},
+		{"utm_", true},
+		{"utm", false},
+		{"fbclid2", false},
+	}
+	for _, tt := range tests {
+		if got := matchesParam([]string{"fbclid", "utm_*"}, tt.param); got != tt.want {
+			t.Errorf("matchesParam(%q) = %v, want %v", tt.param, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package validator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devdahcoder/golang-query-param-validator.git/internal/perfect"
+)
+
+// Query validation
+type QueryValidationError struct {
+	Parameter   string   `json:"parameter"`
+	Value       string   `json:"value"`
+	Message     string   `json:"message"`
+	Description string   `json:"description,omitempty"`
+	Severity    Severity `json:"severity,omitempty"`
+	// Code is the message key of the failure, such as MsgInvalidType's
+	// INVALID_TYPE. Unlike Message it is never localized or overridden.
+	Code string `json:"code,omitempty"`
+	// Index is the position of the failed element of an array parameter.
+	Index *int `json:"index,omitempty"`
+	// Suggestion is a nearby value that would pass, when one was found.
+	Suggestion string `json:"suggestion,omitempty"`
+	status     int
+	reason     failure
+}
+
+// CrossRule validates relationships between parameters and sees the whole query.
+type CrossRule func(values map[string]string) []QueryValidationError
+
+// QueryValidator validates queries against rules. It is safe for
+// concurrent use: registration may happen while requests are validated,
+// and waits for the validations in flight. Hooks it calls, such as
+// validators, cross rules and defaults, must not register anything.
+type QueryValidator struct {
+	// mu guards the registrations below. Validation holds it for reading.
+	// It is shared with the snapshots of CompiledRuleSets, whose
+	// constraints may call back into the validator they were compiled with.
+	mu *sync.RWMutex
+
+	frameworkHooks
+
+	paramPatterns      map[string]Regexp
+	typeValidators     map[string]func(string) bool
+	constraints        map[string]ConstraintFactory
+	crossRules         []CrossRule
+	defaults           map[string]DefaultFunc
+	roleRules          map[string]map[string]string
+	mediaTypeRules     map[string]map[string]string
+	paramScopes        map[string]string
+	descriptions       map[string]string
+	errorDescriptions  bool
+	routes             map[string]map[string]string
+	metrics            MetricsHook
+	contextValidators  map[string]budgetedValidator
+	panicHandler       PanicHandler
+	valueStats         *ValueStats
+	normalizers        map[string]Normalizer
+	regexpEngine       RegexpEngine
+	memos              map[string]*memoCache[typeOutcome]
+	encoding           EncodingPolicy
+	priorities         map[string]int
+	reportAll          bool
+	severities         SeverityPolicy
+	constraintMessages map[string]string
+	paramMessages      map[string]map[string]string
+	errorFormatter     ErrorFormatter
+	keyRings           map[string]*KeyRing
+	cursorCodecs       map[string]*CursorCodec
+	enums              map[string]*enumCache
+	unknownPolicy      UnknownParamPolicy
+	routePatterns      map[string]string
+	ruleConflicts      RuleConflictPolicy
+	reserved           []string
+	repository         *existsChecker
+	uniqueCheckers     map[string]uniqueCheck
+	parallelism        int
+
+	// compiled holds the expressions of a CompiledRuleSet's snapshot, by
+	// rule, and compiledRules its rules, which compiledParams indexes by
+	// parameter in a perfect hash table. They are nil otherwise and never
+	// written after compilation; compiledParams is also nil when no table
+	// could be built.
+	compiled       map[string]typeExpr
+	compiledRules  map[string]string
+	compiledParams *perfect.Table[string]
+}
+
+func NewQueryValidator() *QueryValidator {
+	qv := &QueryValidator{
+		mu:                 new(sync.RWMutex),
+		paramPatterns:      make(map[string]Regexp),
+		typeValidators:     make(map[string]func(string) bool),
+		constraints:        make(map[string]ConstraintFactory),
+		defaults:           make(map[string]DefaultFunc),
+		roleRules:          make(map[string]map[string]string),
+		mediaTypeRules:     make(map[string]map[string]string),
+		paramScopes:        make(map[string]string),
+		descriptions:       make(map[string]string),
+		routes:             make(map[string]map[string]string),
+		contextValidators:  make(map[string]budgetedValidator),
+		normalizers:        make(map[string]Normalizer),
+		regexpEngine:       stdRegexpEngine{},
+		memos:              make(map[string]*memoCache[typeOutcome]),
+		priorities:         make(map[string]int),
+		constraintMessages: make(map[string]string),
+		paramMessages:      make(map[string]map[string]string),
+		keyRings:           make(map[string]*KeyRing),
+		cursorCodecs:       make(map[string]*CursorCodec),
+		enums:              make(map[string]*enumCache),
+		routePatterns:      make(map[string]string),
+		uniqueCheckers:     make(map[string]uniqueCheck),
+	}
+
+	for name, pattern := range builtinNamePatterns {
+		qv.AddParamPattern(name, pattern)
+	}
+
+	qv.typeValidators["number"] = ValidNumber
+	qv.typeValidators["integer"] = ValidInteger
+	qv.typeValidators["float"] = ValidFloat
+
+	qv.typeValidators["boolean"] = func(v string) bool {
+		v = strings.ToLower(v)
+		return v == "true" || v == "false" || v == "1" || v == "0"
+	}
+
+	qv.typeValidators[flagType] = validFlag
+
+	qv.typeValidators["date"] = ValidDate
+
+	qv.typeValidators["glob"] = NewGlobValidator(DefaultGlobOptions)
+	qv.typeValidators["regex"] = NewRegexValidator(DefaultRegexOptions)
+	qv.typeValidators["hostname"] = NewHostnameValidator(IDNAcceptBoth)
+	qv.typeValidators["datauri"] = NewDataURIValidator(DefaultDataURIOptions)
+	qv.typeValidators["storageuri"] = NewStorageURIValidator(StorageURIOptions{})
+	qv.typeValidators["sha"] = ValidGitSHA
+	qv.typeValidators["shortsha"] = ValidGitShortSHA
+	qv.typeValidators["gitref"] = ValidGitRefName
+	qv.typeValidators["cron"] = NewCronValidator(CronStandard)
+	qv.typeValidators["idempotencyKey"] = NewIdempotencyKeyValidator(DefaultIdempotencyKeyOptions)
+	qv.typeValidators["apikey"] = NewAPIKeyValidator(DefaultAPIKeyOptions)
+	qv.typeValidators["uuid"] = ValidUUID
+	qv.typeValidators["uuidv4"] = ValidUUIDv4
+	qv.typeValidators["email"] = ValidEmail
+	qv.typeValidators["url"] = ValidURL
+	qv.typeValidators["ip"] = ValidIP
+	qv.typeValidators["ipv4"] = ValidIPv4
+	qv.typeValidators["ipv6"] = ValidIPv6
+	qv.typeValidators["cidr"] = ValidCIDR
+	qv.AddContextValidator("safeurl", cmp.Or(DefaultSafeURLOptions.Timeout, 2*time.Second), NewSafeURLValidator(DefaultSafeURLOptions))
+	qv.typeValidators["relativeurl"] = ValidRelativeURL
+	qv.typeValidators["jsonObject"] = ValidJSONObject
+	qv.typeValidators["graphqlName"] = ValidGraphQLName
+	qv.typeValidators["graphqlExtensions"] = ValidGraphQLExtensions
+	qv.typeValidators["graphqlPersistedQuery"] = ValidGraphQLPersistedQuery
+	qv.typeValidators["string"] = func(string) bool { return true }
+	qv.typeValidators[requiredType] = func(string) bool { return true }
+	qv.typeValidators[clampTerm] = func(string) bool { return true }
+	qv.typeValidators[fallbackTerm] = func(string) bool { return true }
+
+	qv.addBuiltinConstraints()
+	qv.addBuiltinNormalizers()
+
+	return qv
+}
+
+// AddParamPattern registers a parameter name pattern under name, for
+// SetNamePattern and name_pattern terms. A pattern named after a parameter
+// checks that parameter's name wherever it is sent, unless its rule has a
+// name_pattern term, e.g. AddParamPattern("ids", `^ids(\[\])?$`).
+func (qv *QueryValidator) AddParamPattern(name, pattern string) error {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	regex, err := qv.regexpEngine.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern for %s: %v", name, err)
+	}
+	qv.paramPatterns[name] = regex
+	return nil
+}
+
+func (qv *QueryValidator) AddTypeValidator(name string, validator func(string) bool) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.typeValidators[name] = validator
+}
+
+func (qv *QueryValidator) AddCrossRule(rule CrossRule) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.crossRules = append(qv.crossRules, rule)
+}
+
+// validationRequest carries what the core needs to know about the request
+// being validated. It is empty outside of an HTTP request.
+type validationRequest struct {
+	// ctx is the context of the request, for context validators; nil
+	// outside one.
+	ctx      context.Context
+	route    string
+	hasScope func(scope string) bool
+	// trusted reports whether the request may use reserved parameters.
+	trusted func() bool
+	// values is the whole query, for skip_if conditions.
+	values map[string]string
+	// exact are the rules before their wildcard keys were expanded, telling
+	// the parameters those matched apart.
+	exact map[string]string
+	// compiled is set when the rules are those of the validator's compiled
+	// set, so their parameters can be looked up in its table.
+	compiled bool
+}
+
+// context returns the request's context, or the background one outside a
+// request.
+func (req validationRequest) context() context.Context {
+	if req.ctx == nil {
+		return context.Background()
+	}
+	return req.ctx
+}
+
+// ValidateValues validates a parsed query, such as r.URL.Query() in net/http
+// or any map[string][]string, outside of a framework. Repeated keys keep
+// their last value, except for array parameters, which get all of them.
+// Role overlays and locales do not apply and scope-gated parameters are
+// rejected.
+func (qv *QueryValidator) ValidateValues(values url.Values, rules map[string]string) []QueryValidationError {
+	errors, _ := qv.run(flattenValues(values, rules), rules, validationRequest{})
+	return errors
+}
+
+// ValidateMap is ValidateValues for single-valued queries, e.g. in tests and
+// offline tools. Defaults are filled into values.
+func (qv *QueryValidator) ValidateMap(values map[string]string, rules map[string]string) []QueryValidationError {
+	errors, _ := qv.run(values, rules, validationRequest{})
+	return errors
+}
+
+// queryChanges are the edits run made to a query, for adapters to apply to
+// the request.
+type queryChanges struct {
+	set     map[string]string
+	removed []string
+}
+
+// run is the framework-agnostic core behind every Validate method: it fills
+// defaults into values, strips unknown parameters, clamps values or falls
+// back to defaults, validates them and returns the errors along with the
+// changes it made.
+func (qv *QueryValidator) run(values map[string]string, rules map[string]string, req validationRequest) ([]QueryValidationError, queryChanges) {
+	qv.mu.RLock()
+	defer qv.mu.RUnlock()
+	req.exact, rules = rules, expandWildcardRules(values, rules)
+	req.compiled = qv.compiledParams != nil && sameMap(rules, qv.compiledRules)
+	changes := queryChanges{set: qv.resolveDefaults(values, rules)}
+	changes.removed = qv.stripUnknown(values, rules, req.route)
+	undecryptable, decrypted, sealed := qv.decryptValues(values, rules, req)
+	clamps, clamped := qv.clampValues(values, rules)
+	fallbacks, replaced := qv.fallBack(req.context(), values, rules)
+	errors := qv.validate(values, rules, req)
+	errors = sealErrors(append(append(errors, clamps...), fallbacks...), decrypted, sealed)
+	errors = append(undecryptable, errors...)
+	qv.finishErrors(errors)
+	for _, changed := range []map[string]string{decrypted, clamped, replaced} {
+		if len(changed) > 0 && changes.set == nil {
+			changes.set = make(map[string]string)
+		}
+		maps.Copy(changes.set, changed)
+	}
+	return errors, changes
+}
+
+func (qv *QueryValidator) validate(queries map[string]string, rules map[string]string, req validationRequest) []QueryValidationError {
+	req.values = queries
+	qv.prefetchExists(queries, rules)
+	errors := qv.validateParams(queries, rules, req)
+	errors = append(errors, qv.missingRequired(queries, rules)...)
+	errors = append(errors, qv.crossFieldErrors(queries, rules)...)
+
+	for i, rule := range qv.crossRules {
+		errors = append(errors, qv.safeCrossRule(i, rule, queries)...)
+	}
+
+	return errors
+}
+
+// validateParam runs the per-parameter checks: reserved namespaces, name
+// format, scope, whether the parameter is declared, and its rule. It returns
+// no errors for a valid parameter and several only when reporting all
+// failures.
+func (qv *QueryValidator) validateParam(param, value string, rules map[string]string, req validationRequest) []QueryValidationError {
+	if reservedErr, ok := qv.checkReserved(req, param, value); !ok {
+		return []QueryValidationError{reservedErr}
+	}
+	rule, declared := qv.declaredRule(rules, req, param)
+	if !declared && qv.unknownPolicy.ignores(param) {
+		qv.observeUnknown(req.route, param)
+		return nil
+	}
+	_, exact := req.exact[param]
+	if !qv.validateParamName(param, rule, req.route, declared && req.exact != nil && !exact) {
+		return []QueryValidationError{newValidationError(param, value, fail(MsgInvalidName))}
+	}
+
+	if scopeErr, ok := qv.checkScope(req, param, value); !ok {
+		return []QueryValidationError{scopeErr}
+	}
+
+	if !declared {
+		return qv.unexpectedParam(param, value, req)
+	}
+	if qv.skipped(rule, req.values) {
+		return nil
+	}
+	if qv.valueStats != nil {
+		qv.valueStats.observe(req.route, param, value)
+	}
+
+	errors := qv.checkDeclared(req.context(), param, value, rule)
+	qv.suggest(req.context(), errors, rule)
+	return errors
+}
+
+// checkDeclared checks value against the rule of its declared parameter.
+func (qv *QueryValidator) checkDeclared(ctx context.Context, param, value, rule string) []QueryValidationError {
+	if spec, ok := parseArrayRule(rule); ok {
+		return qv.validateArray(ctx, param, value, spec)
+	}
+	if qv.reportAll {
+		var errors []QueryValidationError
+		for _, reason := range qv.checkParamValueAll(ctx, value, rule) {
+			errors = append(errors, newValidationError(param, value, reason))
+		}
+		return errors
+	}
+	if reason, ok := qv.checkParamValue(ctx, value, rule); !ok {
+		return []QueryValidationError{newValidationError(param, value, reason)}
+	}
+	return nil
+}
+
+// validateParamName checks param against its name pattern. Parameters a
+// wildcard key matched are named by the key, so only a pattern of their rule
+// applies to them.
+func (qv *QueryValidator) validateParamName(param, rule, route string, wildcard bool) bool {
+	name, overridden := ruleNamePattern(rule)
+	if wildcard && !overridden {
+		return true
+	}
+	own := false
+	if !overridden {
+		name, own = qv.ownNamePattern(param)
+	}
+	if !overridden && !own {
+		name = qv.namePattern(route)
+	}
+	pattern, exists := qv.paramPatterns[name]
+	switch {
+	case !exists:
+		return true
+	case own:
+		// Patterns named after a parameter see its brackets.
+		return pattern.MatchString(param)
+	}
+	return matchBracketedName(pattern, param)
+}
+
+// ownNamePattern returns the pattern registered under param's name, or
+// under the name its brackets follow, as "ids" for ids[] or ids[0].
+func (qv *QueryValidator) ownNamePattern(param string) (string, bool) {
+	if _, ok := qv.paramPatterns[param]; ok {
+		return param, true
+	}
+	if base, _, ok := strings.Cut(param, "["); ok {
+		if _, ok := qv.paramPatterns[base]; ok {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+func (qv *QueryValidator) checkParamValue(ctx context.Context, value, rule string) (failure, bool) {
+	expr, err := qv.parseTypeExpr(rule)
+	if err != nil {
+		return fail(MsgInvalidRule, rule, err), false
+	}
+	return expr.check(ctx, qv, value)
+}
+
+func (qv *QueryValidator) validateParamValue(value, expectedType string) bool {
+	validator, exists := qv.typeValidators[expectedType]
+	if !exists {
+		return true
+	}
+	return validator(value)
+}
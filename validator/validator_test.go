@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+)
+
+// errorCodes lists errors as param:CODE, sorted, for comparing outcomes.
+func errorCodes(errors []QueryValidationError) []string {
+	codes := make([]string, 0, len(errors))
+	for _, err := range errors {
+		codes = append(codes, err.Parameter+":"+err.Code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// checkCodes fails t unless validating values against rules gives the
+// listed param:CODE errors.
+func checkCodes(t *testing.T, qv *QueryValidator, values, rules map[string]string, want ...string) {
+	t.Helper()
+	got := errorCodes(qv.ValidateMap(values, rules))
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("ValidateMap(%v, %v) = %v, want %v", values, rules, got, want)
+	}
+}
+
+// errorMessage returns the message of the single error of param.
+func errorMessage(t *testing.T, qv *QueryValidator, param, value, rule string) string {
+	t.Helper()
+	errors := qv.ValidateMap(map[string]string{param: value}, map[string]string{param: rule})
+	if len(errors) != 1 {
+		t.Fatalf("%s=%s against %q: got %d errors, want 1", param, value, rule, len(errors))
+	}
+	return errors[0].Message
+}
+
+func TestValidateValues(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{"page": "required AND int:32 AND min:1", "q": "string"}
+	tests := []struct {
+		values url.Values
+		want   []string
+	}{
+		{url.Values{"page": {"1"}, "q": {"go"}}, nil},
+		{url.Values{"page": {"0"}}, []string{"page:TOO_SMALL"}},
+		{url.Values{"page": {"0", "2"}}, nil},
+		{url.Values{"page": {"2", "0"}}, []string{"page:TOO_SMALL"}},
+		{url.Values{"q": {"go"}}, []string{"page:MISSING_REQUIRED"}},
+		{url.Values{"page": {"1"}, "x": {""}}, []string{"x:UNEXPECTED_PARAM"}},
+		{nil, []string{"page:MISSING_REQUIRED"}},
+	}
+	for _, tt := range tests {
+		if got := errorCodes(qv.ValidateValues(tt.values, rules)); !slices.Equal(got, tt.want) {
+			t.Errorf("ValidateValues(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+		if got := errorCodes(qv.ValidateValues(map[string][]string(tt.values), rules)); !slices.Equal(got, tt.want) {
+			t.Errorf("ValidateValues(map %v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		rule  string
+		value string
+		want  string
+	}{
+		{"required", "", MsgMissingRequired},
+		{"integer", "ten", MsgInvalidType},
+		{"integer AND min:5", "1", MsgTooSmall},
+		{"integer AND max:5", "9", MsgTooLarge},
+		{"in:asc,desc", "up", MsgNotAllowed},
+		{"string AND maxlen:2", "abc", MsgTooLong},
+		{"regex:^[a-z]+$", "ABC", MsgPatternMismatch},
+	}
+	for _, tt := range tests {
+		values := map[string]string{}
+		if tt.value != "" {
+			values["p"] = tt.value
+		}
+		errors := NewQueryValidator().ValidateMap(values, map[string]string{"p": tt.rule})
+		if len(errors) != 1 || errors[0].Code != tt.want {
+			t.Errorf("%q with %q: errors %v, want code %s", tt.rule, tt.value, errors, tt.want)
+		}
+	}
+
+	qv := NewQueryValidator()
+	checkCodes(t, qv, map[string]string{"p": "1", "x": "1", "bad name": "1"}, map[string]string{"p": "integer"},
+		"bad name:"+MsgInvalidName, "x:"+MsgUnexpected)
+}
+
+func TestErrorCodesIgnoreMessages(t *testing.T) {
+	qv := NewQueryValidator()
+	qv.SetMessage("age", "min", "too young")
+	errors := qv.ValidateMap(map[string]string{"age": "3"}, map[string]string{"age": "integer AND min:18"})
+	if len(errors) != 1 || errors[0].Message != "too young" || errors[0].Code != MsgTooSmall {
+		t.Errorf("errors %v, want message %q and code %s", errors, "too young", MsgTooSmall)
+	}
+}
@@ -0,0 +1,188 @@
+package validator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValueStats collects statistics about the values clients send for declared
+// parameters, to inform tightening enums and ranges. It records nothing
+// about who sent a value: distinct values are counted by hash, and a value
+// is only reported among the top values once at least MinCount requests
+// sent it, so rare values such as personal data never surface. It is safe
+// for concurrent use.
+type ValueStats struct {
+	// MaxDistinct bounds the hashes kept per parameter for the distinct
+	// count. Zero means 10000.
+	MaxDistinct int
+	// MaxTracked bounds the values counted per parameter for the top list;
+	// values first seen once it is full are not counted. Zero means 100.
+	MaxTracked int
+	// MinCount is how often a value must have been sent to be reported.
+	// Zero means 5.
+	MinCount int
+	// TopN is how many top values are reported. Zero means 10.
+	TopN int
+
+	mu     sync.Mutex
+	routes map[string]map[string]*valueStat
+}
+
+type valueStat struct {
+	count     int
+	hashes    map[uint64]struct{}
+	capped    bool
+	numeric   bool
+	minNumber float64
+	maxNumber float64
+	minLength int
+	maxLength int
+	values    map[string]int
+}
+
+// ValueCount is how many times a value was sent.
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ParamStats is a snapshot of what ValueStats observed for a parameter.
+type ParamStats struct {
+	Count int `json:"count"`
+	// Distinct is the number of distinct values. When DistinctCapped is
+	// set it is a lower bound.
+	Distinct       int  `json:"distinct"`
+	DistinctCapped bool `json:"distinctCapped,omitempty"`
+	// Min and Max are the observed range when every value was a number.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// MinLength and MaxLength are the observed lengths in runes.
+	MinLength int          `json:"minLength"`
+	MaxLength int          `json:"maxLength"`
+	Top       []ValueCount `json:"top,omitempty"`
+}
+
+// SetValueStats enables collecting value statistics into stats. Pass nil to
+// stop collecting.
+func (qv *QueryValidator) SetValueStats(stats *ValueStats) {
+	qv.mu.Lock()
+	defer qv.mu.Unlock()
+	qv.valueStats = stats
+}
+
+func (s *ValueStats) observe(route, param, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.routes == nil {
+		s.routes = make(map[string]map[string]*valueStat)
+	}
+	params := s.routes[route]
+	if params == nil {
+		params = make(map[string]*valueStat)
+		s.routes[route] = params
+	}
+	stat := params[param]
+	if stat == nil {
+		stat = &valueStat{
+			hashes:    make(map[uint64]struct{}),
+			numeric:   true,
+			minNumber: math.Inf(1),
+			maxNumber: math.Inf(-1),
+			minLength: math.MaxInt,
+			values:    make(map[string]int),
+		}
+		params[param] = stat
+	}
+
+	stat.count++
+	sum := sha256.Sum256([]byte(value))
+	hash := binary.BigEndian.Uint64(sum[:8])
+	if _, seen := stat.hashes[hash]; !seen {
+		if len(stat.hashes) < orDefault(s.MaxDistinct, 10000) {
+			stat.hashes[hash] = struct{}{}
+		} else {
+			stat.capped = true
+		}
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil && stat.numeric {
+		stat.minNumber = min(stat.minNumber, n)
+		stat.maxNumber = max(stat.maxNumber, n)
+	} else {
+		stat.numeric = false
+	}
+	length := utf8.RuneCountInString(value)
+	stat.minLength = min(stat.minLength, length)
+	stat.maxLength = max(stat.maxLength, length)
+	if _, tracked := stat.values[value]; tracked || len(stat.values) < orDefault(s.MaxTracked, 100) {
+		stat.values[value]++
+	}
+}
+
+// Stats returns a snapshot of the statistics for route, keyed by parameter.
+func (s *ValueStats) Stats(route string) map[string]ParamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	params := s.routes[route]
+	if params == nil {
+		return nil
+	}
+	stats := make(map[string]ParamStats, len(params))
+	for name, stat := range params {
+		stats[name] = s.snapshot(stat)
+	}
+	return stats
+}
+
+// Routes returns the routes with statistics, sorted.
+func (s *ValueStats) Routes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	routes := make([]string, 0, len(s.routes))
+	for route := range s.routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func (s *ValueStats) snapshot(stat *valueStat) ParamStats {
+	p := ParamStats{
+		Count:          stat.count,
+		Distinct:       len(stat.hashes),
+		DistinctCapped: stat.capped,
+		MinLength:      stat.minLength,
+		MaxLength:      stat.maxLength,
+	}
+	if stat.numeric {
+		lo, hi := stat.minNumber, stat.maxNumber
+		p.Min, p.Max = &lo, &hi
+	}
+	minCount := orDefault(s.MinCount, 5)
+	for value, count := range stat.values {
+		if count >= minCount {
+			p.Top = append(p.Top, ValueCount{Value: value, Count: count})
+		}
+	}
+	sort.Slice(p.Top, func(i, j int) bool {
+		if p.Top[i].Count != p.Top[j].Count {
+			return p.Top[i].Count > p.Top[j].Count
+		}
+		return p.Top[i].Value < p.Top[j].Value
+	})
+	if topN := orDefault(s.TopN, 10); len(p.Top) > topN {
+		p.Top = p.Top[:topN]
+	}
+	return p
+}
+
+func orDefault(n, fallback int) int {
+	if n <= 0 {
+		return fallback
+	}
+	return n
+}
@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestValueStats(t *testing.T) {
+	qv := NewQueryValidator()
+	stats := &ValueStats{MinCount: 2, TopN: 2}
+	qv.SetValueStats(stats)
+	rules := map[string]string{"status": "string", "limit": "number", "email": "string"}
+	queries := []map[string]string{
+		{"status": "open", "limit": "10", "email": "a@example.com"},
+		{"status": "open", "limit": "250", "email": "b@example.com"},
+		{"status": "closed", "limit": "x"},
+		{"status": "open", "limit": "-5"},
+		{"status": "closed", "nope": "1"},
+		{"status": "archived"},
+	}
+	for _, q := range queries {
+		qv.run(q, rules, validationRequest{route: "/orders"})
+	}
+
+	if got := stats.Routes(); !slices.Equal(got, []string{"/orders"}) {
+		t.Errorf("routes %v", got)
+	}
+	got := stats.Stats("/orders")
+	if _, ok := got["nope"]; ok {
+		t.Error("undeclared parameter recorded")
+	}
+	want := ParamStats{
+		Count: 6, Distinct: 3, MinLength: 4, MaxLength: 8,
+		Top: []ValueCount{{"open", 3}, {"closed", 2}},
+	}
+	if !reflect.DeepEqual(got["status"], want) {
+		t.Errorf("status stats %+v, want %+v", got["status"], want)
+	}
+	if limit := got["limit"]; limit.Count != 4 || limit.Min != nil || limit.Max != nil {
+		t.Errorf("limit stats %+v: a non-number must drop the range", limit)
+	}
+	if email := got["email"]; email.Distinct != 2 || len(email.Top) != 0 {
+		t.Errorf("email stats %+v: values sent once must not be reported", email)
+	}
+	if stats.Stats("/users") != nil {
+		t.Error("stats for an unseen route")
+	}
+
+	qv.SetValueStats(nil)
+	qv.run(map[string]string{"status": "open"}, rules, validationRequest{route: "/orders"})
+	if n := stats.Stats("/orders")["status"].Count; n != 6 {
+		t.Errorf("count %d after collection stopped, want 6", n)
+	}
+}
+
+func TestValueStatsBounds(t *testing.T) {
+	stats := &ValueStats{MaxDistinct: 3, MaxTracked: 2, MinCount: 1}
+	for i := range 10 {
+		stats.observe("/", "n", fmt.Sprint(i))
+	}
+	stats.observe("/", "n", "0")
+	got := stats.Stats("/")["n"]
+	if got.Distinct != 3 || !got.DistinctCapped {
+		t.Errorf("distinct %d, capped %v, want 3, true", got.Distinct, got.DistinctCapped)
+	}
+	if want := []ValueCount{{"0", 2}, {"1", 1}}; !slices.Equal(got.Top, want) {
+		t.Errorf("top %v, want %v", got.Top, want)
+	}
+	if *got.Min != 0 || *got.Max != 9 {
+		t.Errorf("range %v to %v, want 0 to 9", *got.Min, *got.Max)
+	}
+}
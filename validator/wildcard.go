@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"slices"
+	"strings"
+)
+
+// wildcardMatch reports whether param matches the wildcard rule key. Rule
+// keys with a '*' declare families of parameters: "meta_*" covers
+// meta_source and meta_campaign, "dim.*" dim.width, and bracketed keys such
+// as "filter[*]" cover one bracket segment, as filter[status] but not
+// filter[age][gte]. Elsewhere '*' matches any run of characters.
+func wildcardMatch(key, param string) bool {
+	if _, _, bracketed := splitBrackets(key); bracketed {
+		return bracketsMatch(key, param)
+	}
+	literal, rest, _ := strings.Cut(key, "*")
+	if !strings.HasPrefix(param, literal) {
+		return false
+	}
+	param = param[len(literal):]
+	for rest != "" {
+		literal, rest, _ = strings.Cut(rest, "*")
+		if rest == "" && !strings.HasSuffix(key, "*") {
+			// The last literal anchors the end.
+			return len(param) >= len(literal) && strings.HasSuffix(param, literal)
+		}
+		i := strings.Index(param, literal)
+		if i < 0 {
+			return false
+		}
+		param = param[i+len(literal):]
+	}
+	return true
+}
+
+// expandWildcardRules returns rules with its wildcard keys replaced by a
+// rule for each parameter of values they match and no exact key declares.
+// A parameter several keys match gets the rule of the one with the fewest
+// wildcards, then the most literal characters, then the first in key
+// order. rules is returned as is when it has no wildcard keys.
+func expandWildcardRules(values, rules map[string]string) map[string]string {
+	var wildcards []string
+	for key := range rules {
+		if strings.Contains(key, "*") {
+			wildcards = append(wildcards, key)
+		}
+	}
+	if len(wildcards) == 0 {
+		return rules
+	}
+	slices.SortFunc(wildcards, func(a, b string) int {
+		if n, m := strings.Count(a, "*"), strings.Count(b, "*"); n != m {
+			return n - m
+		}
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+
+	expanded := make(map[string]string, len(rules))
+	for key, rule := range rules {
+		if !slices.Contains(wildcards, key) {
+			expanded[key] = rule
+		}
+	}
+	for param := range values {
+		if _, declared := expanded[param]; declared {
+			continue
+		}
+		for _, key := range wildcards {
+			if wildcardMatch(key, param) {
+				expanded[param] = rules[key]
+				break
+			}
+		}
+	}
+	return expanded
+}
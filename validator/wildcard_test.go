@@ -0,0 +1,61 @@
+package validator
+
+import "testing"
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		key, param string
+		want       bool
+	}{
+		{"meta_*", "meta_source", true},
+		{"meta_*", "meta_", true},
+		{"meta_*", "metadata", false},
+		{"dim.*", "dim.width", true},
+		{"dim.*", "dimension", false},
+		{"*_at", "created_at", true},
+		{"*_at", "created_at_x", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "acb", false},
+		{"filter[*]", "filter[status]", true},
+		{"filter[*]", "filter[age][gte]", false},
+		{"filter[*]", "filter", false},
+	}
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.key, tt.param); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.key, tt.param, got, tt.want)
+		}
+	}
+}
+
+func TestWildcardRules(t *testing.T) {
+	qv := NewQueryValidator()
+	rules := map[string]string{
+		"dim.*":       "number",
+		"meta_*":      "string AND maxlen:5",
+		"meta_source": "in:web,app",
+		"*":           "boolean",
+	}
+	tests := []struct {
+		values map[string]string
+		want   []string
+	}{
+		{map[string]string{"dim.width": "10", "dim.height": "2.5"}, nil},
+		{map[string]string{"dim.width": "wide"}, []string{"dim.width:INVALID_TYPE"}},
+		{map[string]string{"meta_campaign": "spring"}, []string{"meta_campaign:TOO_LONG"}},
+		{map[string]string{"meta_source": "web"}, nil},
+		{map[string]string{"meta_source": "email"}, []string{"meta_source:NOT_ALLOWED"}},
+		{map[string]string{"debug": "true"}, nil},
+		{map[string]string{"debug": "yes please"}, []string{"debug:INVALID_TYPE"}},
+	}
+	for _, tt := range tests {
+		checkCodes(t, qv, tt.values, rules, tt.want...)
+	}
+}
+
+func TestWildcardNamePatterns(t *testing.T) {
+	qv := NewQueryValidator()
+	checkCodes(t, qv, map[string]string{"dim.width": "10"}, map[string]string{"dim.width": "number"}, "dim.width:INVALID_NAME")
+	checkCodes(t, qv, map[string]string{"dim.width": "10"}, map[string]string{"dim.*": "number"})
+	checkCodes(t, qv, map[string]string{"dim.width": "10"},
+		map[string]string{"dim.*": "name_pattern:" + PatternSnakeCase + " AND number"}, "dim.width:INVALID_NAME")
+}